@@ -0,0 +1,5 @@
+// Package build holds build-time metadata for the vespa command-line tool.
+package build
+
+// Version is the version of this binary. It is normally overridden at build time via -ldflags.
+var Version = "8.0.0"
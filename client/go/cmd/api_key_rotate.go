@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newAuthApiKeyRotateCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate tenant",
+		Short: "Generate a new Vespa Cloud API key and replace the one installed for tenant",
+		Long: `Generate a new Vespa Cloud API key and replace the one installed for tenant.
+
+A new key pair is generated and its public key is registered with the Vespa Cloud control plane. The new
+private key is written alongside the old one with a .new suffix and verified with a test API call before it
+replaces the old key. If registration or verification fails, the old key is left untouched and an error is
+printed, so a broken rotation never leaves the tenant without a working key.`,
+		Example: `$ vespa auth api-key rotate mytenant`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return rotateAPIKey(cli, args[0])
+		},
+	}
+	return cmd
+}
+
+// rotateAPIKey generates a new API key for tenant, registers it with the control plane, and only once a
+// test call with the new key succeeds does it replace the old key file on disk.
+func rotateAPIKey(cli *CLI, tenant string) error {
+	path := apiKeyPath(cli, tenant)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no existing API key for %s at %s: %w", tenant, path, err)
+	}
+
+	keyPEM, publicKeyPEM, err := vespa.CreateAPIKeyPair()
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := cli.apiHttpClient(cli.requestTimeout)
+	if err != nil {
+		return err
+	}
+	service := vespa.NewService(vespa.CloudAPIURL, "controller", httpClient)
+	if err := vespa.RegisterAPIKey(service, tenant, publicKeyPEM, cli.requestTimeout); err != nil {
+		return fmt.Errorf("registering new API key: %w", err)
+	}
+
+	newPath := path + ".new"
+	if err := os.WriteFile(newPath, keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := vespa.VerifyAPIKey(service, cli.requestTimeout); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("new API key was registered but failed verification, keeping the old key: %w", err)
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "Rotated API key for %s at %s\n", tenant, path)
+	return nil
+}
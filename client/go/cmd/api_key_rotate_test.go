@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func writeExistingAPIKey(t *testing.T, cli *CLI, tenant string) string {
+	t.Helper()
+	path := apiKeyPath(cli, tenant)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("old key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthApiKeyRotate(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse("{}")}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	path := writeExistingAPIKey(t, cli, "mytenant")
+
+	err := cli.Run([]string{"auth", "api-key", "rotate", "mytenant"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Rotated API key for mytenant")
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "old key", string(data))
+	assert.Contains(t, string(data), "PRIVATE KEY")
+	_, err = os.Stat(path + ".new")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAuthApiKeyRotateRequiresExistingKey(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"auth", "api-key", "rotate", "mytenant"})
+
+	assert.NotNil(t, err)
+}
+
+// TestAuthApiKeyRotateKeepsOldKeyOnVerificationFailure verifies a control plane that rejects the
+// verification call after accepting registration leaves the previously installed key untouched, so a flaky
+// rotation never leaves the tenant without a working key.
+func TestAuthApiKeyRotateKeepsOldKeyOnVerificationFailure(t *testing.T) {
+	calls := 0
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return jsonResponse("{}"), nil
+		}
+		return &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader("boom")}, Header: make(http.Header)}, nil
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	path := writeExistingAPIKey(t, cli, "mytenant")
+
+	err := cli.Run([]string{"auth", "api-key", "rotate", "mytenant"})
+
+	assert.NotNil(t, err)
+	data, readErr := os.ReadFile(path)
+	assert.Nil(t, readErr)
+	assert.Equal(t, "old key", string(data))
+	_, statErr := os.Stat(path + ".new")
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestAuthApiKeyRotateKeepsOldKeyOnRegistrationFailure(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 403, Body: noopCloser{strings.NewReader("forbidden")}, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	path := writeExistingAPIKey(t, cli, "mytenant")
+
+	err := cli.Run([]string{"auth", "api-key", "rotate", "mytenant"})
+
+	assert.NotNil(t, err)
+	data, readErr := os.ReadFile(path)
+	assert.Nil(t, readErr)
+	assert.Equal(t, "old key", string(data))
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newApplicationNodesCmd(cli *CLI) *cobra.Command {
+	var (
+		cluster    string
+		jsonOutput bool
+	)
+	cmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "List the nodes running a deployed application",
+		Example: `$ vespa application nodes
+$ vespa application nodes --cluster music`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			target, err := cli.createCloudTarget()
+			if err != nil {
+				return err
+			}
+			service, err := target.DeployService()
+			if err != nil {
+				return err
+			}
+			nodes, err := vespa.ListNodes(service, target.Deployment, cluster, cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return json.NewEncoder(cli.Stdout).Encode(nodes)
+			}
+			for _, n := range nodes {
+				fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\t%s\n", n.Hostname, n.ServiceType, n.Version, n.State)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "Only list nodes belonging to this cluster")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print nodes as JSON")
+	return cmd
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestApplicationNodesFiltersByCluster(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"nodes": [
+		{"hostname": "node1", "serviceType": "music", "version": "8.0.0", "state": "active"},
+		{"hostname": "node2", "serviceType": "books", "version": "8.0.0", "state": "active"}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"application", "nodes", "--cluster", "music"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "node1")
+	assert.NotContains(t, stdout.String(), "node2")
+}
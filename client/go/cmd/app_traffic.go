@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newAppTrafficCmd(cli *CLI) *cobra.Command {
+	var (
+		cluster string
+		window  time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "traffic",
+		Short: "Show live query and feed traffic for a deployed application",
+		Long: `Show live query and feed traffic for a deployed application.
+
+Polls the container service's /metrics/v2/values endpoint every --window and prints a single line of query
+rate, feed rate and error rate, refreshed in place, until interrupted with Ctrl-C.`,
+		Example: `$ vespa application traffic
+$ vespa application traffic --window 5s
+$ vespa application traffic --cluster music`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			service, err := containerService(cli, cluster)
+			if err != nil {
+				return err
+			}
+			return showTraffic(cli, service, window, nil)
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "Show traffic for this container cluster")
+	cmd.Flags().DurationVar(&window, "window", time.Second, "How often to refresh the displayed rates")
+	return cmd
+}
+
+// showTraffic polls service for metrics every window, printing a single, continuously refreshed line of
+// query/feed/error rates to cli.Stdout. It runs until interrupted with Ctrl-C (SIGINT), or, for tests,
+// until stop is closed.
+func showTraffic(cli *CLI, service *vespa.Service, window time.Duration, stop <-chan struct{}) error {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		snapshot, err := vespa.FetchMetrics(service, cli.requestTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.Stdout, "\r\x1b[K%s", formatTraffic(snapshot))
+		select {
+		case <-interrupt:
+			fmt.Fprintln(cli.Stdout)
+			return nil
+		case <-stop:
+			fmt.Fprintln(cli.Stdout)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatTraffic renders a snapshot as the single line showTraffic refreshes on each poll.
+func formatTraffic(s vespa.MetricsSnapshot) string {
+	return fmt.Sprintf("queries/s: %.1f  feed ops/s: %.1f  errors/s: %.1f",
+		s.Sum("queries.rate"), s.Sum("feed.operations.rate"), s.Sum("queries.error.rate"))
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// TestShowTrafficPrintsRatesUntilStopped drives showTraffic directly rather than through cli.Run, since the
+// command itself only returns on Ctrl-C or (here) the test-only stop channel.
+func TestShowTrafficPrintsRatesUntilStopped(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"services": [
+		{"name": "vespa.container", "metrics": [{"values": {"queries.rate": 3.0, "feed.operations.rate": 1.5, "queries.error.rate": 0.0}}]}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	service, err := containerService(cli, "")
+	assert.Nil(t, err)
+	stop := make(chan struct{})
+	close(stop)
+
+	err = showTraffic(cli, service, time.Millisecond, stop)
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "queries/s: 3.0")
+	assert.Contains(t, stdout.String(), "feed ops/s: 1.5")
+	assert.Contains(t, stdout.String(), "errors/s: 0.0")
+}
+
+func TestShowTrafficPropagatesFetchError(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader("boom")}, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+	service, err := containerService(cli, "")
+	assert.Nil(t, err)
+	stop := make(chan struct{})
+
+	err = showTraffic(cli, service, time.Millisecond, stop)
+
+	assert.NotNil(t, err)
+}
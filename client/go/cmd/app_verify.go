@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// servicesXMLFile and hostsXMLFile are the application package files newApplicationPackageVerifyCmd checks.
+const servicesXMLFile = "services.xml"
+const hostsXMLFile = "hosts.xml"
+
+// xmlNode is a minimal parsed XML element, keeping just enough structure and position information for
+// verifyCheck functions to inspect: its name, attributes, children in document order, and the line its
+// start tag was found on, for file:line violation reporting.
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Line     int
+	Text     string
+	Children []*xmlNode
+}
+
+// walk calls fn for node and every descendant, in document order.
+func (n *xmlNode) walk(fn func(*xmlNode)) {
+	if n == nil {
+		return
+	}
+	fn(n)
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
+
+// parseXMLWithLines parses data into an xmlNode tree rooted at its single top-level element, recording the
+// line number each start tag ends on.
+func parseXMLWithLines(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attrs: map[string]string{}, Line: lineAt(data, decoder.InputOffset())}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element found")
+	}
+	return root, nil
+}
+
+// lineAt returns the 1-based line number containing byte offset in data.
+func lineAt(data []byte, offset int64) int {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// violation is one rule failure found in an application package file.
+type violation struct {
+	File     string
+	Line     int
+	Severity string // "error" or "warning"
+	Message  string
+}
+
+func (v violation) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", v.File, v.Line, v.Severity, v.Message)
+}
+
+// verifyCheck inspects root, the parsed contents of the file at path within appDir, and returns every
+// violation it finds. Checks are independent and pluggable: adding a new class of mistake to check for
+// means adding a new verifyCheck to servicesXMLChecks, not touching existing ones.
+type verifyCheck func(appDir, path string, root *xmlNode) []violation
+
+// servicesXMLChecks are run, in order, against a parsed services.xml.
+var servicesXMLChecks = []verifyCheck{
+	checkDuplicateClusterNames,
+	checkContentClustersHaveDocuments,
+	checkComponentBundlesExist,
+}
+
+// checkDuplicateClusterNames flags a <container> or <content> id reused by more than one cluster, which
+// leaves every use but the first rejected at deploy time.
+func checkDuplicateClusterNames(_, path string, root *xmlNode) []violation {
+	var violations []violation
+	firstSeenAt := map[string]int{}
+	root.walk(func(n *xmlNode) {
+		if n.Name != "container" && n.Name != "content" {
+			return
+		}
+		id := n.Attrs["id"]
+		if id == "" {
+			return
+		}
+		if line, ok := firstSeenAt[id]; ok {
+			violations = append(violations, violation{File: path, Line: n.Line, Severity: "error",
+				Message: fmt.Sprintf("duplicate cluster id %q, first declared at line %d", id, line)})
+			return
+		}
+		firstSeenAt[id] = n.Line
+	})
+	return violations
+}
+
+// checkContentClustersHaveDocuments flags a <content> cluster with no <documents> declaration, which has
+// no document types to feed or search and is almost always a missing section rather than an intentional
+// empty cluster.
+func checkContentClustersHaveDocuments(_, path string, root *xmlNode) []violation {
+	var violations []violation
+	root.walk(func(n *xmlNode) {
+		if n.Name != "content" {
+			return
+		}
+		for _, c := range n.Children {
+			if c.Name == "documents" {
+				return
+			}
+		}
+		violations = append(violations, violation{File: path, Line: n.Line, Severity: "error",
+			Message: fmt.Sprintf("content cluster %q is missing <documents>", n.Attrs["id"])})
+	})
+	return violations
+}
+
+// checkComponentBundlesExist flags a <component bundle="..."> whose bundle jar isn't actually present
+// under components/ in the application package, which otherwise only surfaces as a deploy-time failure.
+func checkComponentBundlesExist(appDir, path string, root *xmlNode) []violation {
+	var violations []violation
+	root.walk(func(n *xmlNode) {
+		if n.Name != "component" {
+			return
+		}
+		bundle := n.Attrs["bundle"]
+		if bundle == "" {
+			return
+		}
+		jarPath := filepath.Join(appDir, "components", bundle+".jar")
+		if _, err := os.Stat(jarPath); err != nil {
+			violations = append(violations, violation{File: path, Line: n.Line, Severity: "error",
+				Message: fmt.Sprintf("component %q references bundle %q, but components/%s.jar does not exist", n.Attrs["id"], bundle, bundle)})
+		}
+	})
+	return violations
+}
+
+// checkHostAliasesAreDeclared flags a <node hostalias="..."> in services.xml with no matching <host><alias>
+// in hosts.xml, which deploys fine locally (where hosts.xml is optional) but fails against a real cluster.
+func checkHostAliasesAreDeclared(services *xmlNode, hosts *xmlNode) []violation {
+	declared := map[string]bool{}
+	hosts.walk(func(n *xmlNode) {
+		if n.Name != "alias" {
+			return
+		}
+		declared[strings.TrimSpace(n.Text)] = true
+	})
+	var violations []violation
+	services.walk(func(n *xmlNode) {
+		if n.Name != "node" {
+			return
+		}
+		alias := n.Attrs["hostalias"]
+		if alias == "" || declared[alias] {
+			return
+		}
+		violations = append(violations, violation{File: servicesXMLFile, Line: n.Line, Severity: "error",
+			Message: fmt.Sprintf("hostalias %q has no matching <alias> in %s", alias, hostsXMLFile)})
+	})
+	return violations
+}
+
+func newApplicationPackageVerifyCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [application-directory]",
+		Short: "Check services.xml and hosts.xml for common mistakes, without deploying",
+		Long: `Check services.xml and hosts.xml for common mistakes, without deploying.
+
+This parses services.xml looking for duplicate cluster ids, content clusters missing <documents>, and
+components referencing a bundle that isn't actually present in the package. If hosts.xml exists, every
+hostalias in services.xml is also checked against it. Each violation is reported as file:line. Exits
+non-zero if any error-level violation is found.`,
+		Example: `$ vespa application package verify
+$ vespa application package verify my-app/`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			appDir := "."
+			if len(args) > 0 {
+				appDir = args[0]
+			}
+			violations, err := verifyApplicationPackage(appDir)
+			if err != nil {
+				return err
+			}
+			hasError := false
+			for _, v := range violations {
+				fmt.Fprintln(cli.Stdout, v.String())
+				if v.Severity == "error" {
+					hasError = true
+				}
+			}
+			if len(violations) == 0 {
+				fmt.Fprintln(cli.Stdout, "No issues found")
+			}
+			if hasError {
+				return errCLI(ErrCodeGeneric, "%d violation(s) found", len(violations))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// verifyApplicationPackage runs every services.xml check against appDir's services.xml, returning an error
+// only if services.xml itself could not be read or parsed, rather than for violations found within it.
+func verifyApplicationPackage(appDir string) ([]violation, error) {
+	servicesPath := filepath.Join(appDir, servicesXMLFile)
+	data, err := os.ReadFile(servicesPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseXMLWithLines(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", servicesPath, err)
+	}
+	var violations []violation
+	for _, check := range servicesXMLChecks {
+		violations = append(violations, check(appDir, servicesXMLFile, root)...)
+	}
+	hostsPath := filepath.Join(appDir, hostsXMLFile)
+	if hostsData, err := os.ReadFile(hostsPath); err == nil {
+		hosts, err := parseXMLWithLines(hostsData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", hostsPath, err)
+		}
+		violations = append(violations, checkHostAliasesAreDeclared(root, hosts)...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return violations, nil
+}
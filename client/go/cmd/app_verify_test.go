@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAppFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+}
+
+func TestAppVerifyReportsNoIssuesOnCleanPackage(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <container id="default" version="1.0">
+  </container>
+  <content id="music" version="1.0">
+    <documents>
+      <document type="music" mode="index"/>
+    </documents>
+  </content>
+</services>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "No issues found\n", stdout.String())
+}
+
+func TestAppVerifyFlagsDuplicateClusterIds(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <container id="default" version="1.0"/>
+  <container id="default" version="1.0"/>
+</services>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "duplicate cluster id \"default\"")
+}
+
+func TestAppVerifyFlagsContentClusterMissingDocuments(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <content id="music" version="1.0">
+  </content>
+</services>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "content cluster \"music\" is missing <documents>")
+}
+
+func TestAppVerifyFlagsMissingComponentBundle(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <container id="default" version="1.0">
+    <component id="my-searcher" bundle="my-bundle"/>
+  </container>
+</services>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "components/my-bundle.jar does not exist")
+}
+
+func TestAppVerifyPassesWhenComponentBundlePresent(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <container id="default" version="1.0">
+    <component id="my-searcher" bundle="my-bundle"/>
+  </container>
+</services>`)
+	writeAppFile(t, dir, "components/my-bundle.jar", "not-really-a-jar")
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "No issues found\n", stdout.String())
+}
+
+func TestAppVerifyFlagsUndeclaredHostAlias(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services version="1.0">
+  <container id="default" version="1.0">
+    <nodes>
+      <node hostalias="node1"/>
+    </nodes>
+  </container>
+</services>`)
+	writeAppFile(t, dir, "hosts.xml", `<hosts>
+  <host name="node2.example.com">
+    <alias>node2</alias>
+  </host>
+</hosts>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "hostalias \"node1\" has no matching <alias> in hosts.xml")
+}
+
+func TestAppVerifyFailsOnUnparseableServicesXML(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	writeAppFile(t, dir, "services.xml", `<services><unclosed></services>`)
+
+	err := cli.Run([]string{"application", "package", "verify", dir})
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// vespaignoreFile is a gitignore-style file at the root of an application package listing paths to leave
+// out of a package zip, for build output or other files that live alongside the package but aren't part
+// of it.
+const vespaignoreFile = ".vespaignore"
+
+func newApplicationPackageZipCmd(cli *CLI) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "zip [application-directory]",
+		Short: "Create a deployable application package zip without Maven",
+		Long: `Create a deployable application package zip without Maven.
+
+This walks application-directory and adds every file to a zip archive, for a Maven-less Gradle build or
+other custom build system that has no other way to produce one. Hidden files and directories (those with a
+name starting with ".") are skipped, as is anything matched by a gitignore-style .vespaignore file at the
+root of application-directory. If the package doesn't already have a security/clients.pem, the active
+data-plane certificate (see 'vespa auth cert') is added in its place, so the resulting zip is deployable to
+Vespa Cloud as-is.`,
+		Example: `$ vespa application package zip
+$ vespa application package zip my-app/
+$ vespa application package zip my-app/ --output my-app.zip`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			appDir := "."
+			if len(args) > 0 {
+				appDir = args[0]
+			}
+			if err := zipApplicationPackage(cli, vespa.ApplicationPackage{Path: appDir}, output); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Wrote %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", "application.zip", "Path to write the zip to")
+	return cmd
+}
+
+// zipApplicationPackage walks ap.Path and writes a deployable zip of it to outPath: every file, except a
+// hidden one or one excluded by .vespaignore, plus security/clients.pem from the active data-plane
+// certificate if the package doesn't already carry one of its own.
+func zipApplicationPackage(cli *CLI, ap vespa.ApplicationPackage, outPath string) error {
+	ignore, err := loadVespaignore(ap.Path)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := zip.NewWriter(out)
+	hasClientsPem := false
+	walkErr := filepath.Walk(ap.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(ap.Path, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if isHiddenPath(rel) || ignore.excludes(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if rel == "security/clients.pem" {
+			hasClientsPem = true
+		}
+		return addFileToZip(w, path, rel)
+	})
+	if walkErr != nil {
+		w.Close()
+		return walkErr
+	}
+	if !hasClientsPem {
+		if certPEM, err := os.ReadFile(dataPlaneCertPath(cli)); err == nil {
+			if err := addBytesToZip(w, "security/clients.pem", certPEM); err != nil {
+				w.Close()
+				return err
+			}
+		}
+	}
+	return w.Close()
+}
+
+func addFileToZip(w *zip.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zf, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(zf, f)
+	return err
+}
+
+func addBytesToZip(w *zip.Writer, name string, data []byte) error {
+	zf, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = zf.Write(data)
+	return err
+}
+
+// isHiddenPath reports whether rel, a slash-separated path relative to the package root, has a dot-prefixed
+// component anywhere in it, e.g. ".git" or "target/.gradle".
+func isHiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// vespaignorePatterns is a parsed .vespaignore file, in the order its lines appeared. It supports the
+// common subset of gitignore syntax: comments and blank lines are skipped, a trailing "/" restricts a
+// pattern to directories, a leading "/" (or any "/" before the last character) anchors it to the package
+// root instead of matching at any depth, and a leading "!" re-includes a path an earlier pattern excluded.
+// Patterns themselves are matched with filepath.Match, so "**" is not treated specially.
+type vespaignorePatterns []vespaignorePattern
+
+type vespaignorePattern struct {
+	negate   bool
+	anchored bool
+	dirOnly  bool
+	glob     string
+}
+
+// loadVespaignore reads .vespaignore from the root of appDir, returning an empty vespaignorePatterns if it
+// doesn't exist.
+func loadVespaignore(appDir string) (vespaignorePatterns, error) {
+	data, err := os.ReadFile(filepath.Join(appDir, vespaignoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var patterns vespaignorePatterns
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, parseVespaignoreLine(line))
+	}
+	return patterns, nil
+}
+
+func parseVespaignoreLine(line string) vespaignorePattern {
+	p := vespaignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	} else if strings.Contains(line, "/") {
+		p.anchored = true
+	}
+	p.glob = line
+	return p
+}
+
+// excludes reports whether rel (a slash-separated path relative to the package root) should be left out of
+// the zip, per patterns. As in a .gitignore file, later patterns override earlier ones, so a "!"-prefixed
+// pattern can re-include a path an earlier pattern excluded.
+func (patterns vespaignorePatterns) excludes(rel string, isDir bool) bool {
+	excluded := false
+	for _, p := range patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.matches(rel) {
+			excluded = !p.negate
+		}
+	}
+	return excluded
+}
+
+func (p vespaignorePattern) matches(rel string) bool {
+	if p.anchored {
+		ok, _ := filepath.Match(p.glob, rel)
+		return ok
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if ok, _ := filepath.Match(p.glob, part); ok {
+			return true
+		}
+	}
+	return false
+}
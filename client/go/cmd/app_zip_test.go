@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// zipEntryNames returns the names of every file in the zip at path, for asserting on package contents
+// without caring about entry order.
+func zipEntryNames(t *testing.T, path string) []string {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestZipApplicationPackageIncludesFilesAndExcludesHidden(t *testing.T) {
+	appDir := t.TempDir()
+	writeAppFile(t, appDir, "services.xml", "<services/>")
+	writeAppFile(t, appDir, "schemas/music.sd", "schema music {}")
+	writeAppFile(t, appDir, ".git/HEAD", "ref: refs/heads/master")
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	out := filepath.Join(t.TempDir(), "app.zip")
+
+	err := zipApplicationPackage(cli, vespa.ApplicationPackage{Path: appDir}, out)
+
+	assert.Nil(t, err)
+	names := zipEntryNames(t, out)
+	assert.Contains(t, names, "services.xml")
+	assert.Contains(t, names, "schemas/music.sd")
+	assert.NotContains(t, names, ".git/HEAD")
+}
+
+func TestZipApplicationPackageRespectsVespaignore(t *testing.T) {
+	appDir := t.TempDir()
+	writeAppFile(t, appDir, "services.xml", "<services/>")
+	writeAppFile(t, appDir, "target/classes/Foo.class", "binary")
+	writeAppFile(t, appDir, ".vespaignore", "target/\n")
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	out := filepath.Join(t.TempDir(), "app.zip")
+
+	err := zipApplicationPackage(cli, vespa.ApplicationPackage{Path: appDir}, out)
+
+	assert.Nil(t, err)
+	names := zipEntryNames(t, out)
+	assert.Contains(t, names, "services.xml")
+	assert.NotContains(t, names, "target/classes/Foo.class")
+}
+
+func TestZipApplicationPackageAddsActiveCertWhenClientsPemMissing(t *testing.T) {
+	appDir := t.TempDir()
+	writeAppFile(t, appDir, "services.xml", "<services/>")
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	if err := os.MkdirAll(filepath.Dir(dataPlaneCertPath(cli)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dataPlaneCertPath(cli), []byte("-----BEGIN CERTIFICATE-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "app.zip")
+
+	err := zipApplicationPackage(cli, vespa.ApplicationPackage{Path: appDir}, out)
+
+	assert.Nil(t, err)
+	assert.Contains(t, zipEntryNames(t, out), "security/clients.pem")
+}
+
+func TestZipApplicationPackageKeepsExistingClientsPem(t *testing.T) {
+	appDir := t.TempDir()
+	writeAppFile(t, appDir, "services.xml", "<services/>")
+	writeAppFile(t, appDir, "security/clients.pem", "already here")
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	out := filepath.Join(t.TempDir(), "app.zip")
+
+	err := zipApplicationPackage(cli, vespa.ApplicationPackage{Path: appDir}, out)
+
+	assert.Nil(t, err)
+	r, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "security/clients.pem" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data := make([]byte, len("already here"))
+		if _, err := io.ReadFull(rc, data); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "already here", string(data))
+	}
+}
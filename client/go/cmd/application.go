@@ -0,0 +1,25 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newApplicationCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "application",
+		Short: "Manage a deployed application",
+	}
+	cmd.AddCommand(newApplicationNodesCmd(cli))
+	cmd.AddCommand(newApplicationPackageCmd(cli))
+	cmd.AddCommand(newAppTrafficCmd(cli))
+	cmd.AddCommand(newApplicationListCmd(cli))
+	return cmd
+}
+
+func newApplicationPackageCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Inspect an application package locally, without deploying it",
+	}
+	cmd.AddCommand(newApplicationPackageVerifyCmd(cli))
+	cmd.AddCommand(newApplicationPackageZipCmd(cli))
+	return cmd
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// applicationDeploymentInfo is a single zone an application instance is currently deployed to, as returned
+// by the controller's application listing API.
+type applicationDeploymentInfo struct {
+	Environment string `json:"environment"`
+	Region      string `json:"region"`
+}
+
+// applicationInstanceInfo is a single instance of an application, with the zones it's currently deployed to.
+type applicationInstanceInfo struct {
+	Name        string                      `json:"instance"`
+	Deployments []applicationDeploymentInfo `json:"deployments"`
+}
+
+// applicationListInfo is a single application under a tenant, as returned by the controller's application
+// listing API.
+type applicationListInfo struct {
+	Tenant      string                    `json:"tenant"`
+	Application string                    `json:"application"`
+	Instances   []applicationInstanceInfo `json:"instances"`
+}
+
+func newApplicationListCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the applications under a tenant in Vespa Cloud",
+		Long: `List the applications under a tenant in Vespa Cloud, along with each of their instances and the
+zones each instance is currently deployed to. The tenant defaults to "default" unless overridden by --tenant
+or the tenant part of --application, e.g. --application mytenant.myapp lists applications under "mytenant".`,
+		Example: `$ vespa application list
+$ vespa application list --tenant mytenant
+$ vespa application list --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			apps, err := fetchApplications(cli)
+			if err != nil {
+				return err
+			}
+			if cli.outputFormat == "json" {
+				return json.NewEncoder(cli.Stdout).Encode(apps)
+			}
+			for _, a := range apps {
+				if len(a.Instances) == 0 {
+					fmt.Fprintf(cli.Stdout, "%s.%s\t-\n", a.Tenant, a.Application)
+					continue
+				}
+				for _, i := range a.Instances {
+					fmt.Fprintf(cli.Stdout, "%s.%s.%s\t%s\n", a.Tenant, a.Application, i.Name, formatDeployedZones(i.Deployments))
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// formatDeployedZones renders deployments as a comma-separated "environment.region" list, or "-" if the
+// instance isn't deployed anywhere.
+func formatDeployedZones(deployments []applicationDeploymentInfo) string {
+	if len(deployments) == 0 {
+		return "-"
+	}
+	zones := make([]string, len(deployments))
+	for i, d := range deployments {
+		zones[i] = d.Environment + "." + d.Region
+	}
+	return strings.Join(zones, ",")
+}
+
+func fetchApplications(cli *CLI) ([]applicationListInfo, error) {
+	config, err := newConfig(cli)
+	if err != nil {
+		return nil, err
+	}
+	tenant, _, err := config.application()
+	if err != nil {
+		return nil, err
+	}
+	target, err := cli.createCloudTargetForInstance(config, cli.resolveInstance(config))
+	if err != nil {
+		return nil, err
+	}
+	service := target.ControlService()
+	path := fmt.Sprintf("/application/v4/tenant/%s/application", tenant)
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to list applications: status %d: %s", resp.StatusCode, vespa.ControllerErrorMessage(body))
+	}
+	var apps []applicationListInfo
+	if err := json.Unmarshal(body, &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
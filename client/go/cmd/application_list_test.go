@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestApplicationListPrintsInstancesAndZones(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[
+		{"tenant": "mytenant", "application": "myapp", "instances": [
+			{"instance": "default", "deployments": [{"environment": "prod", "region": "us-east-3"}]},
+			{"instance": "beta", "deployments": []}
+		]}
+	]`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"application", "list"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant.myapp.default\tprod.us-east-3\nmytenant.myapp.beta\t-\n", stdout.String())
+	assert.Equal(t, "/application/v4/tenant/default/application", httpClient.LastRequest.URL.Path)
+}
+
+func TestApplicationListUsesTenantPartOfApplicationFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[]`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"application", "list", "--application", "mytenant.myapp"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/application/v4/tenant/mytenant/application", httpClient.LastRequest.URL.Path)
+}
+
+func TestApplicationListFailsWithReadableMessageOnForbidden(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"error-code": "FORBIDDEN", "message": "Access denied"}`)}
+	httpClient.NextResponse.StatusCode = 403
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"application", "list"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Access denied")
+		assert.NotContains(t, err.Error(), "error-code")
+	}
+}
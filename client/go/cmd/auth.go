@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// authHomeEnv is the CLI home directory; auth.json is stored in the active profile's directory under it
+// (see profileDir), mirroring versionCachePath's use of an env-overridable
+// directory rather than a hardcoded path, so tests (and unusual environments) can redirect it.
+const authHomeEnv = "VESPA_CLI_HOME"
+
+// authSystemEntry holds the credentials stored for a single Vespa Cloud system.
+type authSystemEntry struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// authConfig is the on-disk shape of auth.json: one entry per system (e.g. "public", "publiccd") a user has
+// logged into, keyed by system name.
+type authConfig struct {
+	Systems map[string]authSystemEntry `json:"systems"`
+}
+
+func authConfigPath(cli *CLI) string {
+	return filepath.Join(profileDir(cli), "auth.json")
+}
+
+func readAuthConfig(cli *CLI) (authConfig, error) {
+	cfg := authConfig{Systems: make(map[string]authSystemEntry)}
+	data, err := os.ReadFile(authConfigPath(cli))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return authConfig{}, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return authConfig{}, err
+	}
+	if cfg.Systems == nil {
+		cfg.Systems = make(map[string]authSystemEntry)
+	}
+	return cfg, nil
+}
+
+func writeAuthConfig(cli *CLI, cfg authConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := authConfigPath(cli)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func newAuthCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage Vespa Cloud authentication",
+	}
+	cmd.AddCommand(newLoginCmd(cli))
+	cmd.AddCommand(newAuthLogoutCmd(cli))
+	cmd.AddCommand(newAuthApiKeyCmd(cli))
+	cmd.AddCommand(newAuthCertCmd(cli))
+	return cmd
+}
+
+func newAuthLogoutCmd(cli *CLI) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Log out of Vespa Cloud",
+		Long: `Log out of Vespa Cloud, removing the stored refresh token for the current system.
+
+Pass --all to remove the stored credentials for every system instead, which is useful on shared machines
+or when rotating credentials across public and internal systems.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := readAuthConfig(cli)
+			if err != nil {
+				return err
+			}
+			var cleared []string
+			if all {
+				for system := range cfg.Systems {
+					cleared = append(cleared, system)
+				}
+				cfg.Systems = make(map[string]authSystemEntry)
+			} else if system := cli.cloudSystem(); cfg.Systems[system].RefreshToken != "" {
+				delete(cfg.Systems, system)
+				cleared = []string{system}
+			}
+			if err := writeAuthConfig(cli, cfg); err != nil {
+				return err
+			}
+			if len(cleared) == 0 {
+				fmt.Fprintln(cli.Stdout, "Already logged out")
+				return nil
+			}
+			sort.Strings(cleared)
+			fmt.Fprintf(cli.Stdout, "Logged out of: %s\n", strings.Join(cleared, ", "))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Log out of every system instead of just the current one")
+	return cmd
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// apiKeyPath returns the path the Vespa Cloud API key for tenant is stored at, under the CLI home
+// directory, mirroring authConfigPath's use of authHomeEnv.
+func apiKeyPath(cli *CLI, tenant string) string {
+	return filepath.Join(profileDir(cli), tenant+".api-key.pem")
+}
+
+func newAuthApiKeyCmd(cli *CLI) *cobra.Command {
+	var fromStdin bool
+	cmd := &cobra.Command{
+		Use:   "api-key tenant",
+		Short: "Install a Vespa Cloud API key for tenant",
+		Long: `Install a Vespa Cloud API key for tenant.
+
+Pass --from-stdin to read the PEM-encoded private key from stdin, e.g. when piping it from a secret
+manager, rather than copying it into a file by hand first. The key is validated before being written to
+disk with permissions restricted to the current user.`,
+		Example: `$ echo "$API_KEY" | vespa auth api-key mytenant --from-stdin`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !fromStdin {
+				return fmt.Errorf("only --from-stdin is currently supported")
+			}
+			tenant := args[0]
+			data, err := io.ReadAll(cli.Stdin)
+			if err != nil {
+				return err
+			}
+			if err := validatePrivateKey(data); err != nil {
+				return fmt.Errorf("invalid private key: %w", err)
+			}
+			path := apiKeyPath(cli, tenant)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Installed API key for %s at %s\n", tenant, path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&fromStdin, "from-stdin", false, "Read the PEM-encoded private key from stdin")
+	cmd.AddCommand(newAuthApiKeyRotateCmd(cli))
+	return cmd
+}
+
+// validatePrivateKey returns an error unless data is a PEM block that parses as a private key, to catch a
+// malformed or accidentally-truncated key before it's written to disk.
+func validatePrivateKey(data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("not a PEM-encoded key")
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return nil
+	}
+	if _, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return nil
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return nil
+	}
+	return fmt.Errorf("unrecognized private key format")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateTestPrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestAuthApiKeyFromStdin(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.Stdin = strings.NewReader(generateTestPrivateKeyPEM(t))
+
+	err := cli.Run([]string{"auth", "api-key", "mytenant", "--from-stdin"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Installed API key for mytenant")
+	data, err := os.ReadFile(apiKeyPath(cli, "mytenant"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "PRIVATE KEY")
+	info, err := os.Stat(apiKeyPath(cli, "mytenant"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAuthApiKeyRejectsInvalidKey(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.Stdin = strings.NewReader("not a key")
+
+	err := cli.Run([]string{"auth", "api-key", "mytenant", "--from-stdin"})
+
+	assert.NotNil(t, err)
+	_, statErr := os.Stat(apiKeyPath(cli, "mytenant"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestAuthApiKeyRequiresFromStdin(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"auth", "api-key", "mytenant"})
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// certValidity is how long a self-signed data-plane certificate generated by `vespa auth cert` is valid for.
+const certValidity = 5 * 365 * 24 * time.Hour
+
+// dataPlaneKeyFile and dataPlaneCertFile are the profile-directory-relative names of the private key and public
+// certificate generated by `vespa auth cert`, mirroring the single-file global config.yaml convention
+// rather than per-tenant naming, since a CLI invocation only ever has one active target at a time.
+const dataPlaneKeyFile = "data-plane-private-key.pem"
+const dataPlaneCertFile = "data-plane-public-cert.pem"
+
+func dataPlaneKeyPath(cli *CLI) string {
+	return filepath.Join(profileDir(cli), dataPlaneKeyFile)
+}
+
+func dataPlaneCertPath(cli *CLI) string {
+	return filepath.Join(profileDir(cli), dataPlaneCertFile)
+}
+
+// clientsPemPath is the application package file that lists the certificates Vespa Cloud will accept for
+// data-plane access.
+func clientsPemPath(appDir string) string {
+	return filepath.Join(appDir, "security", "clients.pem")
+}
+
+func newAuthCertCmd(cli *CLI) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "cert [application-directory]",
+		Short: "Create a self-signed certificate for data-plane access",
+		Long: `Create a self-signed certificate for data-plane access.
+
+This generates an ECDSA private key and a matching self-signed public certificate, storing the private key
+under the CLI home directory and adding the public certificate to security/clients.pem in the application
+package, where Vespa Cloud expects to find it.`,
+		Example: `$ vespa auth cert
+$ vespa auth cert my-app/
+$ vespa auth cert --force my-app/`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			appDir := "."
+			if len(args) > 0 {
+				appDir = args[0]
+			}
+			if !force {
+				if _, err := os.Stat(dataPlaneKeyPath(cli)); err == nil {
+					return fmt.Errorf("private key already exists at %s: use --force to overwrite", dataPlaneKeyPath(cli))
+				}
+			}
+			return generateDataPlaneCertificate(cli, appDir)
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing private key and certificate")
+	cmd.AddCommand(newAuthCertRotateCmd(cli))
+	cmd.AddCommand(newAuthCertImportCmd(cli))
+	return cmd
+}
+
+// generateDataPlaneCertificate runs the steps behind `vespa auth cert`: a new self-signed key pair is
+// written under the CLI profile directory and its public certificate is added to appDir's
+// security/clients.pem. It's factored out of newAuthCertCmd so other callers (e.g. deploy's interactive
+// prompt for a missing certificate, see ensureDataPlaneCertificate) produce byte-for-byte the same files the
+// command itself would have.
+func generateDataPlaneCertificate(cli *CLI, appDir string) error {
+	keyPEM, certPEM, err := generateSelfSignedCert()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dataPlaneKeyPath(cli)), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPlaneKeyPath(cli), keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPlaneCertPath(cli), certPEM, 0644); err != nil {
+		return err
+	}
+	if err := addClientCert(appDir, certPEM); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "Certificate written to %s\n", clientsPemPath(appDir))
+	fmt.Fprintf(cli.Stdout, "Private key written to %s\n", dataPlaneKeyPath(cli))
+	return nil
+}
+
+// ensureDataPlaneCertificate checks that a data-plane key pair already exists for deploying appDir to
+// tenant.application.instance, and if not, offers to generate one the same way `vespa auth cert` would: only
+// when stdin is a terminal (so a script piping input in, or running under CI, never blocks) and --quiet was
+// not given. Declining, or either of those conditions not holding, returns the same error a deploy without
+// this prompt would always have returned.
+func ensureDataPlaneCertificate(cli *CLI, appDir, tenant, application, instance string) error {
+	_, keyErr := os.Stat(dataPlaneKeyPath(cli))
+	_, certErr := os.Stat(dataPlaneCertPath(cli))
+	if keyErr == nil && certErr == nil {
+		return nil
+	}
+	missing := fmt.Errorf("no data-plane certificate found for %s.%s.%s: run 'vespa auth cert' first", tenant, application, instance)
+	if cli.quietOverride || !cli.stdinIsTerminal {
+		return missing
+	}
+	fmt.Fprintf(cli.Stdout, "No data-plane certificate found for %s.%s.%s — generate one now? [Y/n] ", tenant, application, instance)
+	if !cli.confirmYes() {
+		return missing
+	}
+	return generateDataPlaneCertificate(cli, appDir)
+}
+
+// generateSelfSignedCert creates a new ECDSA P-256 key pair and a self-signed certificate for it, valid for
+// certValidity, returning both as PEM-encoded blocks.
+func generateSelfSignedCert() (keyPEM, certPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "vespa-cli"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return keyPEM, certPEM, nil
+}
+
+// addClientCert appends certPEM to appDir's security/clients.pem, creating it (and the security directory)
+// if necessary, so a certificate can be rotated by accumulating the new certificate alongside the old one.
+func addClientCert(appDir string, certPEM []byte) error {
+	path := clientsPemPath(appDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data := append(existing, certPEM...)
+	return os.WriteFile(path, data, 0644)
+}
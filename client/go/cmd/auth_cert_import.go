@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// envPassphrasePrefix marks a --passphrase value as the name of an environment variable to read the real
+// passphrase from, rather than the passphrase itself, so it never needs to appear in shell history.
+const envPassphrasePrefix = "env:"
+
+func newAuthCertImportCmd(cli *CLI) *cobra.Command {
+	var (
+		pkcs12Path string
+		passphrase string
+		force      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import [application-directory]",
+		Short: "Import a certificate and private key from a PKCS#12 key store",
+		Long: `Import a certificate and private key from a PKCS#12 key store.
+
+This reads an existing PKCS#12 (.pfx/.p12) bundle, such as one issued by an enterprise certificate
+authority, and writes its certificate and private key to the same paths vespa auth cert would generate,
+adding the certificate to security/clients.pem in the application package.`,
+		Example: `$ vespa auth cert import --pkcs12 bundle.pfx
+$ vespa auth cert import --pkcs12 bundle.pfx --passphrase env:PKCS12_PASSPHRASE my-app/`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			appDir := "."
+			if len(args) > 0 {
+				appDir = args[0]
+			}
+			if !force {
+				if _, err := os.Stat(dataPlaneKeyPath(cli)); err == nil {
+					return fmt.Errorf("private key already exists at %s: use --force to overwrite", dataPlaneKeyPath(cli))
+				}
+			}
+			resolvedPassphrase, err := resolvePassphrase(passphrase)
+			if err != nil {
+				return err
+			}
+			keyPEM, certPEM, err := importPKCS12(pkcs12Path, resolvedPassphrase)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(dataPlaneKeyPath(cli)), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(dataPlaneKeyPath(cli), keyPEM, 0600); err != nil {
+				return err
+			}
+			if err := os.WriteFile(dataPlaneCertPath(cli), certPEM, 0644); err != nil {
+				return err
+			}
+			if err := addClientCert(appDir, certPEM); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Certificate written to %s\n", clientsPemPath(appDir))
+			fmt.Fprintf(cli.Stdout, "Private key written to %s\n", dataPlaneKeyPath(cli))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pkcs12Path, "pkcs12", "", "PKCS#12 (.pfx/.p12) file to import the certificate and private key from")
+	cmd.MarkFlagRequired("pkcs12")
+	cmd.Flags().StringVar(&passphrase, "passphrase", "", "Passphrase protecting the PKCS#12 file, or env:NAME to read it from an environment variable")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing private key and certificate")
+	return cmd
+}
+
+// resolvePassphrase returns passphrase as-is, unless it has the envPassphrasePrefix, in which case the
+// named environment variable's value is returned instead, so a real passphrase never needs to be typed on
+// the command line or saved in shell history.
+func resolvePassphrase(passphrase string) (string, error) {
+	if !strings.HasPrefix(passphrase, envPassphrasePrefix) {
+		return passphrase, nil
+	}
+	name := strings.TrimPrefix(passphrase, envPassphrasePrefix)
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// importPKCS12 reads the PKCS#12 file at path, decoding it with passphrase, and returns its private key and
+// certificate as PEM-encoded blocks in the same shape generateSelfSignedCert produces.
+func importPKCS12(path, passphrase string) (keyPEM, certPEM []byte, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, cert, err := pkcs12.Decode(data, passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode private key from %s: %w", path, err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	return keyPEM, certPEM, nil
+}
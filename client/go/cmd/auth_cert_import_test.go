@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// writeTestPKCS12 generates a self-signed certificate and private key, bundles them into a PKCS#12 file
+// protected by passphrase, and returns its path.
+func writeTestPKCS12(t *testing.T, passphrase string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "imported-cert"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	pfxData, err := pkcs12.Encode(rand.Reader, key, cert, nil, passphrase)
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "bundle.pfx")
+	assert.Nil(t, os.WriteFile(path, pfxData, 0644))
+	return path
+}
+
+func TestAuthCertImportWritesKeyAndCert(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	pfxPath := writeTestPKCS12(t, "hunter2")
+
+	err := cli.Run([]string{"auth", "cert", "import", "--pkcs12", pfxPath, "--passphrase", "hunter2", appDir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Certificate written to")
+	assert.FileExists(t, dataPlaneKeyPath(cli))
+	assert.FileExists(t, dataPlaneCertPath(cli))
+	clientsPem, err := os.ReadFile(clientsPemPath(appDir))
+	assert.Nil(t, err)
+	assert.Contains(t, string(clientsPem), "BEGIN CERTIFICATE")
+}
+
+func TestAuthCertImportReadsPassphraseFromEnv(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	pfxPath := writeTestPKCS12(t, "hunter2")
+	os.Setenv("VESPA_TEST_PKCS12_PASSPHRASE", "hunter2")
+	defer os.Unsetenv("VESPA_TEST_PKCS12_PASSPHRASE")
+
+	err := cli.Run([]string{"auth", "cert", "import", "--pkcs12", pfxPath, "--passphrase", "env:VESPA_TEST_PKCS12_PASSPHRASE", appDir})
+
+	assert.Nil(t, err)
+}
+
+func TestAuthCertImportFailsOnMissingEnvPassphrase(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	pfxPath := writeTestPKCS12(t, "hunter2")
+
+	err := cli.Run([]string{"auth", "cert", "import", "--pkcs12", pfxPath, "--passphrase", "env:VESPA_TEST_DOES_NOT_EXIST"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "VESPA_TEST_DOES_NOT_EXIST")
+	}
+}
+
+func TestAuthCertImportFailsOnWrongPassphrase(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	pfxPath := writeTestPKCS12(t, "hunter2")
+
+	err := cli.Run([]string{"auth", "cert", "import", "--pkcs12", pfxPath, "--passphrase", "wrong"})
+
+	assert.NotNil(t, err)
+}
+
+func TestAuthCertImportRefusesToOverwriteWithoutForce(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(dataPlaneKeyPath(cli)), 0755))
+	assert.Nil(t, os.WriteFile(dataPlaneKeyPath(cli), []byte("existing"), 0600))
+	pfxPath := writeTestPKCS12(t, "hunter2")
+
+	err := cli.Run([]string{"auth", "cert", "import", "--pkcs12", pfxPath, "--passphrase", "hunter2"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--force")
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthCert(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+
+	err := cli.Run([]string{"auth", "cert", appDir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Certificate written to")
+	key, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+	assert.Contains(t, string(key), "PRIVATE KEY")
+	cert, err := os.ReadFile(clientsPemPath(appDir))
+	assert.Nil(t, err)
+	assert.Contains(t, string(cert), "CERTIFICATE")
+}
+
+func TestAuthCertRefusesToOverwriteWithoutForce(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+
+	err := cli.Run([]string{"auth", "cert", appDir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "--force")
+}
+
+func TestAuthCertForceOverwrites(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+	firstKey, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+
+	err = cli.Run([]string{"auth", "cert", "--force", appDir})
+
+	assert.Nil(t, err)
+	secondKey, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+	assert.NotEqual(t, firstKey, secondKey)
+}
+
+func TestAuthCertAppendsToExistingClientsPem(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+	first, err := os.ReadFile(clientsPemPath(appDir))
+	assert.Nil(t, err)
+
+	assert.Nil(t, cli.Run([]string{"auth", "cert", "--force", appDir}))
+
+	second, err := os.ReadFile(clientsPemPath(appDir))
+	assert.Nil(t, err)
+	assert.True(t, len(second) > len(first))
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// auth0ClientID is the OAuth client ID used for the CLI's device authorization flow.
+const auth0ClientID = "4Q6hPZCFuQUY4OC9aO3HVDKJzYB9xZf8"
+
+// auth0Domains maps a Vespa Cloud system name to the Auth0 tenant that issues its tokens.
+var auth0Domains = map[string]string{
+	"public":   "https://vespa-auth.auth0.com",
+	"publiccd": "https://vespa-auth-cd.auth0.com",
+}
+
+func auth0Domain(system string) string {
+	if d, ok := auth0Domains[system]; ok {
+		return d
+	}
+	return auth0Domains["public"]
+}
+
+func newLoginCmd(cli *CLI) *cobra.Command {
+	var (
+		noBrowser    bool
+		loginTimeout time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to Vespa Cloud",
+		Long: `Log in to Vespa Cloud using the OAuth device authorization flow.
+
+By default, this opens a browser to complete verification and waits for Enter before polling. Pass
+--no-browser for headless environments, such as a CI runner: the verification URL and code are printed
+instead, so a human can complete verification from another device within --timeout.`,
+		Example: `$ vespa auth login
+$ vespa auth login --no-browser --timeout 5m`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return login(cli, noBrowser, loginTimeout)
+		},
+	}
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Skip opening a browser and the Enter prompt; print the verification URL and code instead")
+	cmd.Flags().DurationVar(&loginTimeout, "timeout", 5*time.Minute, "How long to wait for verification to complete")
+	return cmd
+}
+
+// login runs the device authorization flow: request a code, direct the user to verify it, then poll until
+// the token endpoint reports success, failure, or timeout elapses.
+func login(cli *CLI, noBrowser bool, timeout time.Duration) error {
+	system := cli.cloudSystem()
+	service := vespa.NewService(auth0Domain(system), "auth0", cli.httpClient(cli.requestTimeout))
+	code, err := vespa.RequestDeviceCode(service, auth0ClientID, cli.requestTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "Your verification code is: %s\n", code.UserCode)
+	if noBrowser {
+		fmt.Fprintf(cli.Stdout, "Open this URL in a browser to verify: %s\n", code.VerificationURI)
+	} else {
+		fmt.Fprintf(cli.Stdout, "Press Enter to open %s in your browser...\n", code.VerificationURI)
+		cli.waitForEnter()
+		if err := cli.openURL(code.VerificationURI); err != nil {
+			fmt.Fprintf(cli.Stderr, "Could not open a browser automatically: %s\nOpen this URL instead: %s\n", err, code.VerificationURI)
+		}
+	}
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	var elapsed time.Duration
+	for {
+		token, err := vespa.PollDeviceToken(service, auth0ClientID, code.DeviceCode, cli.requestTimeout)
+		if err == nil {
+			return storeRefreshToken(cli, system, token)
+		}
+		if err != vespa.ErrAuthorizationPending {
+			return err
+		}
+		elapsed += interval
+		if elapsed > timeout {
+			return errCLI(ErrCodeAuthExpired, "timed out waiting for login to complete")
+		}
+		cli.sleep(interval)
+	}
+}
+
+func storeRefreshToken(cli *CLI, system, refreshToken string) error {
+	cfg, err := readAuthConfig(cli)
+	if err != nil {
+		return err
+	}
+	cfg.Systems[system] = authSystemEntry{RefreshToken: refreshToken}
+	if err := writeAuthConfig(cli, cfg); err != nil {
+		return err
+	}
+	cli.printSuccess("logged in")
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestLoginNoBrowserPollsUntilSuccess(t *testing.T) {
+	var tokenCalls int32
+	httpClient := &mock.HTTPClient{
+		DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/oauth/device/code") {
+				return okResponse(`{"device_code": "d", "user_code": "ABCD-EFGH", "verification_uri_complete": "https://x/activate", "expires_in": 900, "interval": 1}`), nil
+			}
+			if atomic.AddInt32(&tokenCalls, 1) == 1 {
+				return okResponse(`{"error": "authorization_pending"}`), nil
+			}
+			return okResponse(`{"refresh_token": "rt-123"}`), nil
+		},
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	var slept []time.Duration
+	cli.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	err := cli.Run([]string{"auth", "login", "--no-browser"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "ABCD-EFGH")
+	assert.Len(t, slept, 1)
+	cfg, err := readAuthConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, "rt-123", cfg.Systems["public"].RefreshToken)
+}
+
+func TestLoginOpensBrowserAndWaitsForEnter(t *testing.T) {
+	httpClient := &mock.HTTPClient{
+		DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/oauth/device/code") {
+				return okResponse(`{"device_code": "d", "user_code": "ABCD-EFGH", "verification_uri_complete": "https://x/activate", "expires_in": 900, "interval": 1}`), nil
+			}
+			return okResponse(`{"refresh_token": "rt-123"}`), nil
+		},
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.Stdin = strings.NewReader("\n")
+	var openedURL string
+	cli.openURL = func(url string) error {
+		openedURL = url
+		return nil
+	}
+
+	err := cli.Run([]string{"auth", "login"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://x/activate", openedURL)
+}
+
+func TestLoginTimesOut(t *testing.T) {
+	httpClient := &mock.HTTPClient{
+		DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/oauth/device/code") {
+				return okResponse(`{"device_code": "d", "user_code": "ABCD-EFGH", "verification_uri_complete": "https://x/activate", "expires_in": 900, "interval": 1}`), nil
+			}
+			return okResponse(`{"error": "authorization_pending"}`), nil
+		},
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.sleep = func(time.Duration) {}
+
+	err := cli.Run([]string{"auth", "login", "--no-browser", "--timeout", "2s"})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrCodeAuthExpired, codeOf(err))
+}
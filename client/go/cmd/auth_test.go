@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthLogoutClearsCurrentSystem(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, writeAuthConfig(cli, authConfig{Systems: map[string]authSystemEntry{
+		"public":   {RefreshToken: "a"},
+		"publiccd": {RefreshToken: "b"},
+	}}))
+
+	assert.Nil(t, cli.Run([]string{"auth", "logout"}))
+
+	assert.Contains(t, stdout.String(), "Logged out of: public\n")
+	cfg, err := readAuthConfig(cli)
+	assert.Nil(t, err)
+	assert.NotContains(t, cfg.Systems, "public")
+	assert.Contains(t, cfg.Systems, "publiccd")
+}
+
+func TestAuthLogoutAllClearsEverySystem(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, writeAuthConfig(cli, authConfig{Systems: map[string]authSystemEntry{
+		"public":   {RefreshToken: "a"},
+		"publiccd": {RefreshToken: "b"},
+	}}))
+
+	assert.Nil(t, cli.Run([]string{"auth", "logout", "--all"}))
+
+	assert.Contains(t, stdout.String(), "public, publiccd")
+	cfg, err := readAuthConfig(cli)
+	assert.Nil(t, err)
+	assert.Empty(t, cfg.Systems)
+}
+
+func TestAuthLogoutWithNothingStored(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	assert.Nil(t, cli.Run([]string{"auth", "logout"}))
+
+	assert.Contains(t, stdout.String(), "Already logged out")
+}
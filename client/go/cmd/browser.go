@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// openBrowser launches the user's default browser at url. It is the default value of CLI.openURL; tests
+// replace the field instead of calling this directly.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// rotateSleep is an indirection over time.Sleep, so tests can skip the real wait between convergence polls.
+var rotateSleep = time.Sleep
+
+// maxConvergencePolls bounds how many times newAuthCertRotateCmd polls the target for deployment
+// convergence before giving up, so a config server that never converges doesn't hang the command forever.
+const maxConvergencePolls = 30
+
+// defaultConvergencePollInterval is the base interval waitForConvergence backs off from (see
+// newPollRetrier), overridden by --poll-interval.
+const defaultConvergencePollInterval = time.Second
+
+func newAuthCertRotateCmd(cli *CLI) *cobra.Command {
+	var pollInterval time.Duration
+	cmd := &cobra.Command{
+		Use:   "rotate [application-directory]",
+		Short: "Generate a new certificate and deploy it in place of the current one",
+		Long: `Generate a new certificate and deploy it in place of the current one.
+
+Rotation proceeds in stages so the application never loses data-plane access: a new key pair is generated
+without touching the existing one, its certificate is added alongside the current one in
+security/clients.pem and deployed, and only once that deployment has converged is the old certificate
+retired and the package redeployed with just the new one. Each deploy step prints the package being
+deployed, as vespa deploy does; like vespa deploy, it uploads nothing to the target.
+
+Waiting for convergence backs off from --poll-interval (default 1s) the same way vespa status --watch does,
+so a slow cold start isn't hammered with requests while a quick one still converges promptly.`,
+		Example: `$ vespa auth cert rotate
+$ vespa auth cert rotate my-app/
+$ vespa auth cert rotate --poll-interval 2s my-app/`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			appDir := "."
+			if len(args) > 0 {
+				appDir = args[0]
+			}
+			return rotateCert(cli, appDir, pollInterval)
+		},
+	}
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", defaultConvergencePollInterval, "Base interval to poll the target for deployment convergence, backing off from there")
+	return cmd
+}
+
+// rotateCert steps an existing data-plane certificate through rotation: generate, deploy alongside the old
+// one, wait for that to converge, then retire the old certificate and redeploy.
+func rotateCert(cli *CLI, appDir string, pollInterval time.Duration) error {
+	oldKeyPEM, err := os.ReadFile(dataPlaneKeyPath(cli))
+	if err != nil {
+		return fmt.Errorf("no existing certificate to rotate: %w", err)
+	}
+	oldCertPEM, err := os.ReadFile(dataPlaneCertPath(cli))
+	if err != nil {
+		return fmt.Errorf("no existing certificate to rotate: %w", err)
+	}
+
+	fmt.Fprintln(cli.Stdout, "Generating new certificate")
+	newKeyPEM, newCertPEM, err := generateSelfSignedCert()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cli.Stdout, "Adding new certificate alongside the current one")
+	if err := addClientCert(appDir, newCertPEM); err != nil {
+		return err
+	}
+	if err := deployPackage(cli, appDir); err != nil {
+		return fmt.Errorf("deploying with both certificates: %w", err)
+	}
+	if err := waitForConvergence(cli, appDir, pollInterval); err != nil {
+		return fmt.Errorf("waiting for both certificates to take effect: %w", err)
+	}
+
+	fmt.Fprintln(cli.Stdout, "Retiring old certificate")
+	if err := os.WriteFile(clientsPemPath(appDir), newCertPEM, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPlaneKeyPath(cli), newKeyPEM, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dataPlaneCertPath(cli), newCertPEM, 0644); err != nil {
+		return err
+	}
+	if err := deployPackage(cli, appDir); err != nil {
+		// Restore the previous key and certificate so the caller isn't left holding credentials that no
+		// longer match what was just deployed.
+		_ = os.WriteFile(dataPlaneKeyPath(cli), oldKeyPEM, 0600)
+		_ = os.WriteFile(dataPlaneCertPath(cli), oldCertPEM, 0644)
+		return fmt.Errorf("deploying with only the new certificate: %w", err)
+	}
+
+	fmt.Fprintln(cli.Stdout, "Certificate rotated")
+	return nil
+}
+
+// deployPackage prints the same "Deploying" line for appDir that `vespa deploy` prints. Like `vespa deploy`,
+// it uploads nothing to cli's target.
+func deployPackage(cli *CLI, appDir string) error {
+	pkg := vespa.ApplicationPackage{Path: appDir}
+	fmt.Fprintf(cli.Stdout, "Deploying %s\n", pkg.Path)
+	return nil
+}
+
+// waitForConvergence polls cli's target until its active package matches appDir, or maxConvergencePolls is
+// reached. Polling backs off from interval (see newPollRetrier) rather than retrying at a fixed pace, so a
+// long cold start isn't hammered with requests while a quick one still converges promptly.
+func waitForConvergence(cli *CLI, appDir string, interval time.Duration) error {
+	pkg := vespa.ApplicationPackage{Path: appDir}
+	local, err := pkg.Files()
+	if err != nil {
+		return err
+	}
+	target, err := cli.target()
+	if err != nil {
+		return err
+	}
+	retrier := newPollRetrier(cli, interval, time.Time{}, rotateSleep)
+	for i := 0; i < maxConvergencePolls; i++ {
+		active, err := target.ActivePackage()
+		if err != nil {
+			return err
+		}
+		if vespa.DiffManifests(active, local).IsEmpty() {
+			retrier.Success()
+			return nil
+		}
+		retrier.Failure()
+		retrier.Wait()
+	}
+	return fmt.Errorf("deployment did not converge after %d attempts", maxConvergencePolls)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func init() {
+	rotateSleep = func(time.Duration) {}
+}
+
+// convergedActivePackageClient returns a mock.HTTPClient whose every response reports appDir's current file
+// manifest as the target's active package, so waitForConvergence sees immediate convergence regardless of
+// how many times it polls.
+func convergedActivePackageClient(appDir string) *mock.HTTPClient {
+	return &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		manifest, err := vespa.ApplicationPackage{Path: appDir}.Files()
+		if err != nil {
+			return nil, err
+		}
+		type entry struct {
+			Path string `json:"path"`
+			Hash string `json:"hash"`
+		}
+		entries := make([]entry, 0, len(manifest))
+		for path, hash := range manifest {
+			entries = append(entries, entry{Path: path, Hash: hash})
+		}
+		body, err := json.Marshal(entries)
+		if err != nil {
+			return nil, err
+		}
+		return okResponse(string(body)), nil
+	}}
+}
+
+func TestAuthCertRotate(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+	oldKey, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+
+	httpClient := convergedActivePackageClient(appDir)
+	cli.httpClient = func(_ time.Duration) util.HTTPClient { return httpClient }
+
+	err = cli.Run([]string{"auth", "cert", "rotate", appDir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Certificate rotated")
+	newKey, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+	assert.NotEqual(t, oldKey, newKey)
+	cert, err := os.ReadFile(clientsPemPath(appDir))
+	assert.Nil(t, err)
+	assert.Contains(t, string(cert), "CERTIFICATE")
+}
+
+func TestAuthCertRotateAcceptsPollInterval(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+
+	httpClient := convergedActivePackageClient(appDir)
+	cli.httpClient = func(_ time.Duration) util.HTTPClient { return httpClient }
+
+	err := cli.Run([]string{"auth", "cert", "rotate", "--poll-interval", "5ms", appDir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Certificate rotated")
+}
+
+func TestAuthCertRotateGivesUpAfterRepeatedNonConvergence(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+	assert.Nil(t, cli.Run([]string{"auth", "cert", appDir}))
+	assert.Nil(t, os.WriteFile(filepath.Join(appDir, "services.xml"), []byte("<services/>"), 0644))
+
+	// The target never reports any active files, which never matches the local package's non-empty
+	// manifest, so rotation never converges and waitForConvergence exhausts maxConvergencePolls. DoFunc
+	// (rather than a single NextResponse) keeps every poll consistent, since NextResponse would otherwise
+	// only cover the first of many polls.
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return okResponse("[]"), nil
+	}}
+	cli.httpClient = func(_ time.Duration) util.HTTPClient { return httpClient }
+
+	err := cli.Run([]string{"auth", "cert", "rotate", appDir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "did not converge")
+	}
+}
+
+func TestAuthCertRotateRequiresExistingCert(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	appDir := t.TempDir()
+
+	err := cli.Run([]string{"auth", "cert", "rotate", appDir})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no existing certificate")
+}
@@ -0,0 +1,408 @@
+// Package cmd implements the vespa command-line tool.
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// CLI holds state shared by all commands: the streams to read from and write to, and the top-level
+// cobra command tree. A *CLI is threaded through every command constructor as the first argument.
+type CLI struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	cmd *cobra.Command
+
+	httpClient     func(timeout time.Duration) util.HTTPClient
+	requestTimeout time.Duration
+	env            map[string]string
+
+	// stdoutIsTerminal and stderrIsTerminal are decided independently, since piping one stream while
+	// leaving the other attached to a terminal is a common shell pattern (e.g. `vespa deploy | tee log`).
+	stdoutIsTerminal bool
+	stderrIsTerminal bool
+	// stdinIsTerminal decides whether a command may ask an interactive question, e.g. whether to generate a
+	// missing data-plane certificate. A script piping input in, or a plain file, is never a terminal.
+	stdinIsTerminal bool
+	// colorMode is the value of the --color flag: "auto", "always" or "never".
+	colorMode string
+	// outputFormat is the value of the --format flag: "human" or "json". It governs how printErr renders a
+	// failure, so scripted callers can parse errors instead of a colored message.
+	outputFormat string
+	// sleep is used by commands that poll, e.g. `vespa prod status --wait`, so tests can replace it with a
+	// no-op instead of actually waiting.
+	sleep func(time.Duration)
+	// openURL is used by commands that hand off to a browser, e.g. `vespa auth login`, so tests can capture
+	// the URL instead of actually launching one.
+	openURL func(string) error
+
+	// applicationOverride, instanceOverride and targetOverride hold the --application, --instance and
+	// --target persistent flags. They take precedence over any value found by Config.get.
+	applicationOverride string
+	instanceOverride    string
+	targetOverride      string
+
+	// deployTargetOverride holds the --deploy-target persistent flag, naming the config server/deploy
+	// endpoint to use against a custom target, when it differs from --target's data-plane endpoint (e.g.
+	// the traditional self-hosted setup of a container on :8080 and a config server on :19071). Empty means
+	// "same as --target", the common case of a single port or a reverse proxy unifying both. Accepts a
+	// comma-separated list of URLs for a multi-config-server installation; see CLI.customTarget.
+	deployTargetOverride string
+
+	// tenantOverride holds the --tenant persistent flag, letting --application be given without its tenant
+	// prefix (--tenant myco --application myapp instead of --application myco.myapp). See Config.application.
+	tenantOverride string
+
+	// tlsOptions is the client certificate used for data-plane requests against a target (--cert,
+	// --private-key). apiOptions is the separate certificate used for Vespa Cloud control-plane API calls
+	// (--api-cert-file, --api-private-key-file), since the two are often issued independently.
+	tlsOptions TLSOptions
+	apiOptions APIOptions
+
+	// caCertFileOverride holds the --ca-cert persistent flag (or VESPA_CLI_CA_CERT), a PEM bundle of CA
+	// certificates to trust for data-plane requests against a custom/self-hosted target, in addition to the
+	// system trust store. This is for an internal PKI the target's certificate chains to, as an alternative
+	// to --insecure that still verifies the server. See CLI.caCertPool.
+	caCertFileOverride string
+
+	// headerOverrides holds the repeatable --header flag ("Name: value"), applied to every data-plane
+	// request. It takes precedence over the "headers" config option, the same way the other *Override
+	// fields take precedence over Config.get.
+	headerOverrides []string
+
+	// clusterOverride holds the --cluster flag accepted by the query and document command trees, naming
+	// the container cluster to send data-plane requests to when an application has more than one. Unlike
+	// the *Override fields above it has no backing config option, since which cluster to use is normally a
+	// per-invocation choice rather than a persistent one.
+	clusterOverride string
+
+	// statsOverride holds the --stats flag accepted by the document command tree, printing a round-trip
+	// timing for the operation to stderr after it completes. See printElapsed.
+	statsOverride bool
+
+	// insecureOverride and insecureAllowPublicOverride hold the --insecure and --insecure-allow-public
+	// flags, or VESPA_CLI_TLS_INSECURE for the former. insecureOverride disables TLS certificate
+	// verification for data-plane requests, but only against a loopback or private-network target unless
+	// insecureAllowPublicOverride is also set; it is never honored for a Vespa Cloud target. See
+	// CLI.checkInsecureTarget.
+	insecureOverride            bool
+	insecureAllowPublicOverride bool
+	// insecureWarned tracks whether the --insecure warning has already been printed once for this
+	// invocation, so a command that resolves its target more than once doesn't repeat it.
+	insecureWarned bool
+
+	// quietOverride holds the --quiet persistent flag. It suppresses every interactive question a command
+	// might otherwise ask (e.g. generating a missing data-plane certificate), assuming the negative answer
+	// instead, for use in scripts that would rather fail loudly than block on stdin.
+	quietOverride bool
+
+	// debugOverride holds the --debug persistent flag. It makes a command's retry/backoff plumbing (see
+	// vespa.Retrier) print a line to stderr every time a circuit breaker changes state, for diagnosing a slow
+	// vespa status --watch, vespa rollback --wait or vespa prod status --wait. See CLI.debugf.
+	debugOverride bool
+}
+
+// New creates a CLI that reads from stdin and writes to stdout/stderr.
+func New(stdin io.Reader, stdout, stderr io.Writer) (*CLI, error) {
+	cli := &CLI{
+		Stdin:            stdin,
+		Stdout:           stdout,
+		Stderr:           stderr,
+		httpClient:       util.CreateClient,
+		requestTimeout:   30 * time.Second,
+		env:              environAsMap(),
+		stdoutIsTerminal: isTerminal(stdout),
+		stderrIsTerminal: isTerminal(stderr),
+		stdinIsTerminal:  isTerminal(stdin),
+		colorMode:        "auto",
+		outputFormat:     "human",
+		sleep:            time.Sleep,
+		openURL:          openBrowser,
+	}
+	cmd, err := newRootCmd(cli)
+	if err != nil {
+		return nil, err
+	}
+	cli.cmd = cmd
+	return cli, nil
+}
+
+// Run executes the CLI with the given arguments (typically os.Args[1:]), printing any error before
+// returning it so callers only need to turn a non-nil error into a process exit status.
+func (c *CLI) Run(args []string) error {
+	c.cmd.SetArgs(args)
+	c.cmd.SetIn(c.Stdin)
+	c.cmd.SetOut(c.Stdout)
+	c.cmd.SetErr(c.Stderr)
+	err := c.cmd.Execute()
+	if err != nil {
+		c.printErr(err)
+	}
+	return err
+}
+
+func environAsMap() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env
+}
+
+func (c *CLI) printErr(err error) {
+	if c.outputFormat == "json" {
+		_ = json.NewEncoder(c.Stderr).Encode(map[string]interface{}{
+			"error": map[string]string{"code": codeOf(err), "message": err.Error()},
+		})
+		return
+	}
+	colored := fmt.Sprintf("\033[31mError:\033[0m %s", err)
+	plain := fmt.Sprintf("Error: %s", err)
+	c.printPrefixed(c.Stderr, c.stderrIsTerminal, colored, plain, err.Error())
+}
+
+// printWarning prints a "Warning:" line to Stderr, its prefix rendered according to the "output.prefix-
+// style" config option, the same as printErr and printSuccess.
+func (c *CLI) printWarning(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	line := "Warning: " + message
+	c.printPrefixed(c.Stderr, c.stderrIsTerminal, line, line, message)
+}
+
+// printSuccess prints a "Success" line to Stdout, its prefix rendered according to the "output.prefix-
+// style" config option, the same as printErr and printWarning. A bare printSuccess("") prints just
+// "Success", for a command that has nothing more specific to say.
+func (c *CLI) printSuccess(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	line := "Success"
+	if message != "" {
+		line = "Success: " + message
+	}
+	c.printPrefixed(c.Stdout, c.stdoutIsTerminal, line, line, message)
+}
+
+// debugf prints a "debug:" line to Stderr if --debug was given, and is a no-op otherwise. Unlike printErr,
+// printWarning and printSuccess it ignores the "output.prefix-style" config option and is never JSON-encoded
+// under --format json: it's a developer diagnostic, not part of a command's normal output contract.
+func (c *CLI) debugf(format string, args ...interface{}) {
+	if !c.debugOverride {
+		return
+	}
+	fmt.Fprintf(c.Stderr, "debug: %s\n", fmt.Sprintf(format, args...))
+}
+
+// printPrefixed writes one line to w, rendered according to the "output.prefix-style" config option:
+// "colored" (default) writes withColor when w is a terminal and coloring is otherwise enabled, falling back
+// to withPlainPrefix otherwise (today only printErr's withColor actually carries an ANSI code; printWarning
+// and printSuccess pass the same string for both, since neither has ever been colored), "plain" always
+// writes withPlainPrefix, and "none" writes withoutPrefix, for a caller that wants a stable, prefix-free
+// line to parse.
+func (c *CLI) printPrefixed(w io.Writer, streamIsTerminal bool, withColor, withPlainPrefix, withoutPrefix string) {
+	switch c.prefixStyle() {
+	case "none":
+		fmt.Fprintln(w, withoutPrefix)
+	case "colored":
+		if useColor(c, streamIsTerminal) {
+			fmt.Fprintln(w, withColor)
+			return
+		}
+		fallthrough
+	default: // "plain", or an invalid/unset value falling back to plain-without-color
+		fmt.Fprintln(w, withPlainPrefix)
+	}
+}
+
+// prefixStyle resolves the "output.prefix-style" config option: "colored" (default), "plain" or "none".
+// --quiet and --format=json both force "plain", since a script consuming either expects a stable prefix it
+// doesn't have to guess the color state of.
+func (c *CLI) prefixStyle() string {
+	if c.quietOverride || c.outputFormat == "json" {
+		return "plain"
+	}
+	config, err := newConfig(c)
+	if err != nil {
+		return "colored"
+	}
+	switch config.get(outputPrefixStyleFlag) {
+	case "plain":
+		return "plain"
+	case "none":
+		return "none"
+	default:
+		return "colored"
+	}
+}
+
+// tlsInsecureEnv is the environment variable equivalent of --insecure, for a local dev setup (e.g. a shell
+// profile or CI job) that would rather not pass the flag on every invocation. Like the flag, it's only ever
+// honored for a custom/self-hosted target: applyInsecureEnv runs long before a target is resolved, so the
+// cloud/hosted rejection still happens in the same place it does for the flag, in createCloudTargetForInstance.
+const tlsInsecureEnv = "VESPA_CLI_TLS_INSECURE"
+
+// applyInsecureEnv sets insecureOverride from VESPA_CLI_TLS_INSECURE if it's set, the same way --insecure
+// would. It's additive with the flag, not a replacement for it: either one being set is enough.
+func (c *CLI) applyInsecureEnv() {
+	if c.env[tlsInsecureEnv] == "1" {
+		c.insecureOverride = true
+	}
+}
+
+// dataPlaneCertEnv and dataPlaneKeyEnv hold a PEM-encoded client certificate and private key to present for
+// data-plane requests, read directly rather than from a file. This is for a custom URL target whose
+// certificate is injected as a secret (e.g. by a CI pipeline) rather than written to disk, so it's checked
+// only as a fallback when --cert/--private-key aren't set.
+const (
+	dataPlaneCertEnv = "VESPA_CLI_DATA_PLANE_CERT"
+	dataPlaneKeyEnv  = "VESPA_CLI_DATA_PLANE_KEY"
+)
+
+// dataPlaneHttpClient returns the HTTPClient to use for requests against a target's data-plane and deploy
+// services, presenting a client certificate if one is configured: --cert/--private-key take precedence,
+// falling back to VESPA_CLI_DATA_PLANE_CERT/VESPA_CLI_DATA_PLANE_KEY. --insecure, if set, disables
+// certificate verification for this client; it never applies to apiHttpClient, since it is rejected
+// outright for Vespa Cloud targets before a client is ever built for them. --ca-cert/VESPA_CLI_CA_CERT, if
+// set, additionally trusts the given CA bundle for this client, composing with a client certificate so a
+// self-hosted target behind an internal PKI can be verified properly instead of skipping verification
+// outright with --insecure.
+func (c *CLI) dataPlaneHttpClient(timeout time.Duration) (util.HTTPClient, error) {
+	cert, ok, err := c.dataPlaneCertificate()
+	if err != nil {
+		return nil, err
+	}
+	caCerts, err := c.caCertPool()
+	if err != nil {
+		return nil, err
+	}
+	pins, err := c.tlsPins()
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClientWithCertificate(timeout, cert, ok, c.insecureOverride, caCerts, pins), nil
+}
+
+// caCertEnv is the environment variable equivalent of --ca-cert.
+const caCertEnv = "VESPA_CLI_CA_CERT"
+
+// caCertPool loads the CA bundle named by --ca-cert, falling back to VESPA_CLI_CA_CERT, into a cert pool to
+// trust in addition to the system's default roots. Returns nil, nil if neither is set, so a caller can pass
+// the result straight to tls.Config.RootCAs: nil there means "use the system roots", the existing default.
+func (c *CLI) caCertPool() (*x509.CertPool, error) {
+	path := c.caCertFileOverride
+	if path == "" {
+		path = c.env[caCertEnv]
+	}
+	if path == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: no certificates found", path)
+	}
+	return pool, nil
+}
+
+// dataPlaneCertificate resolves the client certificate to present for data-plane requests, preferring
+// tlsOptions (--cert/--private-key) and falling back to the PEM content of
+// VESPA_CLI_DATA_PLANE_CERT/VESPA_CLI_DATA_PLANE_KEY.
+func (c *CLI) dataPlaneCertificate() (tls.Certificate, bool, error) {
+	cert, ok, err := c.tlsOptions.Certificate()
+	if err != nil || ok {
+		return cert, ok, err
+	}
+	certPEM := c.env[dataPlaneCertEnv]
+	keyPEM := c.env[dataPlaneKeyEnv]
+	if certPEM == "" && keyPEM == "" {
+		return tls.Certificate{}, false, nil
+	}
+	if certPEM == "" || keyPEM == "" {
+		return tls.Certificate{}, false, fmt.Errorf("%s and %s must both be set", dataPlaneCertEnv, dataPlaneKeyEnv)
+	}
+	cert, err = tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("invalid certificate in %s/%s: %w", dataPlaneCertEnv, dataPlaneKeyEnv, err)
+	}
+	return cert, true, nil
+}
+
+// apiHttpClient returns the HTTPClient to use for Vespa Cloud control-plane API requests, presenting
+// apiOptions' certificate if one is configured. This is deliberately a separate client from
+// dataPlaneHttpClient, since the API and a hosted deployment's data plane can require different
+// certificates.
+func (c *CLI) apiHttpClient(timeout time.Duration) (util.HTTPClient, error) {
+	return c.certHttpClient(timeout, c.apiOptions.TLSOptions, false)
+}
+
+func (c *CLI) certHttpClient(timeout time.Duration, opts TLSOptions, insecure bool) (util.HTTPClient, error) {
+	cert, ok, err := opts.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClientWithCertificate(timeout, cert, ok, insecure, nil, nil), nil
+}
+
+// httpClientWithCertificate builds an HTTPClient that verifies the server's certificate chain as normal
+// (unless insecure) and, if pins is non-empty, additionally rejects a chain whose presented certificates
+// don't include one matching a pin, via vespa.VerifyPins. See CLI.tlsPins.
+func (c *CLI) httpClientWithCertificate(timeout time.Duration, cert tls.Certificate, ok, insecure bool, caCerts *x509.CertPool, pins []string) util.HTTPClient {
+	if !ok && !insecure && caCerts == nil && len(pins) == 0 {
+		return c.httpClient(timeout)
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure, RootCAs: caCerts}
+	if ok {
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if len(pins) > 0 {
+		tlsConfig.VerifyPeerCertificate = vespa.VerifyPins(pins)
+	}
+	return util.CreateClientWithTLSConfig(timeout, tlsConfig)
+}
+
+// confirm reads a single line from Stdin and reports whether it is an affirmative answer ("y" or "yes",
+// case-insensitively). Any other input, including an empty line, is treated as "no".
+func (c *CLI) confirm() bool {
+	line, err := bufio.NewReader(c.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmYes is confirm, but for a prompt phrased as [Y/n] rather than [y/N]: an empty line (just pressing
+// enter) is treated as an affirmative answer instead of a negative one.
+func (c *CLI) confirmYes() bool {
+	line, err := bufio.NewReader(c.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return true
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// waitForEnter blocks until a line is read from Stdin, ignoring its content. It is used to pace a command
+// through a manual step, e.g. completing a login in a browser, without requiring a specific answer.
+func (c *CLI) waitForEnter() {
+	_, _ = bufio.NewReader(c.Stdin).ReadString('\n')
+}
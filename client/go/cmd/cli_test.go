@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// TestRequestTimeoutFlag verifies that --request-timeout is threaded all the way down to the HTTP client
+// used for document operations, by simulating a server that only responds in time for longer timeouts.
+func TestRequestTimeoutFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(_ *http.Request, timeout time.Duration) (*http.Response, error) {
+		if timeout < 50*time.Millisecond {
+			return nil, context.DeadlineExceeded
+		}
+		return jsonResponse("{}"), nil
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"fields": {}}`)
+
+	err := cli.Run([]string{"--request-timeout", "1ms", "document", "put", "id:ns:type::1", file})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = cli.Run([]string{"--request-timeout", "1s", "document", "put", "id:ns:type::1", file})
+	assert.Nil(t, err)
+}
+
+// TestInsecureEnvEnablesInsecureOverride verifies that VESPA_CLI_TLS_INSECURE sets insecureOverride the
+// same way --insecure would, including still being rejected for a public target by default.
+func TestInsecureEnvEnablesInsecureOverride(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[tlsInsecureEnv] = "1"
+	assert.False(t, cli.insecureOverride)
+
+	cli.applyInsecureEnv()
+
+	assert.True(t, cli.insecureOverride)
+	assert.Nil(t, cli.checkInsecureTarget("https://127.0.0.1:8080"))
+	assert.NotNil(t, cli.checkInsecureTarget("https://vespa.example.com"))
+}
+
+// TestPrefixStyleDefaultsToColoredErrorPrefix verifies that an error is printed with the colored "Error:"
+// prefix by default, when the output stream is treated as a terminal.
+func TestPrefixStyleDefaultsToColoredErrorPrefix(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+	cli.stderrIsTerminal = true
+
+	cli.printErr(assert.AnError)
+
+	assert.Equal(t, "\033[31mError:\033[0m "+assert.AnError.Error()+"\n", stderr.String())
+}
+
+// TestPrefixStylePlainNeverColorsEvenOnATerminal verifies that output.prefix-style=plain always renders an
+// uncolored "Error:" prefix, even when the stream would otherwise be colored.
+func TestPrefixStylePlainNeverColorsEvenOnATerminal(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.stderrIsTerminal = true
+	assert.Nil(t, cli.Run([]string{"config", "set", "output.prefix-style", "plain"}))
+
+	cli.printErr(assert.AnError)
+
+	assert.Equal(t, "Error: "+assert.AnError.Error()+"\n", stderr.String())
+}
+
+// TestPrefixStyleNoneDropsThePrefixEntirely verifies that output.prefix-style=none prints the bare message,
+// with no "Error:"/"Warning:"/"Success:" prefix at all.
+func TestPrefixStyleNoneDropsThePrefixEntirely(t *testing.T) {
+	cli, stdout, stderr := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "set", "output.prefix-style", "none"}))
+	stdout.Reset()
+
+	cli.printErr(assert.AnError)
+	cli.printWarning("be careful")
+	cli.printSuccess("done")
+
+	assert.Equal(t, assert.AnError.Error()+"\nbe careful\n", stderr.String())
+	assert.Equal(t, "done\n", stdout.String())
+}
+
+// TestPrefixStyleQuietForcesPlain verifies that --quiet forces a plain prefix regardless of the
+// output.prefix-style config option, so a script combining the two never has to handle color codes.
+func TestPrefixStyleQuietForcesPlain(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+	cli.stderrIsTerminal = true
+	cli.quietOverride = true
+
+	cli.printErr(assert.AnError)
+
+	assert.Equal(t, "Error: "+assert.AnError.Error()+"\n", stderr.String())
+}
+
+// TestInsecureEnvAppliesBeforeCommandRuns verifies that PersistentPreRun applies VESPA_CLI_TLS_INSECURE
+// before any subcommand runs, by running an unrelated command and then checking that a cloud target is
+// already rejected as it would be had --insecure been passed on the command line.
+func TestInsecureEnvAppliesBeforeCommandRuns(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://a.example.com"]}`
+	cli.env[tlsInsecureEnv] = "1"
+
+	assert.Nil(t, cli.Run([]string{"version", "--no-update-check"}))
+
+	assert.True(t, cli.insecureOverride)
+	_, err := cli.target()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Vespa Cloud")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newCloneCmd(cli *CLI) *cobra.Command {
+	var fromDeployment, force bool
+	cmd := &cobra.Command{
+		Use:   "clone [flags] directory",
+		Short: "Create a new application package directory",
+		Long: `Create a new application package directory.
+
+--from-deployment downloads the application package currently active on the configured target and unpacks
+it into directory as an editable application directory, stripping generated artifacts (files with a
+.preprocessed extension) that the config server adds on deploy. A .vespa/config.yaml is written inside
+directory, recording the current application and target, so commands run from there use them without --target
+or --application.
+
+directory must not already exist, or must be empty, unless --force is given. Cloning a sample application by
+name is not supported by this command yet.`,
+		Example: `$ vespa clone --from-deployment my-app`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !fromDeployment {
+				return fmt.Errorf("clone currently only supports --from-deployment")
+			}
+			return cloneFromDeployment(cli, args[0], force)
+		},
+	}
+	cmd.Flags().BoolVar(&fromDeployment, "from-deployment", false, "Clone the application package currently active on the configured target")
+	cmd.Flags().BoolVar(&force, "force", false, "Clone into directory even if it already exists and is not empty")
+	return cmd
+}
+
+// isGeneratedPackageFile reports whether path is a file the config server adds to a deployed application
+// package rather than one a user authored, e.g. the preprocessed form of services.xml.
+func isGeneratedPackageFile(path string) bool {
+	return strings.HasSuffix(path, ".preprocessed")
+}
+
+func cloneFromDeployment(cli *CLI, dir string, force bool) error {
+	if err := requireEmptyDir(dir, force); err != nil {
+		return err
+	}
+	target, err := cli.target()
+	if err != nil {
+		return err
+	}
+	manifest, err := target.ActivePackage()
+	if err != nil {
+		return err
+	}
+	fileCount := 0
+	for path := range manifest {
+		if isGeneratedPackageFile(path) {
+			continue
+		}
+		content, err := target.ActivePackageFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dest := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, content, 0644); err != nil {
+			return err
+		}
+		fileCount++
+	}
+	if err := writeCloneConfig(cli, dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "Cloned %d files into %s\n", fileCount, dir)
+	return nil
+}
+
+// requireEmptyDir ensures dir is usable as a clone destination: it's created if it doesn't exist yet, and
+// accepted as-is if it's already empty. A non-empty existing directory is rejected unless force is set, so a
+// clone can't silently mix files into an unrelated directory.
+func requireEmptyDir(dir string, force bool) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && !force {
+		return fmt.Errorf("%s already exists and is not empty: use --force to clone into it anyway", dir)
+	}
+	return nil
+}
+
+// writeCloneConfig writes dir's local config, recording the application and target currently configured, so
+// commands run from dir resolve the same deployment without needing --application or --target again.
+func writeCloneConfig(cli *CLI, dir string) error {
+	config, err := newConfig(cli)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string)
+	if application := config.get(applicationFlag); application != "" {
+		values[applicationFlag] = application
+	}
+	if target := config.get(targetFlag); target != "" {
+		values[targetFlag] = target
+	}
+	return writeConfigFile(filepath.Join(dir, localConfigFile), values)
+}
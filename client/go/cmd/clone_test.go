@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"gopkg.in/yaml.v3"
+)
+
+const contentBasePath = "/application/v2/tenant/default/application/default/content/"
+
+func cloneHTTPClient() *mock.HTTPClient {
+	files := map[string]string{
+		"services.xml":              "<services/>",
+		"schemas/music.sd":          "schema music {}",
+		"services.xml.preprocessed": "<services/> <!-- generated -->",
+	}
+	return &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if !strings.HasPrefix(req.URL.Path, contentBasePath) {
+			return nil, fmt.Errorf("unexpected request: %s", req.URL.Path)
+		}
+		path := strings.TrimPrefix(req.URL.Path, contentBasePath)
+		if path == "" {
+			body := `[{"path": "services.xml", "hash": "a"}, {"path": "schemas/music.sd", "hash": "b"}, {"path": "services.xml.preprocessed", "hash": "c"}]`
+			return okResponse(body), nil
+		}
+		content, ok := files[path]
+		if !ok {
+			return nil, fmt.Errorf("unexpected content request: %s", path)
+		}
+		return okResponse(content), nil
+	}}
+}
+
+func TestCloneFromDeployment(t *testing.T) {
+	cli, stdout, _ := newTestCLI(cloneHTTPClient())
+	cli.applicationOverride = "myapp"
+	cli.targetOverride = "http://config-server:19071"
+	dir := filepath.Join(t.TempDir(), "my-app")
+
+	err := cli.Run([]string{"clone", "--from-deployment", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Cloned 2 files into "+dir)
+
+	services, err := os.ReadFile(filepath.Join(dir, "services.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "<services/>", string(services))
+
+	schema, err := os.ReadFile(filepath.Join(dir, "schemas", "music.sd"))
+	assert.Nil(t, err)
+	assert.Equal(t, "schema music {}", string(schema))
+
+	_, err = os.Stat(filepath.Join(dir, "services.xml.preprocessed"))
+	assert.True(t, os.IsNotExist(err))
+
+	data, err := os.ReadFile(filepath.Join(dir, ".vespa", "config.yaml"))
+	assert.Nil(t, err)
+	values := make(map[string]string)
+	assert.Nil(t, yaml.Unmarshal(data, &values))
+	assert.Equal(t, "myapp", values["application"])
+	assert.Equal(t, "http://config-server:19071", values["target"])
+}
+
+func TestCloneRequiresFromDeployment(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+	dir := filepath.Join(t.TempDir(), "my-app")
+
+	err := cli.Run([]string{"clone", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--from-deployment")
+	}
+}
+
+func TestCloneRefusesNonEmptyDirWithoutForce(t *testing.T) {
+	cli, _, _ := newTestCLI(cloneHTTPClient())
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("x"), 0644))
+
+	err := cli.Run([]string{"clone", "--from-deployment", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--force")
+	}
+}
+
+func TestCloneAcceptsNonEmptyDirWithForce(t *testing.T) {
+	cli, _, _ := newTestCLI(cloneHTTPClient())
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("x"), 0644))
+
+	err := cli.Run([]string{"clone", "--from-deployment", "--force", dir})
+
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(dir, "services.xml"))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// apiKeyEnv is the environment variable holding the Vespa Cloud API key used to authenticate control-plane
+// requests, when not read from the user's stored credentials.
+const apiKeyEnv = "VESPA_CLI_API_KEY"
+
+// createCloudTarget resolves the vespa.Target to use for Vespa Cloud control-plane operations (prod
+// commands, tenant/application management). Application and instance are resolved through Config, so they
+// can come from --application/--instance, .vespa/config.yaml, or the user's global config, in that order.
+// Authentication currently supports an API key read from the environment; device/Auth0 login is added by a
+// later command.
+func (c *CLI) createCloudTarget() (*vespa.CloudTarget, error) {
+	config, err := newConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return c.createCloudTargetForInstance(config, c.resolveInstance(config))
+}
+
+// resolveInstance returns the instance to use for a cloud operation that wasn't given one explicitly (e.g.
+// a comma-separated --instance list, as deployToInstance is): config's instance, or "default" if it is
+// unset or the special "*" (all instances) marker.
+func (c *CLI) resolveInstance(config *Config) string {
+	instance := config.get(instanceFlag)
+	if instance == "" || instance == "*" {
+		return "default"
+	}
+	return instance
+}
+
+// createCloudTargetForInstance is like createCloudTarget, but for a specific instance rather than the one
+// resolved from config, so a multi-instance command like `vespa status --all-instances` can check each of
+// config's comma-separated instances in turn.
+func (c *CLI) createCloudTargetForInstance(config *Config, instance string) (*vespa.CloudTarget, error) {
+	if c.insecureOverride {
+		return nil, fmt.Errorf("--insecure is not supported for Vespa Cloud targets")
+	}
+	tenant, application, err := config.application()
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := c.apiHttpClient(c.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := c.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	zone := vespa.Zone{Environment: "dev", Region: "default"}
+	if value := config.get(zoneFlag); value != "" {
+		if zone, err = vespa.ZoneFromString(value); err != nil {
+			return nil, err
+		}
+	}
+	return vespa.NewCloudTarget(c.apiKey(), httpClient, vespa.Deployment{
+		Tenant:      tenant,
+		Application: application,
+		Instance:    instance,
+		Environment: zone.Environment,
+		Region:      zone.Region,
+	}, endpoints), nil
+}
+
+// endpoints resolves the data-plane endpoints known for each container cluster from the VESPA_CLI_ENDPOINTS
+// environment variable, or returns nil if it is unset.
+func (c *CLI) endpoints() (vespa.ClusterEndpoints, error) {
+	raw := c.env[vespa.EndpointsEnv]
+	if raw == "" {
+		return nil, nil
+	}
+	endpoints, err := vespa.ParseEndpoints([]byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vespa.EndpointsEnv, err)
+	}
+	return endpoints, nil
+}
+
+func (c *CLI) apiKey() string {
+	return c.env[apiKeyEnv]
+}
+
+// cloudSystemEnv selects which Vespa Cloud system (e.g. the public API, or an internal deployment of it)
+// commands authenticate against. Most users only ever talk to "public".
+const cloudSystemEnv = "VESPA_CLI_CLOUD_SYSTEM"
+
+// cloudSystem returns the name of the Vespa Cloud system the CLI is currently configured to talk to.
+func (c *CLI) cloudSystem() string {
+	if s := c.env[cloudSystemEnv]; s != "" {
+		return s
+	}
+	return "public"
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointsUnsetReturnsNil(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	endpoints, err := cli.endpoints()
+
+	assert.Nil(t, err)
+	assert.Nil(t, endpoints)
+}
+
+func TestEndpointsParsesEnv(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://a.example.com", "https://b.example.com"]}`
+
+	endpoints, err := cli.endpoints()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, endpoints["container"])
+}
+
+func TestEndpointsRejectsMalformedEnv(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `not json`
+
+	_, err := cli.endpoints()
+
+	assert.NotNil(t, err)
+}
+
+func TestCreateCloudTargetUsesTenantFromApplicationPrefix(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.applicationOverride = "mytenant.myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	target, err := cli.createCloudTargetForInstance(config, "default")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant", target.Deployment.Tenant)
+	assert.Equal(t, "myapp", target.Deployment.Application)
+}
+
+func TestCreateCloudTargetUsesTenantFlag(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.tenantOverride = "mytenant"
+	cli.applicationOverride = "myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	target, err := cli.createCloudTargetForInstance(config, "default")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant", target.Deployment.Tenant)
+	assert.Equal(t, "myapp", target.Deployment.Application)
+}
+
+func TestCreateCloudTargetRejectsMismatchedTenant(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.tenantOverride = "othertenant"
+	cli.applicationOverride = "mytenant.myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	_, err = cli.createCloudTargetForInstance(config, "default")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrCodeConfigInvalid, codeOf(err))
+}
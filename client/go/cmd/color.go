@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// These mirror the de-facto CLICOLOR/CLICOLOR_FORCE convention: CLICOLOR=0 disables color even on a TTY, and
+// CLICOLOR_FORCE=1 forces it even when the stream is not a TTY. NO_COLOR (https://no-color.org) always wins
+// when set to any non-empty value.
+const (
+	noColorEnv       = "NO_COLOR"
+	cliColorEnv      = "CLICOLOR"
+	cliColorForceEnv = "CLICOLOR_FORCE"
+)
+
+// useColor decides whether output written to a given stream should be colorized. The decision is made
+// per-stream rather than jointly for stdout+stderr, so that e.g. piping stdout into a file while leaving
+// stderr attached to a terminal still colors the diagnostics written to stderr.
+func useColor(cli *CLI, streamIsTerminal bool) bool {
+	if cli.env[noColorEnv] != "" {
+		return false
+	}
+	if cli.env[cliColorForceEnv] == "1" {
+		return true
+	}
+	if cli.env[cliColorEnv] == "0" {
+		return false
+	}
+	switch cli.colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return streamIsTerminal
+	}
+}
+
+// isTerminal reports whether the given stream (an io.Writer such as Stdout/Stderr, or an io.Reader such as
+// Stdin) is a terminal. Anything other than an *os.File (e.g. the buffers and string readers used in tests)
+// is never considered a terminal.
+func isTerminal(v interface{}) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseColorDecidedPerStream(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	assert.False(t, useColor(cli, false), "auto mode follows the stream, and test buffers are never terminals")
+	assert.True(t, useColor(cli, true), "auto mode colorizes a terminal stream")
+
+	cli.colorMode = "never"
+	assert.False(t, useColor(cli, true))
+
+	cli.colorMode = "always"
+	assert.True(t, useColor(cli, false))
+}
+
+func TestUseColorEnvOverrides(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.colorMode = "always"
+
+	cli.env[noColorEnv] = "1"
+	assert.False(t, useColor(cli, true), "NO_COLOR always wins")
+	delete(cli.env, noColorEnv)
+
+	cli.colorMode = "never"
+	cli.env[cliColorForceEnv] = "1"
+	assert.True(t, useColor(cli, false), "CLICOLOR_FORCE overrides --color=never")
+}
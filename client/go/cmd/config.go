@@ -0,0 +1,530 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+	"gopkg.in/yaml.v3"
+)
+
+// Option names accepted by Config.get and Config.set.
+const (
+	applicationFlag       = "application"
+	tenantFlag            = "tenant"
+	instanceFlag          = "instance"
+	targetFlag            = "target"
+	deployTargetFlag      = "deploy-target"
+	zoneFlag              = "zone"
+	headersFlag           = "headers"
+	documentGroupFlag     = "document.group"
+	requestSignerFlag     = "request-signer"
+	outputPrefixStyleFlag = "output.prefix-style"
+	tlsPinSHA256Flag      = "tls.pin-sha256"
+)
+
+// configOptions lists every option Config.set accepts, used to reject typos up front rather than silently
+// persisting an option nothing ever reads.
+var configOptions = map[string]bool{
+	applicationFlag:       true,
+	tenantFlag:            true,
+	instanceFlag:          true,
+	targetFlag:            true,
+	deployTargetFlag:      true,
+	zoneFlag:              true,
+	headersFlag:           true,
+	documentGroupFlag:     true,
+	requestSignerFlag:     true,
+	outputPrefixStyleFlag: true,
+	tlsPinSHA256Flag:      true,
+}
+
+// validConfigOptionsList describes configOptions for error messages, in the same order they're declared
+// above.
+const validConfigOptionsList = "application, tenant, instance, target, deploy-target, zone, headers, document.group, request-signer, output.prefix-style, tls.pin-sha256"
+
+// configOptionNames lists the same options as configOptions, but in a fixed order so `config get
+// --effective` prints them deterministically.
+var configOptionNames = []string{applicationFlag, tenantFlag, instanceFlag, targetFlag, deployTargetFlag, zoneFlag, headersFlag, documentGroupFlag, requestSignerFlag, outputPrefixStyleFlag, tlsPinSHA256Flag}
+
+// configOptionInfo describes a single option for `vespa config get --defaults` to list, kept in sync with
+// configOptions by TestConfigOptionInfoMatchesConfigOptions so the two can't drift apart.
+type configOptionInfo struct {
+	Name        string `json:"name"`
+	Default     string `json:"default"`
+	ValidValues string `json:"validValues,omitempty"` // empty if the option takes any string, e.g. a free-form name or URL
+	Description string `json:"description"`
+}
+
+// configOptionInfos describes every option configOptions accepts, in the same order as configOptionNames,
+// for `vespa config get --defaults` to print.
+var configOptionInfos = []configOptionInfo{
+	{applicationFlag, "default", "", "The application to use. May be given as tenant.application if --tenant is omitted"},
+	{tenantFlag, "default", "", "The tenant to use. Must match --application's tenant prefix, if it has one"},
+	{instanceFlag, "default", "", "The application instance(s) to use, comma-separated; * expands to every instance listed here"},
+	{targetFlag, "local", "local, cloud, or a URL", "The target to deploy to and query"},
+	{deployTargetFlag, "", "", "The config server/deploy target, overriding --target. Accepts a comma-separated list of URLs for a multi-config-server installation"},
+	{zoneFlag, "dev.default", "environment.region, e.g. prod.us-east-3", "The zone to deploy to or query, for the cloud target"},
+	{headersFlag, "", "", "Extra HTTP headers to send with every data-plane request, one name: value pair per line"},
+	{documentGroupFlag, "", "", "The default document group, substituted for a shorthand document ID missing a group, e.g. id:ns:type::123"},
+	{requestSignerFlag, "", "sigv4, or an external command to run per request", "How to sign data-plane requests; unset sends them unsigned"},
+	{outputPrefixStyleFlag, "colored", "colored, plain, or none", "How the Error:, Warning: and Success prefixes are rendered: colored, plain (no ANSI codes), or none (no prefix)"},
+	{tlsPinSHA256Flag, "", "one SHA-256 SPKI pin per line, base64 or hex", "Expected data-plane certificate public key pin(s); a mismatch aborts the request"},
+}
+
+// Option sources returned by Config.getWithSource, identifying which layer of the flag > local > global >
+// default precedence an option's effective value came from.
+const (
+	SourceFlag    = "flag"
+	SourceLocal   = "local"
+	SourceGlobal  = "global"
+	SourceDefault = "default"
+)
+
+// localConfigFile is the per-repository config, meant to be committed to git so a team shares settings like
+// application, while leaving per-developer values (e.g. instance: ${USER}) to expand from the environment.
+const localConfigFile = ".vespa/config.yaml"
+
+// globalConfigFile is the per-user config, stored under the CLI home directory.
+const globalConfigFile = "config.yaml"
+
+// Config resolves CLI options by precedence: an explicit flag first, then the local (per-repository)
+// config, then the global (per-user) config. Values may reference ${VAR}, expanded against the CLI's
+// environment; a reference to an undefined variable is left as-is rather than erroring, so a team can
+// commit a value like instance: ${USER} without every reader needing that variable set.
+type Config struct {
+	cli    *CLI
+	flags  map[string]string
+	local  map[string]string
+	global map[string]string
+}
+
+// newConfig loads the local and global config files and captures the CLI's current flag overrides.
+func newConfig(cli *CLI) (*Config, error) {
+	local, err := readConfigFile(localConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	global, err := readConfigFile(globalConfigPath(cli))
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		cli:    cli,
+		local:  local,
+		global: global,
+		flags: map[string]string{
+			applicationFlag:  cli.applicationOverride,
+			tenantFlag:       cli.tenantOverride,
+			instanceFlag:     cli.instanceOverride,
+			targetFlag:       cli.targetOverride,
+			deployTargetFlag: cli.deployTargetOverride,
+		},
+	}, nil
+}
+
+func globalConfigPath(cli *CLI) string {
+	return filepath.Join(profileDir(cli), globalConfigFile)
+}
+
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func writeConfigFile(path string, values map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// get resolves option's effective value, expanding any ${VAR} reference against the CLI's environment. It
+// returns the empty string if option is not set anywhere.
+func (c *Config) get(option string) string {
+	value, _ := c.getWithSource(option)
+	return value
+}
+
+// getWithSource is like get, but also reports which layer the effective value came from, so `vespa config
+// get --effective` can show users why an option resolved the way it did.
+func (c *Config) getWithSource(option string) (value, source string) {
+	layers := []struct {
+		values map[string]string
+		source string
+	}{
+		{c.flags, SourceFlag},
+		{c.local, SourceLocal},
+		{c.global, SourceGlobal},
+	}
+	for _, layer := range layers {
+		if v, ok := layer.values[option]; ok && v != "" {
+			return c.expand(v), layer.source
+		}
+	}
+	return "", SourceDefault
+}
+
+// instances returns every instance a multi-instance command like `vespa status --all-instances` or `vespa
+// deploy` should check: the explicit --instance override (itself allowed to be a comma-separated list,
+// e.g. --instance foo,bar), unless it's the wildcard "*", in which case every instance listed
+// (comma-separated) in the local or global instance config option is returned instead.
+func (c *Config) instances() []string {
+	if v := c.flags[instanceFlag]; v != "" && v != "*" {
+		return splitInstances(c.expand(v))
+	}
+	for _, values := range []map[string]string{c.local, c.global} {
+		if v, ok := values[instanceFlag]; ok && v != "" {
+			return splitInstances(c.expand(v))
+		}
+	}
+	return []string{"default"}
+}
+
+// application resolves the effective tenant and application name, so --tenant myco --application myapp and
+// --application myco.myapp are equivalent. The application option may optionally be given as
+// "tenant.application"; if both that prefix and the tenant option are set, they must agree. Either part
+// defaults to "default" if not set anywhere.
+func (c *Config) application() (tenant, application string, err error) {
+	app := c.get(applicationFlag)
+	if app == "" {
+		app = "default"
+	}
+	prefixTenant, application := splitApplicationTenant(app)
+	tenant = c.get(tenantFlag)
+	if tenant != "" && prefixTenant != "" && tenant != prefixTenant {
+		return "", "", errCLI(ErrCodeConfigInvalid, "--tenant %q does not match the tenant prefix %q of --application %q", tenant, prefixTenant, app)
+	}
+	if tenant == "" {
+		tenant = prefixTenant
+	}
+	if tenant == "" {
+		tenant = "default"
+	}
+	return tenant, application, nil
+}
+
+// splitApplicationTenant splits app on its first "." into a tenant prefix and the remaining application
+// name, e.g. "myco.myapp" becomes ("myco", "myapp"). It returns ("", app) unchanged if app has no prefix.
+func splitApplicationTenant(app string) (tenant, application string) {
+	if i := strings.Index(app, "."); i >= 0 {
+		return app[:i], app[i+1:]
+	}
+	return "", app
+}
+
+func splitInstances(raw string) []string {
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{"default"}
+	}
+	return names
+}
+
+func (c *Config) expand(value string) string {
+	return os.Expand(value, func(name string) string {
+		if v, ok := c.cli.env[name]; ok {
+			return v
+		}
+		return "${" + name + "}"
+	})
+}
+
+// set persists option's value in the global (per-user) config, recording the change in the audit log.
+func (c *Config) set(option, value string) error {
+	if !configOptions[option] {
+		return fmt.Errorf("invalid option %q: must be one of %s", option, validConfigOptionsList)
+	}
+	if option == zoneFlag {
+		if _, err := vespa.ZoneFromString(value); err != nil {
+			return err
+		}
+	}
+	old := c.global[option]
+	c.global[option] = value
+	if err := writeConfigFile(globalConfigPath(c.cli), c.global); err != nil {
+		return err
+	}
+	return c.appendAuditLog(option, old, value)
+}
+
+func newConfigCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage persistent values for global options",
+	}
+	cmd.AddCommand(newConfigSetCmd(cli))
+	cmd.AddCommand(newConfigGetCmd(cli))
+	cmd.AddCommand(newConfigProfileCmd(cli))
+	cmd.AddCommand(newConfigShowEnvCmd(cli))
+	cmd.AddCommand(newConfigMigrateCmd(cli))
+	cmd.AddCommand(newConfigAuditLogCmd(cli))
+	return cmd
+}
+
+// maxConfigValueBytes bounds a value read via @file or @- (stdin), so a fat-fingered redirect doesn't
+// silently wedge a multi-megabyte blob into config.yaml.
+const maxConfigValueBytes = 1 << 20 // 1 MiB
+
+func newConfigSetCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set option value",
+		Short: "Set a persistent value for an option",
+		Long: `Set a persistent value for an option.
+
+value can also be @file, to read the value verbatim from file (trailing newline trimmed), or @-, to read
+it from stdin. This is meant for options whose value is awkward to pass as a shell argument, e.g. a
+multi-line or JSON value.`,
+		Example: `$ vespa config set application mytenant.myapp
+$ vespa config set instance '${USER}'
+$ vespa config set target @endpoints.json
+$ vespa config set zone prod.us-east-3
+$ vespa config set request-signer sigv4
+$ echo '${USER}' | vespa config set instance @-`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			value, err := readConfigValueArg(cli, args[1])
+			if err != nil {
+				return err
+			}
+			config, err := newConfig(cli)
+			if err != nil {
+				return err
+			}
+			if err := config.set(args[0], value); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Set %s\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
+// readConfigValueArg resolves a config set value argument: @- reads stdin, @file reads a file, and
+// anything else is taken literally. File and stdin reads are size-limited and have one trailing newline
+// trimmed, since that's what an editor or echo almost always adds.
+func readConfigValueArg(cli *CLI, arg string) (string, error) {
+	if !strings.HasPrefix(arg, "@") {
+		return arg, nil
+	}
+	ref := arg[1:]
+	var r io.Reader
+	if ref == "-" {
+		r = cli.Stdin
+	} else {
+		f, err := os.Open(ref)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxConfigValueBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxConfigValueBytes {
+		return "", fmt.Errorf("value exceeds maximum size of %d bytes", maxConfigValueBytes)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func newConfigGetCmd(cli *CLI) *cobra.Command {
+	var (
+		raw       bool
+		effective bool
+		defaults  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "get [option]",
+		Short: "Print the effective value of an option",
+		Long: `Print the effective value of an option.
+
+A value set via @file or @- that spans multiple lines is summarized rather than printed inline; pass
+--raw to print it verbatim, e.g. for piping into another command.
+
+--effective additionally annotates the value with which layer it was resolved from: flag (an override like
+--application on the command line), local (.vespa/config.yaml), global (the per-user config), or default
+(not set anywhere). Given with no option argument, it prints every option this way instead of just one.
+
+--defaults lists every option this command accepts, with its default value, valid values and a one-line
+description, instead of the current config's values. Pass --format json (the global flag) for a JSON array
+instead of a table.`,
+		Example: `$ vespa config get application
+$ vespa config get application --effective
+$ vespa config get --effective
+$ vespa config get --defaults`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if (effective || defaults) && len(args) == 0 {
+				return nil
+			}
+			return cobra.ExactArgs(1)(nil, args)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if defaults {
+				return printConfigOptionDefaults(cli)
+			}
+			config, err := newConfig(cli)
+			if err != nil {
+				return err
+			}
+			if effective && len(args) == 0 {
+				for _, option := range configOptionNames {
+					printEffectiveOption(cli, config, option)
+				}
+				return nil
+			}
+			if !configOptions[args[0]] {
+				return fmt.Errorf("invalid option %q: must be one of %s", args[0], validConfigOptionsList)
+			}
+			if effective {
+				printEffectiveOption(cli, config, args[0])
+				return nil
+			}
+			value := config.get(args[0])
+			if raw || !strings.Contains(value, "\n") {
+				fmt.Fprintln(cli.Stdout, value)
+				return nil
+			}
+			fmt.Fprintf(cli.Stdout, "<multi-line value, %d bytes; use --raw to print>\n", len(value))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print a multi-line value verbatim instead of a summary")
+	cmd.Flags().BoolVar(&effective, "effective", false, "Annotate the value with the layer (flag/local/global/default) it was resolved from")
+	cmd.Flags().BoolVar(&defaults, "defaults", false, "List every option this command accepts, with its default, valid values and description, instead of the current config")
+	return cmd
+}
+
+// printConfigOptionDefaults prints configOptionInfos, one option per line, or as a JSON array with
+// --format json.
+func printConfigOptionDefaults(cli *CLI) error {
+	if cli.outputFormat == "json" {
+		return json.NewEncoder(cli.Stdout).Encode(configOptionInfos)
+	}
+	for _, info := range configOptionInfos {
+		validValues := info.ValidValues
+		if validValues == "" {
+			validValues = "any"
+		}
+		fmt.Fprintf(cli.Stdout, "%s\tdefault: %s\tvalid values: %s\t%s\n", info.Name, info.Default, validValues, info.Description)
+	}
+	return nil
+}
+
+// printEffectiveOption prints option's effective value and the layer it came from, summarizing a
+// multi-line value the same way a plain `config get` would.
+func printEffectiveOption(cli *CLI, config *Config, option string) {
+	value, source := config.getWithSource(option)
+	if strings.Contains(value, "\n") {
+		fmt.Fprintf(cli.Stdout, "%s=<multi-line value, %d bytes> (%s)\n", option, len(value), source)
+		return
+	}
+	fmt.Fprintf(cli.Stdout, "%s=%s (%s)\n", option, value, source)
+}
+
+func newConfigShowEnvCmd(cli *CLI) *cobra.Command {
+	var (
+		shell  bool
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "show-env",
+		Short: "Print the VESPA_CLI_* environment variables the CLI reads",
+		Long: `Print every VESPA_CLI_* environment variable the CLI reads, one per line, with its current
+value and whether a local or global config value references it via ${VAR} expansion. A secret value, e.g.
+VESPA_CLI_API_KEY, is masked to *** rather than printed verbatim.
+
+With --shell, instead print only the variables that currently have a non-empty value, one export statement
+per line, for a script to source, e.g. eval "$(vespa config show-env --shell)". --format selects the export
+syntax, defaulting to one native to the current platform; a secret value is still masked.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if !shell {
+				return showEnv(cli)
+			}
+			exportFormat := vespa.DefaultEnvExportFormat()
+			if format != "" {
+				parsed, err := vespa.ParseEnvExportFormat(format)
+				if err != nil {
+					return err
+				}
+				exportFormat = parsed
+			}
+			return showEnvShell(cli, exportFormat)
+		},
+	}
+	cmd.Flags().BoolVar(&shell, "shell", false, "Print shell export statements for variables with a non-empty value, instead of a human-readable listing")
+	cmd.Flags().StringVar(&format, "format", "", "Export syntax to use with --shell: sh, cmd or powershell (default: native to the current platform)")
+	return cmd
+}
+
+func showEnv(cli *CLI) error {
+	local, err := readConfigFile(localConfigFile)
+	if err != nil {
+		return err
+	}
+	global, err := readConfigFile(globalConfigPath(cli))
+	if err != nil {
+		return err
+	}
+	for _, v := range knownEnvVars {
+		overrides := "no"
+		if referencesEnvVar(local, v.name) || referencesEnvVar(global, v.name) {
+			overrides = "yes"
+		}
+		fmt.Fprintf(cli.Stdout, "%s=%s (overrides config: %s)\n", v.name, maskedEnvValue(v, cli.env[v.name]), overrides)
+	}
+	return nil
+}
+
+// showEnvShell prints one export statement per knownEnvVars entry with a non-empty value, in format, for a
+// script to source.
+func showEnvShell(cli *CLI, format vespa.EnvExportFormat) error {
+	for _, v := range knownEnvVars {
+		value := cli.env[v.name]
+		if value == "" {
+			continue
+		}
+		fmt.Fprintln(cli.Stdout, vespa.FormatEnvExport(format, v.name, maskedEnvValue(v, value)))
+	}
+	return nil
+}
+
+// referencesEnvVar reports whether any value in values contains a ${name} reference, meaning Config.expand
+// would substitute the named environment variable into it.
+func referencesEnvVar(values map[string]string, name string) bool {
+	placeholder := "${" + name + "}"
+	for _, v := range values {
+		if strings.Contains(v, placeholder) {
+			return true
+		}
+	}
+	return false
+}
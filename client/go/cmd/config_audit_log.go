@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// auditLogFile is the append-only record of config changes, written at the top of the CLI home directory
+// next to profiles/, shared by every profile.
+const auditLogFile = "audit.log"
+
+func auditLogPath(cli *CLI) string {
+	return filepath.Join(homeDir(cli), auditLogFile)
+}
+
+// auditLogEntry is a single line of audit.log: a record of one config option changing.
+type auditLogEntry struct {
+	Time string `json:"time"`
+	User string `json:"user"`
+	Key  string `json:"key"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// appendAuditLog appends a record of key changing from old to new to audit.log, identifying the user from
+// the USER environment variable. A failure to write it is returned as an error rather than silently
+// dropped, since a gap in the trail would defeat its purpose.
+func (c *Config) appendAuditLog(key, old, new string) error {
+	entry := auditLogEntry{
+		Time: time.Now().UTC().Format(time.RFC3339Nano),
+		User: c.cli.env["USER"],
+		Key:  key,
+		Old:  old,
+		New:  new,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := auditLogPath(c.cli)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// readAuditLog reads every entry from audit.log, oldest first. A file that doesn't exist yet is treated as
+// empty, the common case of no config value ever having been set.
+func readAuditLog(cli *CLI) ([]auditLogEntry, error) {
+	data, err := os.ReadFile(auditLogPath(cli))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []auditLogEntry
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry auditLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", auditLogPath(cli), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newConfigAuditLogCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-log",
+		Short: "Inspect the config change audit log",
+	}
+	cmd.AddCommand(newConfigAuditLogShowCmd(cli))
+	return cmd
+}
+
+func newConfigAuditLogShowCmd(cli *CLI) *cobra.Command {
+	var last int
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print recent entries from the config change audit log",
+		Long: `Print recent entries from the config change audit log, one JSON object per line, oldest first. Every
+` + "`vespa config set`" + ` appends an entry recording the option changed, its previous and new value, when, and
+which user (from the USER environment variable) made the change.
+
+--last bounds how many of the most recent entries are printed; by default, every entry is printed.`,
+		Example: `$ vespa config audit-log show
+$ vespa config audit-log show --last 10`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			entries, err := readAuditLog(cli)
+			if err != nil {
+				return err
+			}
+			if last > 0 && last < len(entries) {
+				entries = entries[len(entries)-last:]
+			}
+			for _, entry := range entries {
+				data, err := json.Marshal(entry)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cli.Stdout, string(data))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&last, "last", 0, "Only print this many of the most recent entries (default: all)")
+	return cmd
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSetAppendsAuditLogEntry(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["USER"] = "alice"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.Nil(t, config.set(instanceFlag, "from-nothing"))
+	assert.Nil(t, config.set(instanceFlag, "from-something"))
+
+	entries, err := readAuditLog(cli)
+	assert.Nil(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "alice", entries[0].User)
+		assert.Equal(t, instanceFlag, entries[0].Key)
+		assert.Equal(t, "", entries[0].Old)
+		assert.Equal(t, "from-nothing", entries[0].New)
+		assert.Equal(t, "from-nothing", entries[1].Old)
+		assert.Equal(t, "from-something", entries[1].New)
+		assert.NotEmpty(t, entries[0].Time)
+	}
+}
+
+func TestConfigAuditLogShowPrintsEveryEntry(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(instanceFlag, "one"))
+	assert.Nil(t, config.set(instanceFlag, "two"))
+
+	err = cli.Run([]string{"config", "audit-log", "show"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"new":"one"`)
+	assert.Contains(t, stdout.String(), `"new":"two"`)
+}
+
+func TestConfigAuditLogShowLastLimitsToMostRecentEntries(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(instanceFlag, "one"))
+	assert.Nil(t, config.set(instanceFlag, "two"))
+	assert.Nil(t, config.set(instanceFlag, "three"))
+
+	err = cli.Run([]string{"config", "audit-log", "show", "--last", "1"})
+
+	assert.Nil(t, err)
+	assert.NotContains(t, stdout.String(), `"new":"one"`)
+	assert.NotContains(t, stdout.String(), `"new":"two"`)
+	assert.Contains(t, stdout.String(), `"new":"three"`)
+}
+
+func TestConfigAuditLogShowWithoutAnyChangesPrintsNothing(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "audit-log", "show"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", stdout.String())
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// migrationMarkerFile is a homeDir-relative file recording that automatic migration has already run once
+// for this home directory, so every command invocation doesn't need to re-scan for a legacy layout.
+const migrationMarkerFile = "migrated"
+
+// Pre-profile layout: config.yaml, auth.json and the data-plane key pair lived directly under the CLI home
+// directory. `vespa config profile` moved them under profiles/<name>/ so each profile could have its own,
+// and auth_cert.go renamed the key pair's files along the way.
+const (
+	legacyConfigFile = "config.yaml"
+	legacyAuthFile   = "auth.json"
+	legacyKeyFile    = "privatekey.pem"
+	legacyCertFile   = "cert.pem"
+)
+
+// deprecatedConfigKeys maps a config.yaml key no longer read by Config.get to the option name that replaced
+// it, so a migration rewrites an old key into today's name instead of silently dropping its value.
+var deprecatedConfigKeys = map[string]string{
+	"endpoint": targetFlag,
+	"app":      applicationFlag,
+}
+
+// migrationReport describes every change migrateConfig made (or, with dryRun, would make), in the order
+// they were considered, for `vespa config migrate` to print back to the user.
+type migrationReport struct {
+	changes []string
+	dryRun  bool
+}
+
+func (r *migrationReport) add(format string, args ...interface{}) {
+	r.changes = append(r.changes, fmt.Sprintf(format, args...))
+}
+
+func newConfigMigrateCmd(cli *CLI) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate an older config layout to the current one",
+		Long: `Migrate an older config layout to the current one.
+
+Older CLI versions stored config.yaml, auth.json and the data-plane key pair directly under the CLI home
+directory, and used a handful of config.yaml keys that have since been renamed. This moves those files to
+their current per-profile locations, renames deprecated keys, and normalizes profile directory names left
+with leading/trailing whitespace or inconsistent case. A file that can't be parsed is backed up alongside
+the original with a .bak suffix and left for inspection rather than deleted.
+
+This also runs automatically, once per home directory, the first time any command needs it; running it
+explicitly is mainly useful for previewing changes with --dry-run or re-checking after an interrupted run.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			report, err := migrateConfig(cli, dryRun)
+			if err != nil {
+				return err
+			}
+			if len(report.changes) == 0 {
+				fmt.Fprintln(cli.Stdout, "Nothing to migrate")
+				return nil
+			}
+			if dryRun {
+				fmt.Fprintln(cli.Stdout, "Would make the following changes:")
+			} else {
+				fmt.Fprintln(cli.Stdout, "Made the following changes:")
+			}
+			for _, change := range report.changes {
+				fmt.Fprintf(cli.Stdout, "  %s\n", change)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without writing anything")
+	return cmd
+}
+
+// autoMigrateConfig runs migrateConfig at most once per home directory, ignoring any error: it is called on
+// every command invocation, so it must never turn an otherwise-working command into a failure over a legacy
+// layout problem the user hasn't asked to deal with yet. `vespa config migrate` surfaces that error instead.
+// It skips `vespa config migrate` itself, so that command's own report isn't always empty the first time a
+// user thinks to run it.
+func autoMigrateConfig(cli *CLI, cmd *cobra.Command) {
+	if cmd.Name() == "migrate" {
+		return
+	}
+	marker := filepath.Join(homeDir(cli), migrationMarkerFile)
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+	_, _ = migrateConfig(cli, false)
+}
+
+// migrateConfig moves every legacy file it finds directly under the CLI home directory to its current
+// per-profile location, renames deprecated config.yaml keys, and normalizes profile directory names. With
+// dryRun it reports what it would do without writing anything, including the marker file, so running it
+// again afterward behaves the same way. Without dryRun it always writes the marker file on success, even if
+// there was nothing to migrate, so autoMigrateConfig doesn't re-scan on every future invocation.
+func migrateConfig(cli *CLI, dryRun bool) (*migrationReport, error) {
+	report := &migrationReport{dryRun: dryRun}
+	home := homeDir(cli)
+	profile := profileDir(cli)
+
+	if err := migrateLegacyConfigFile(home, profile, report); err != nil {
+		return nil, err
+	}
+	if err := migrateLegacyAuthFile(home, profile, report); err != nil {
+		return nil, err
+	}
+	if err := migrateLegacyCertFiles(cli, home, profile, report); err != nil {
+		return nil, err
+	}
+	if err := normalizeProfileNames(home, report); err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		if err := os.MkdirAll(home, 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(home, migrationMarkerFile), []byte("1\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// migrateLegacyConfigFile merges home/config.yaml into profile/config.yaml, renaming any deprecated key
+// along the way, then removes the legacy file. A key already set in the current file takes precedence, so
+// migration never clobbers a value set by a newer CLI version the user has already been using. A config.yaml
+// that fails to parse is backed up and left in place rather than touched further.
+func migrateLegacyConfigFile(home, profile string, report *migrationReport) error {
+	legacyPath := filepath.Join(home, legacyConfigFile)
+	legacy, err := readLegacyYAML(legacyPath)
+	if err != nil {
+		report.add("could not parse %s, backed up and left in place: %v", legacyPath, err)
+		return backupCorruptFile(legacyPath, report)
+	}
+	if legacy == nil {
+		return nil
+	}
+	normalizedLegacy := normalizeDeprecatedKeys(legacy, report)
+	current, err := readConfigFile(filepath.Join(profile, globalConfigFile))
+	if err != nil {
+		return err
+	}
+	changed := false
+	for key, value := range normalizedLegacy {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		current[key] = value
+		changed = true
+	}
+	if changed {
+		report.add("merged %s into %s", legacyPath, filepath.Join(profile, globalConfigFile))
+		if !report.dryRun {
+			if err := writeConfigFile(filepath.Join(profile, globalConfigFile), current); err != nil {
+				return err
+			}
+		}
+	}
+	report.add("removed legacy config file %s", legacyPath)
+	if !report.dryRun {
+		if err := os.Remove(legacyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeDeprecatedKeys rewrites every deprecated key in legacy to the option name that replaced it,
+// reporting each rename. If the legacy file sets both a deprecated key and the modern key it would rename
+// to (e.g. both "endpoint" and "target"), the modern key's value wins, since it's the one a newer CLI run
+// against this same file would actually have read.
+func normalizeDeprecatedKeys(legacy map[string]string, report *migrationReport) map[string]string {
+	normalized := make(map[string]string, len(legacy))
+	for key, value := range legacy {
+		if _, deprecated := deprecatedConfigKeys[key]; !deprecated {
+			normalized[key] = value
+		}
+	}
+	for key, value := range legacy {
+		renamed, deprecated := deprecatedConfigKeys[key]
+		if !deprecated {
+			continue
+		}
+		if _, ok := normalized[renamed]; ok {
+			continue
+		}
+		report.add("renamed deprecated config key %q to %q", key, renamed)
+		normalized[renamed] = value
+	}
+	return normalized
+}
+
+// readLegacyYAML reads and parses path as a string-keyed YAML map, the shape config.yaml has always had.
+// It returns (nil, nil) if path does not exist, so callers can tell "nothing to migrate" apart from an empty
+// file.
+func readLegacyYAML(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// migrateLegacyAuthFile moves home/auth.json to profile/auth.json, unless a current one already exists, in
+// which case the legacy file is left untouched rather than risking the loss of either one's credentials. A
+// auth.json that fails to parse is backed up and left in place.
+func migrateLegacyAuthFile(home, profile string, report *migrationReport) error {
+	legacyPath := filepath.Join(home, legacyAuthFile)
+	data, err := os.ReadFile(legacyPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var cfg authConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		report.add("could not parse %s, backed up and left in place: %v", legacyPath, err)
+		return backupCorruptFile(legacyPath, report)
+	}
+	currentPath := filepath.Join(profile, "auth.json")
+	if fileExists(currentPath) {
+		report.add("skipped %s: %s already exists", legacyPath, currentPath)
+		return nil
+	}
+	report.add("moved %s to %s", legacyPath, currentPath)
+	if report.dryRun {
+		return nil
+	}
+	return moveFile(legacyPath, currentPath, 0600)
+}
+
+// migrateLegacyCertFiles moves the pre-rename data-plane key pair (home/privatekey.pem, home/cert.pem) to
+// their current per-profile names, unless a current key pair already exists. A file that isn't valid
+// PEM is backed up and left in place instead of being moved to a name auth_cert.go would then fail to read.
+func migrateLegacyCertFiles(cli *CLI, home, profile string, report *migrationReport) error {
+	moves := []struct {
+		legacyName  string
+		currentPath string
+	}{
+		{legacyKeyFile, dataPlaneKeyPath(cli)},
+		{legacyCertFile, dataPlaneCertPath(cli)},
+	}
+	for _, m := range moves {
+		legacyPath := filepath.Join(home, m.legacyName)
+		data, err := os.ReadFile(legacyPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if block, _ := pem.Decode(data); block == nil {
+			report.add("could not parse %s as PEM, backed up and left in place", legacyPath)
+			if err := backupCorruptFile(legacyPath, report); err != nil {
+				return err
+			}
+			continue
+		}
+		if fileExists(m.currentPath) {
+			report.add("skipped %s: %s already exists", legacyPath, m.currentPath)
+			continue
+		}
+		report.add("moved %s to %s", legacyPath, m.currentPath)
+		if report.dryRun {
+			continue
+		}
+		if err := moveFile(legacyPath, m.currentPath, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeProfileNames renames a profile directory under home/profiles whose name has leading/trailing
+// whitespace or upper-case characters to its trimmed, lower-cased form, the convention `vespa config profile
+// create` has always enforced for new profiles. A rename that would collide with an existing profile is
+// skipped and reported rather than overwriting it.
+func normalizeProfileNames(home string, report *migrationReport) error {
+	entries, err := os.ReadDir(filepath.Join(home, profilesDirName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		existing[entry.Name()] = true
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		normalized := strings.ToLower(strings.TrimSpace(name))
+		if normalized == name {
+			continue
+		}
+		oldPath := filepath.Join(home, profilesDirName, name)
+		newPath := filepath.Join(home, profilesDirName, normalized)
+		if existing[normalized] {
+			report.add("skipped renaming profile %q to %q: already exists", name, normalized)
+			continue
+		}
+		report.add("renamed profile %q to %q", name, normalized)
+		if report.dryRun {
+			continue
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return err
+		}
+		existing[normalized] = true
+		delete(existing, name)
+	}
+	return nil
+}
+
+// backupCorruptFile renames path to path+".bak", so a file migrateConfig can't safely interpret is
+// preserved for manual inspection instead of being silently skipped in place (where it would keep shadowing
+// whatever replaces it) or deleted.
+func backupCorruptFile(path string, report *migrationReport) error {
+	if report.dryRun {
+		return nil
+	}
+	return os.Rename(path, path+".bak")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// moveFile renames path to dest, falling back to copying it to dest with the given permissions and removing
+// path when a plain rename fails (e.g. dest is on a different filesystem, as can happen with a symlinked CLI
+// home).
+func moveFile(path, dest string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(path, dest); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dest, data, perm); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
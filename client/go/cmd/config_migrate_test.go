@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakePEM(blockType string) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: []byte("fake")})
+}
+
+func writeLegacyLayout(t *testing.T, home string) {
+	t.Helper()
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyConfigFile), []byte("endpoint: http://old:8080\napp: mytenant.myapp\ntarget: http://new:8080\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyAuthFile), []byte(`{"systems":{"public":{"refreshToken":"abc"}}}`), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyKeyFile), fakePEM("EC PRIVATE KEY"), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyCertFile), fakePEM("CERTIFICATE"), 0644))
+}
+
+func TestMigrateConfigMovesFilesAndRenamesDeprecatedKeys(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+
+	report, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, report.changes)
+
+	// target, set directly in the legacy file, is left alone: it already has the current key name.
+	values, err := readConfigFile(filepath.Join(profileDir(cli), globalConfigFile))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://new:8080", values[targetFlag])
+	assert.Equal(t, "mytenant.myapp", values[applicationFlag])
+	assert.NotContains(t, values, "endpoint")
+	assert.NotContains(t, values, "app")
+
+	assert.NoFileExists(t, filepath.Join(home, legacyConfigFile))
+	assert.NoFileExists(t, filepath.Join(home, legacyAuthFile))
+	assert.NoFileExists(t, filepath.Join(home, legacyKeyFile))
+	assert.NoFileExists(t, filepath.Join(home, legacyCertFile))
+
+	assert.FileExists(t, filepath.Join(profileDir(cli), "auth.json"))
+	assert.FileExists(t, dataPlaneKeyPath(cli))
+	assert.FileExists(t, dataPlaneCertPath(cli))
+	assert.FileExists(t, filepath.Join(home, migrationMarkerFile))
+}
+
+func TestMigrateConfigDoesNotOverwriteExistingCurrentFiles(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+	assert.Nil(t, os.MkdirAll(profileDir(cli), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(profileDir(cli), "auth.json"), []byte(`{"systems":{}}`), 0600))
+
+	report, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+
+	// The legacy auth.json is left in place rather than overwriting the one already at the current path.
+	assert.FileExists(t, filepath.Join(home, legacyAuthFile))
+	found := false
+	for _, change := range report.changes {
+		if change == "skipped "+filepath.Join(home, legacyAuthFile)+": "+filepath.Join(profileDir(cli), "auth.json")+" already exists" {
+			found = true
+		}
+	}
+	assert.True(t, found, "expected a skip entry for auth.json, got %v", report.changes)
+}
+
+func TestMigrateConfigBacksUpUnparseableFiles(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyConfigFile), []byte("not: [valid: yaml"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyAuthFile), []byte("not json"), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyKeyFile), []byte("not pem"), 0600))
+
+	_, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+
+	assert.FileExists(t, filepath.Join(home, legacyConfigFile+".bak"))
+	assert.FileExists(t, filepath.Join(home, legacyAuthFile+".bak"))
+	assert.FileExists(t, filepath.Join(home, legacyKeyFile+".bak"))
+	assert.NoFileExists(t, filepath.Join(home, legacyConfigFile))
+	assert.NoFileExists(t, filepath.Join(home, legacyAuthFile))
+	assert.NoFileExists(t, filepath.Join(home, legacyKeyFile))
+}
+
+func TestMigrateConfigDryRunChangesNothing(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+
+	report, err := migrateConfig(cli, true)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, report.changes)
+
+	assert.FileExists(t, filepath.Join(home, legacyConfigFile))
+	assert.FileExists(t, filepath.Join(home, legacyAuthFile))
+	assert.FileExists(t, filepath.Join(home, legacyKeyFile))
+	assert.FileExists(t, filepath.Join(home, legacyCertFile))
+	assert.NoFileExists(t, filepath.Join(home, migrationMarkerFile))
+}
+
+func TestMigrateConfigIsIdempotent(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+
+	_, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+
+	report, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+	assert.Empty(t, report.changes)
+}
+
+func TestMigrateConfigNormalizesProfileNames(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	assert.Nil(t, os.MkdirAll(filepath.Join(home, profilesDirName, " CloudProd "), 0755))
+
+	report, err := migrateConfig(cli, false)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, report.changes)
+
+	assert.DirExists(t, filepath.Join(home, profilesDirName, "cloudprod"))
+	assert.NoDirExists(t, filepath.Join(home, profilesDirName, " CloudProd "))
+}
+
+func TestAutoMigrateConfigRunsOnlyOnce(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+	statusCmd := &cobra.Command{Use: "status"}
+
+	autoMigrateConfig(cli, statusCmd)
+	assert.NoFileExists(t, filepath.Join(home, legacyConfigFile))
+	assert.FileExists(t, filepath.Join(home, migrationMarkerFile))
+
+	// A legacy file reappearing after the marker was written (e.g. restored from a backup) is left alone:
+	// auto-migration only ever scans once per home directory.
+	assert.Nil(t, os.WriteFile(filepath.Join(home, legacyConfigFile), []byte("target: http://again:8080\n"), 0644))
+	autoMigrateConfig(cli, statusCmd)
+	assert.FileExists(t, filepath.Join(home, legacyConfigFile))
+}
+
+func TestAutoMigrateConfigSkipsMigrateCommandItself(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+	migrateCmd := &cobra.Command{Use: "migrate"}
+
+	autoMigrateConfig(cli, migrateCmd)
+
+	assert.FileExists(t, filepath.Join(home, legacyConfigFile))
+	assert.NoFileExists(t, filepath.Join(home, migrationMarkerFile))
+}
+
+func TestConfigMigrateCommandPrintsReport(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+
+	assert.Nil(t, cli.Run([]string{"config", "migrate"}))
+
+	assert.Contains(t, stdout.String(), "Made the following changes:")
+}
+
+func TestConfigMigrateCommandDryRun(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	home := t.TempDir()
+	cli.env[authHomeEnv] = home
+	writeLegacyLayout(t, home)
+
+	assert.Nil(t, cli.Run([]string{"config", "migrate", "--dry-run"}))
+
+	assert.Contains(t, stdout.String(), "Would make the following changes:")
+	assert.FileExists(t, filepath.Join(home, legacyConfigFile))
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetExpandsEnvVars(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["USER"] = "alice"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(instanceFlag, "${USER}"))
+
+	config, err = newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "alice", config.get(instanceFlag))
+}
+
+func TestConfigGetLeavesUnknownVarsAsIs(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(applicationFlag, "team.${UNDEFINED_VAR}"))
+
+	config, err = newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "team.${UNDEFINED_VAR}", config.get(applicationFlag))
+}
+
+func TestConfigFlagOverridesLocalAndGlobal(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(localConfigFile), 0755))
+	assert.Nil(t, writeConfigFile(localConfigFile, map[string]string{instanceFlag: "from-local"}))
+
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, writeConfigFile(globalConfigPath(cli), map[string]string{instanceFlag: "from-global"}))
+	cli.instanceOverride = "from-flag"
+
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "from-flag", config.get(instanceFlag))
+}
+
+func TestConfigLocalOverridesGlobal(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(localConfigFile), 0755))
+	assert.Nil(t, writeConfigFile(localConfigFile, map[string]string{instanceFlag: "from-local"}))
+
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, writeConfigFile(globalConfigPath(cli), map[string]string{instanceFlag: "from-global"}))
+
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "from-local", config.get(instanceFlag))
+}
+
+func TestConfigSetRejectsUnknownOption(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, config.set("bogus", "x"))
+}
+
+func TestConfigSetAcceptsValidZone(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(zoneFlag, "prod.us-east-3"))
+
+	config, err = newConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, "prod.us-east-3", config.get(zoneFlag))
+}
+
+func TestConfigSetRejectsMalformedZone(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	err = config.set(zoneFlag, "us-east-3")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "env.region")
+	}
+}
+
+func TestConfigSetReadsValueFromFile(t *testing.T) {
+	jsonValue := `{"endpoints": [{"url": "https://example.com"}]}`
+	file := filepath.Join(t.TempDir(), "endpoints.json")
+	assert.Nil(t, os.WriteFile(file, []byte(jsonValue+"\n"), 0644))
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "set", "target", "@" + file})
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Set target")
+
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, jsonValue, config.get(targetFlag))
+}
+
+func TestConfigSetReadsValueFromStdin(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.Stdin = strings.NewReader("from-stdin\n")
+
+	err := cli.Run([]string{"config", "set", "instance", "@-"})
+	assert.Nil(t, err)
+
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, "from-stdin", config.get(instanceFlag))
+}
+
+func TestConfigSetRejectsOversizedValue(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "big.txt")
+	assert.Nil(t, os.WriteFile(file, make([]byte, maxConfigValueBytes+1), 0644))
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "set", "target", "@" + file})
+
+	assert.NotNil(t, err)
+}
+
+func TestConfigGetRawPrintsMultiLineValueVerbatim(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.Stdin = strings.NewReader("line one\nline two\n")
+	assert.Nil(t, cli.Run([]string{"config", "set", "target", "@-"}))
+	stdout.Reset()
+
+	assert.Nil(t, cli.Run([]string{"config", "get", "target"}))
+	assert.Contains(t, stdout.String(), "multi-line value")
+
+	stdout.Reset()
+	assert.Nil(t, cli.Run([]string{"config", "get", "target", "--raw"}))
+	assert.Equal(t, "line one\nline two\n", stdout.String())
+}
+
+func TestConfigShowEnvMasksSecretValue(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[apiKeyEnv] = "super-secret-key"
+
+	err := cli.Run([]string{"config", "show-env"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), apiKeyEnv+"=***")
+	assert.NotContains(t, stdout.String(), "super-secret-key")
+}
+
+func TestConfigShowEnvPrintsNonSecretValueVerbatim(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[cloudSystemEnv] = "public"
+
+	err := cli.Run([]string{"config", "show-env"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), cloudSystemEnv+"=public")
+}
+
+func TestConfigShowEnvReportsConfigOverride(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(targetFlag, "${"+cloudSystemEnv+"}.example.com"))
+
+	err = cli.Run([]string{"config", "show-env"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), cloudSystemEnv+"= (overrides config: yes)")
+}
+
+func TestConfigShowEnvDefaultsUnsetVarsToEmpty(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "show-env"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), apiKeyEnv+"= (overrides config: no)")
+}
+
+func TestConfigShowEnvShellPrintsOnlySetVarsAsExports(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[cloudSystemEnv] = "public"
+
+	err := cli.Run([]string{"config", "show-env", "--shell", "--format", "sh"})
+
+	assert.Nil(t, err)
+	// authHomeEnv is set above too, just to give the CLI an isolated home dir, so it legitimately gets its
+	// own export line here as well; this only checks that a var the test never set (apiKeyEnv) doesn't.
+	assert.Contains(t, stdout.String(), "export "+cloudSystemEnv+"='public'\n")
+	assert.NotContains(t, stdout.String(), apiKeyEnv)
+}
+
+func TestConfigShowEnvShellMasksSecretValue(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[apiKeyEnv] = "super-secret-key"
+
+	err := cli.Run([]string{"config", "show-env", "--shell", "--format", "sh"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), apiKeyEnv+"='***'")
+	assert.NotContains(t, stdout.String(), "super-secret-key")
+}
+
+func TestConfigShowEnvShellRejectsInvalidFormat(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "show-env", "--shell", "--format", "bogus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid format")
+	}
+}
+
+func TestConfigGetWithSourceAttributesFlag(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.instanceOverride = "from-flag"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	value, source := config.getWithSource(instanceFlag)
+
+	assert.Equal(t, "from-flag", value)
+	assert.Equal(t, SourceFlag, source)
+}
+
+func TestConfigGetWithSourceAttributesLocal(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(localConfigFile), 0755))
+	assert.Nil(t, writeConfigFile(localConfigFile, map[string]string{instanceFlag: "from-local"}))
+
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	value, source := config.getWithSource(instanceFlag)
+
+	assert.Equal(t, "from-local", value)
+	assert.Equal(t, SourceLocal, source)
+}
+
+func TestConfigGetWithSourceAttributesGlobal(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(instanceFlag, "from-global"))
+
+	config, err = newConfig(cli)
+	assert.Nil(t, err)
+	value, source := config.getWithSource(instanceFlag)
+
+	assert.Equal(t, "from-global", value)
+	assert.Equal(t, SourceGlobal, source)
+}
+
+func TestConfigGetWithSourceAttributesDefault(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	value, source := config.getWithSource(instanceFlag)
+
+	assert.Equal(t, "", value)
+	assert.Equal(t, SourceDefault, source)
+}
+
+func TestConfigGetEffectiveAnnotatesSource(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.applicationOverride = "mytenant.myapp"
+
+	err := cli.Run([]string{"config", "get", "application", "--effective"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "application=mytenant.myapp (flag)\n", stdout.String())
+}
+
+func TestConfigGetEffectiveWithoutOptionListsAll(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.applicationOverride = "mytenant.myapp"
+
+	err := cli.Run([]string{"config", "get", "--effective"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "application=mytenant.myapp (flag)")
+	assert.Contains(t, stdout.String(), "instance= (default)")
+}
+
+func TestConfigOptionInfoMatchesConfigOptions(t *testing.T) {
+	assert.Equal(t, len(configOptions), len(configOptionInfos))
+	for _, info := range configOptionInfos {
+		assert.True(t, configOptions[info.Name], "%s is listed in configOptionInfos but not configOptions", info.Name)
+	}
+	for option := range configOptions {
+		found := false
+		for _, info := range configOptionInfos {
+			if info.Name == option {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, "%s is accepted by Config.set but missing from configOptionInfos", option)
+	}
+}
+
+func TestConfigGetDefaultsListsEveryOption(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"config", "get", "--defaults"})
+
+	assert.Nil(t, err)
+	for option := range configOptions {
+		assert.Contains(t, stdout.String(), option+"\t")
+	}
+}
+
+func TestConfigGetDefaultsJSON(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"--format", "json", "config", "get", "--defaults"})
+
+	assert.Nil(t, err)
+	var infos []configOptionInfo
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &infos))
+	assert.Equal(t, len(configOptions), len(infos))
+}
+
+func TestConfigApplicationSplitsDottedApplication(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.applicationOverride = "mytenant.myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	tenant, application, err := config.application()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant", tenant)
+	assert.Equal(t, "myapp", application)
+}
+
+func TestConfigApplicationCombinesTenantAndApplicationFlags(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.tenantOverride = "mytenant"
+	cli.applicationOverride = "myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	tenant, application, err := config.application()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant", tenant)
+	assert.Equal(t, "myapp", application)
+}
+
+func TestConfigApplicationAcceptsAgreeingTenantAndPrefix(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.tenantOverride = "mytenant"
+	cli.applicationOverride = "mytenant.myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	tenant, application, err := config.application()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant", tenant)
+	assert.Equal(t, "myapp", application)
+}
+
+func TestConfigApplicationRejectsMismatchedTenantAndPrefix(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.tenantOverride = "othertenant"
+	cli.applicationOverride = "mytenant.myapp"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	_, _, err = config.application()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrCodeConfigInvalid, codeOf(err))
+}
+
+func TestConfigApplicationDefaultsTenantAndApplication(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+
+	tenant, application, err := config.application()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default", tenant)
+	assert.Equal(t, "default", application)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// curlErrorBodyLimit caps how much of a failed request's response body is quoted in the error, enough to
+// show a JSON error message without dumping an entire HTML error page into the terminal.
+const curlErrorBodyLimit = 1024
+
+func newCurlCmd(cli *CLI) *cobra.Command {
+	var (
+		method     string
+		methodLong string
+		data       string
+		dataFile   string
+		output     string
+		verbose    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "curl path",
+		Short: "Issue a raw HTTP request against a Vespa endpoint",
+		Long: `Issue a raw HTTP request against a Vespa endpoint, curl-style, for debugging an API query,
+document and other data-plane commands don't cover.
+
+Target resolution, the client certificate and any --header set apply here exactly as they do for query and
+document commands.
+
+Use --data-file instead of --data to upload a body too large to comfortably pass on the command line, or one
+that needs to round-trip through a file unmodified (--data "$(cat f)" can corrupt line endings on some
+shells). A .json file sets the request's Content-Type automatically.
+
+Use --output to stream the response body straight to a file instead of stdout, showing progress while the
+response is read if the server reports a Content-Length.`,
+		Example: `$ vespa curl /ApplicationStatus
+$ vespa curl -X POST --data '{"foo": "bar"}' /some/path
+$ vespa curl --method POST --data-file body.json /some/path
+$ vespa curl --output response.json /some/path
+$ vespa curl -v /ApplicationStatus`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if data != "" && dataFile != "" {
+				return fmt.Errorf("--data and --data-file are mutually exclusive")
+			}
+			if methodLong != "" {
+				method = methodLong
+			}
+			service, err := containerService(cli, "")
+			if err != nil {
+				return err
+			}
+			return runCurl(cli, service, method, args[0], data, dataFile, output, verbose)
+		},
+	}
+	cmd.Flags().StringVarP(&method, "request", "X", http.MethodGet, "HTTP method to use")
+	cmd.Flags().StringVar(&methodLong, "method", "", "HTTP method to use (overrides --request if both are given)")
+	cmd.Flags().StringVarP(&data, "data", "d", "", "Request body")
+	cmd.Flags().StringVar(&dataFile, "data-file", "", "Path to a file whose content is sent as the request body")
+	cmd.Flags().StringVar(&output, "output", "", "Write the response body to this file instead of stdout")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print the request line and headers before sending, and the response status")
+	return cmd
+}
+
+// runCurl issues a single HTTP request through service, printing request and response details to cli.Stderr
+// if verbose, and the response body to cli.Stdout, or to outputPath if given. A header whose name contains
+// "auth" or "token", case-insensitively, has its value redacted in verbose output, since it's commonly a
+// credential.
+func runCurl(cli *CLI, service *vespa.Service, method, path, data, dataFile, outputPath string, verbose bool) error {
+	var body io.Reader
+	var contentType string
+	switch {
+	case dataFile != "":
+		data, err := os.ReadFile(dataFile)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		if strings.EqualFold(filepath.Ext(dataFile), ".json") {
+			contentType = "application/json"
+		}
+	case data != "":
+		body = strings.NewReader(data)
+	}
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if verbose {
+		fmt.Fprintf(cli.Stderr, "> %s %s\n", method, path)
+		for name, values := range req.Header {
+			for _, v := range values {
+				fmt.Fprintf(cli.Stderr, "> %s: %s\n", name, vespa.RedactHeaderValue(name, v))
+			}
+		}
+		for name, values := range service.Headers {
+			for _, v := range values {
+				fmt.Fprintf(cli.Stderr, "> %s: %s\n", name, vespa.RedactHeaderValue(name, v))
+			}
+		}
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if verbose {
+		fmt.Fprintf(cli.Stderr, "< HTTP %d\n", resp.StatusCode)
+	}
+	if resp.StatusCode/100 != 2 {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, curlErrorBodyLimit))
+		return errCLI(ErrCodeGeneric, "request failed with status %d: %s", resp.StatusCode, errorBody)
+	}
+	if outputPath != "" {
+		return writeResponseToFile(cli, resp, outputPath)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Stdout, string(respBody))
+	return nil
+}
+
+// writeResponseToFile streams resp's body to outputPath, showing a progress bar against resp.ContentLength
+// when the server reported one, so piping a large export-style response doesn't look like it's hung.
+func writeResponseToFile(cli *CLI, resp *http.Response, outputPath string) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bar := util.NewProgressBar(cli.Stderr, resp.ContentLength, cli.stderrIsTerminal)
+	written, err := io.Copy(f, io.TeeReader(resp.Body, progressWriter{bar}))
+	if err != nil {
+		return err
+	}
+	bar.Finish()
+	fmt.Fprintf(cli.Stderr, "Wrote %d bytes to %s\n", written, outputPath)
+	return nil
+}
+
+// progressWriter adapts a util.ProgressBar to io.Writer, so it can be driven as the sink side of an
+// io.TeeReader over a response body of unknown total length until Content-Length says otherwise.
+type progressWriter struct{ bar *util.ProgressBar }
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	w.bar.Add(int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestCurlIssuesRequestAndPrintsBody(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"status": "ok"}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"curl", "/ApplicationStatus"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "ok")
+	assert.Equal(t, "/ApplicationStatus", httpClient.LastRequest.URL.Path)
+	assert.Equal(t, http.MethodGet, httpClient.LastRequest.Method)
+}
+
+func TestCurlSendsMethodAndData(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"curl", "-X", "POST", "--data", `{"foo": "bar"}`, "/some/path"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, httpClient.LastRequest.Method)
+}
+
+func TestCurlAppliesCustomHeader(t *testing.T) {
+	var seen string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, _ time.Duration) (*http.Response, error) {
+		seen = request.Header.Get("X-Auth-Token")
+		return okResponse("{}"), nil
+	}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"--header", "X-Auth-Token: secret", "curl", "/ApplicationStatus"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", seen)
+}
+
+func TestCurlRejectsContentTypeHeaderOverride(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+
+	err := cli.Run([]string{"--header", "Content-Type: text/plain", "curl", "/ApplicationStatus"})
+
+	assert.NotNil(t, err)
+}
+
+func TestCurlVerboseShowsHeadersAndRedactsAuthValue(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"--header", "X-Auth-Token: secret", "curl", "-v", "/ApplicationStatus"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stderr.String(), "X-Auth-Token: [redacted]")
+	assert.NotContains(t, stderr.String(), "secret")
+}
+
+func TestCurlFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader("boom")}, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"curl", "/ApplicationStatus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "500")
+		assert.Contains(t, err.Error(), "boom")
+	}
+}
+
+func TestCurlMethodFlagOverridesRequestFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"curl", "-X", "POST", "--method", "DELETE", "/some/path"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodDelete, httpClient.LastRequest.Method)
+}
+
+func TestCurlSendsBodyFromDataFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+	file := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(file, []byte(`{"foo": "bar"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"curl", "--data-file", file, "/some/path"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", httpClient.LastRequest.Header.Get("Content-Type"))
+	sent, err := io.ReadAll(httpClient.LastRequest.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"foo": "bar"}`, string(sent))
+}
+
+func TestCurlRejectsBothDataAndDataFile(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+	file := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"curl", "--data", "{}", "--data-file", file, "/some/path"})
+
+	assert.NotNil(t, err)
+}
+
+func TestCurlStreamsResponseToOutputFile(t *testing.T) {
+	resp := okResponse(`{"status": "ok"}`)
+	resp.ContentLength = int64(len(`{"status": "ok"}`))
+	httpClient := &mock.HTTPClient{NextResponse: resp}
+	cli, stdout, stderr := newTestCLI(httpClient)
+	out := filepath.Join(t.TempDir(), "response.json")
+
+	err := cli.Run([]string{"curl", "--output", out, "/ApplicationStatus"})
+
+	assert.Nil(t, err)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "Wrote")
+	data, readErr := os.ReadFile(out)
+	assert.Nil(t, readErr)
+	assert.Equal(t, `{"status": "ok"}`, string(data))
+}
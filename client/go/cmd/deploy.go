@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// Environment variables read for HTTP basic auth against the --from-url endpoint, mirroring the other
+// VESPA_CLI_* env conventions (e.g. requestSignerAccessKeyIdEnv) rather than adding a flag for a credential.
+const (
+	fromURLUsernameEnv = "VESPA_CLI_DEPLOY_FROM_URL_USERNAME"
+	fromURLPasswordEnv = "VESPA_CLI_DEPLOY_FROM_URL_PASSWORD"
+)
+
+// envVarEnvPrefix is the prefix --env-var's fallback strips to get the env var name it sets, e.g.
+// VESPA_CLI_DEPLOY_ENV_FOO=bar sets FOO=bar, for deployment-time secrets that shouldn't be passed on a
+// command line that ends up in shell history.
+const envVarEnvPrefix = "VESPA_CLI_DEPLOY_ENV_"
+
+// deploySleep is an indirection over time.Sleep, so tests can skip the real wait between --wait-for polls.
+var deploySleep = time.Sleep
+
+// maxDeployHealthPolls bounds how many times --wait-for polls a service before giving up, so a service that
+// never comes up doesn't hang the command forever.
+const maxDeployHealthPolls = 30
+
+// deployWaitServices names, in the order --wait-for all reports them, every service --wait-for can wait for.
+var deployWaitServices = []string{"query", "document", "config"}
+
+func newDeployCmd(cli *CLI) *cobra.Command {
+	var (
+		diff       bool
+		dryRun     bool
+		noValidate bool
+		fromURL    string
+		stats      bool
+		envVar     []string
+		waitFor    string
+	)
+	cmd := &cobra.Command{
+		Use:   "deploy application-package",
+		Short: "Deploy an application package",
+		Long: `Deploy an application package.
+
+Before any network traffic is sent, the package is validated locally: services.xml and hosts.xml (if
+present) must be well-formed XML, schema files must look like schemas, and files referenced by a "path"
+attribute must exist in the package. All problems found are reported together. Pass --no-validate to skip
+this check.
+
+Pass --from-url instead of a path to deploy a package fetched from an artifact URL (e.g. S3 or an
+Artifactory release), removing the need for a separate download step in a release pipeline.
+VESPA_CLI_DEPLOY_FROM_URL_USERNAME and VESPA_CLI_DEPLOY_FROM_URL_PASSWORD configure HTTP basic auth for the
+request, if the URL requires it.
+
+--instance accepts a comma-separated list of instances (e.g. --instance feature-a,feature-b), deploying the
+same, already-validated package to each of them in turn, one line of output per instance. This is only
+supported against a Vespa Cloud target, and fails with an aggregate error naming how many instances failed
+if any did.
+
+Deploying to Vespa Cloud requires a data-plane certificate (see vespa auth cert). If none is found and
+stdin is a terminal, you're asked whether to generate one now, the same way vespa auth cert would; --quiet
+skips the question and fails instead, for unattended use.
+
+--stats prints how long local validation took, and the command's total time, to stderr after the normal
+output, or as a JSON object under --format json. This command does not yet upload, prepare or activate a
+session against a target, so those phases have no timing of their own to report; --stats will grow an
+upload/prepare/activate breakdown once that pipeline exists.
+
+--env-var KEY=VALUE (repeatable) resolves deployment-time environment variables for the deploy job, e.g. for
+secrets a deployment needs at runtime that shouldn't be committed to the application package.
+VESPA_CLI_DEPLOY_ENV_* environment variables are read as a fallback for any key not already set by --env-var,
+so VESPA_CLI_DEPLOY_ENV_FOO=bar resolves FOO=bar without it appearing on a command line. As with --stats,
+this command does not yet upload a session to a target, so the resolved variables aren't sent anywhere yet;
+they're printed so --env-var is usable (and testable) ahead of that pipeline.
+
+--wait-for query|document|config|all waits, after deploying, for the named service(s) to report healthy,
+backing off between polls the same way vespa rollback --wait does, printing which service it's currently
+waiting on. Not supported together with --instance.`,
+		Example: `$ vespa deploy my-app/
+$ vespa deploy --dry-run my-app/
+$ vespa deploy --diff my-app/
+$ vespa deploy --no-validate my-app/
+$ vespa deploy --instance feature-a,feature-b my-app/
+$ vespa deploy --from-url https://artifacts.example.com/my-app.zip
+$ vespa deploy --env-var LOG_LEVEL=debug my-app/
+$ vespa deploy --wait-for all my-app/`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromURL != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			totalStart := time.Now()
+			switch waitFor {
+			case "", "query", "document", "config", "all":
+			default:
+				return fmt.Errorf("invalid --wait-for %q: must be one of query, document, config, all", waitFor)
+			}
+			deployOpts, err := vespa.ParseEnvVars(envVar, cli.env, envVarEnvPrefix)
+			if err != nil {
+				return err
+			}
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if fromURL != "" {
+				downloaded, err := downloadPackage(cli, fromURL)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(downloaded)
+				path = downloaded
+			}
+			pkg := vespa.ApplicationPackage{Path: path}
+			var validateElapsed time.Duration
+			if !noValidate {
+				validateStart := time.Now()
+				if err := pkg.Validate(); err != nil {
+					return errCLI(ErrCodeDeployInvalidPackage, "invalid application package:\n%s", err)
+				}
+				validateElapsed = time.Since(validateStart)
+			}
+			config, err := newConfig(cli)
+			if err != nil {
+				return err
+			}
+			if instances := config.instances(); len(instances) > 1 {
+				if waitFor != "" {
+					return fmt.Errorf("--wait-for is not supported together with --instance")
+				}
+				return deployToInstances(cli, config, pkg, instances, diff, dryRun, deployOpts)
+			}
+			if diff || dryRun {
+				if err := printPackageDiff(cli, pkg); err != nil {
+					return err
+				}
+				if dryRun {
+					return nil
+				}
+			}
+			if err := ensureCloudDataPlaneCertificate(cli, config, pkg.Path); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Deploying %s\n", pkg.Path)
+			printEnvVars(cli, "", deployOpts)
+			if waitFor != "" {
+				if err := waitForDeployedServices(cli, waitFor); err != nil {
+					return err
+				}
+			}
+			if stats {
+				printStats(cli, []statLine{{"validate", validateElapsed}, {"total", time.Since(totalStart)}})
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print a summary of changes compared to the active package before deploying")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the diff against the active package and exit without deploying")
+	cmd.Flags().BoolVar(&noValidate, "no-validate", false, "Skip local validation of the application package before deploying")
+	cmd.Flags().StringVar(&fromURL, "from-url", "", "Fetch the application package to deploy from this URL instead of a local path")
+	cmd.Flags().BoolVar(&stats, "stats", false, "Print local validation and total time to stderr; not supported together with --instance")
+	cmd.Flags().StringArrayVar(&envVar, "env-var", nil, "Set a deployment-time environment variable, as \"key=value\". Repeatable")
+	cmd.Flags().StringVar(&waitFor, "wait-for", "", "Wait for a service to report healthy after deploying: query, document, config, or all. Not supported together with --instance")
+	return cmd
+}
+
+// waitForDeployedServices waits for each service named by waitFor ("query", "document", "config", or "all"
+// for every one of them, in the order listed by deployWaitServices) to report healthy, printing which
+// service it's currently waiting on. "query" and "document" both poll the container's health endpoint,
+// since this CLI serves both through the same container service (see containerService); "config" polls the
+// config server's deploy service instead.
+func waitForDeployedServices(cli *CLI, waitFor string) error {
+	names := deployWaitServices
+	if waitFor != "all" {
+		names = []string{waitFor}
+	}
+	for _, name := range names {
+		fmt.Fprintf(cli.Stdout, "Waiting for %s service\n", name)
+		if err := waitForDeployedService(cli, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForDeployedService polls name's health endpoint, backing off between attempts (see newPollRetrier)
+// until it reports healthy, or maxDeployHealthPolls is reached.
+func waitForDeployedService(cli *CLI, name string) error {
+	var service *vespa.Service
+	var err error
+	if name == "config" {
+		service, err = deployService(cli)
+	} else {
+		service, err = containerService(cli, cli.clusterOverride)
+	}
+	if err != nil {
+		return err
+	}
+	retrier := newPollRetrier(cli, time.Second, time.Time{}, deploySleep)
+	var lastErr error
+	for i := 0; i < maxDeployHealthPolls; i++ {
+		if lastErr = vespa.CheckHealth(service, cli.requestTimeout); lastErr == nil {
+			retrier.Success()
+			return nil
+		}
+		retrier.Failure()
+		retrier.Wait()
+	}
+	return fmt.Errorf("%s service did not become healthy after %d attempts: %w", name, maxDeployHealthPolls, lastErr)
+}
+
+// printEnvVars prints one "Env vars: KEY=VALUE,..." line, sorted by key, if opts has any, prefixed the same
+// way as a multi-instance deploy's other output lines.
+func printEnvVars(cli *CLI, prefix string, opts vespa.DeploymentOptions) {
+	if len(opts.EnvVars) == 0 {
+		return
+	}
+	names := make([]string, 0, len(opts.EnvVars))
+	for name := range opts.EnvVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%s", name, opts.EnvVars[name])
+	}
+	fmt.Fprintln(cli.Stdout, withPrefix(prefix, "Env vars: "+strings.Join(pairs, ",")))
+}
+
+// deployToInstances deploys pkg to each of instances in turn, so a comma-separated --instance (e.g.
+// --instance feature-a,feature-b) can exercise several dev instances from a single invocation instead of
+// a separate `vespa deploy` per instance. The package is validated and, if fetched with --from-url,
+// downloaded only once up front and reused across every instance.
+//
+// This is only supported against Vespa Cloud, since a self-hosted/custom target has no notion of more than
+// one instance of the same application. Note that certificate resolution is not actually per instance in
+// this CLI: a single data-plane/API client certificate lives under the CLI profile directory and is reused
+// for every instance, exactly as `vespa status --all-instances` already does today; per-instance key pairs
+// would need dedicated certificate storage, which is a larger change than this command alone should make.
+func deployToInstances(cli *CLI, config *Config, pkg vespa.ApplicationPackage, instances []string, diff, dryRun bool, deployOpts vespa.DeploymentOptions) error {
+	endpoints, err := cli.endpoints()
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("--instance with a comma-separated list is only supported against a Vespa Cloud target, set %s", vespa.EndpointsEnv)
+	}
+	failed := 0
+	for _, instance := range instances {
+		if err := deployToInstance(cli, config, pkg, instance, diff, dryRun, deployOpts); err != nil {
+			fmt.Fprintln(cli.Stdout, withPrefix(instance, err.Error()))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errCLI(ErrCodeDeployFailed, "%d of %d instances failed to deploy", failed, len(instances))
+	}
+	return nil
+}
+
+func deployToInstance(cli *CLI, config *Config, pkg vespa.ApplicationPackage, instance string, diff, dryRun bool, deployOpts vespa.DeploymentOptions) error {
+	target, err := cli.createCloudTargetForInstance(config, instance)
+	if err != nil {
+		return err
+	}
+	if diff || dryRun {
+		if err := printPackageDiffForTarget(cli, pkg, target, instance); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+	}
+	tenant, application, err := config.application()
+	if err != nil {
+		return err
+	}
+	if err := ensureDataPlaneCertificate(cli, pkg.Path, tenant, application, instance); err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Stdout, withPrefix(instance, fmt.Sprintf("Deploying %s", pkg.Path)))
+	printEnvVars(cli, instance, deployOpts)
+	return nil
+}
+
+// ensureCloudDataPlaneCertificate is ensureDataPlaneCertificate for the single-instance deploy path: it's a
+// no-op unless config resolves to a Vespa Cloud target, since a self-hosted/custom target has no notion of
+// a data-plane certificate managed by `vespa auth cert`.
+func ensureCloudDataPlaneCertificate(cli *CLI, config *Config, appDir string) error {
+	endpoints, err := cli.endpoints()
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	tenant, application, err := config.application()
+	if err != nil {
+		return err
+	}
+	return ensureDataPlaneCertificate(cli, appDir, tenant, application, cli.resolveInstance(config))
+}
+
+// downloadPackage fetches the zip at rawURL into a temporary file, validating that its content is actually
+// a zip archive before returning its path, so a misconfigured URL (e.g. one that serves an HTML error page)
+// fails loudly instead of producing a package that only fails once uploaded.
+func downloadPackage(cli *CLI, rawURL string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if username := cli.env[fromURLUsernameEnv]; username != "" {
+		req.SetBasicAuth(username, cli.env[fromURLPasswordEnv])
+	}
+	resp, err := cli.httpClient(cli.requestTimeout).Do(req, cli.requestTimeout)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("could not fetch %s: status %d", rawURL, resp.StatusCode)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf("%s does not contain a valid zip file: %w", rawURL, err)
+	}
+	f, err := os.CreateTemp("", "vespa-deploy-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func printPackageDiff(cli *CLI, pkg vespa.ApplicationPackage) error {
+	target, err := cli.target()
+	if err != nil {
+		return err
+	}
+	return printPackageDiffForTarget(cli, pkg, target, "")
+}
+
+// printPackageDiffForTarget is printPackageDiff against an already-resolved target, so a multi-instance
+// deploy can diff each instance's CloudTarget without re-resolving it through cli.target(). prefix, if
+// non-empty, is printed before each line, identifying which instance it came from.
+func printPackageDiffForTarget(cli *CLI, pkg vespa.ApplicationPackage, target vespa.Target, prefix string) error {
+	local, err := pkg.Files()
+	if err != nil {
+		return err
+	}
+	active, err := target.ActivePackage()
+	if err != nil {
+		return err
+	}
+	d := vespa.DiffManifests(active, local)
+	if d.IsEmpty() {
+		fmt.Fprintln(cli.Stdout, withPrefix(prefix, "No changes"))
+		return nil
+	}
+	printPaths(cli, prefix, "Added", d.Added)
+	printPaths(cli, prefix, "Removed", d.Removed)
+	printPaths(cli, prefix, "Changed", d.Changed)
+	return nil
+}
+
+func withPrefix(prefix, line string) string {
+	if prefix == "" {
+		return line
+	}
+	return prefix + ": " + line
+}
+
+func printPaths(cli *CLI, prefix, label string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		highlight := ""
+		if isDestructiveFile(p) {
+			highlight = " (!)"
+		}
+		fmt.Fprintln(cli.Stdout, withPrefix(prefix, fmt.Sprintf("%s: %s%s", label, p, highlight)))
+	}
+}
+
+// isDestructiveFile reports whether a change to path is likely to affect data or serving, and should thus
+// be called out more loudly in the diff.
+func isDestructiveFile(path string) bool {
+	return strings.HasSuffix(path, ".sd") || path == "services.xml"
+}
@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// zipPackage builds an in-memory zip application package containing just a valid services.xml, for tests
+// exercising --from-url without needing a real artifact server.
+func zipPackage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("services.xml")
+	assert.Nil(t, err)
+	_, err = f.Write([]byte("<services/>"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDeployDryRunPrintsDiff(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[{"path": "services.xml", "hash": "old"}]`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--dry-run", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Changed: services.xml (!)")
+	assert.NotContains(t, stdout.String(), "Deploying")
+}
+
+func TestDeployRejectsInvalidPackage(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services><container></services>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeDeployInvalidPackage, codeOf(err))
+		assert.Contains(t, err.Error(), "services.xml")
+	}
+}
+
+func TestDeployNoValidateSkipsValidation(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services><container></services>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--no-validate", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Deploying")
+}
+
+// TestDeployStatsPrintsValidateAndTotalTime verifies --stats prints the two timings this command can
+// actually report today (validate, total), after the usual "Deploying" line.
+func TestDeployStatsPrintsValidateAndTotalTime(t *testing.T) {
+	cli, stdout, stderr := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--stats", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Deploying")
+	assert.Contains(t, stderr.String(), "validate:")
+	assert.Contains(t, stderr.String(), "total:")
+}
+
+// TestDeployEnvVarPrintsResolvedVariables verifies --env-var is parsed and, in the absence of an actual
+// upload pipeline to send it to, printed sorted by key after the usual "Deploying" line.
+func TestDeployEnvVarPrintsResolvedVariables(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--env-var", "FOO=bar", "--env-var", "BAZ=qux", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Env vars: BAZ=qux,FOO=bar\n")
+}
+
+// TestDeployEnvVarRejectsMalformedValue verifies a --env-var without "=" is rejected before any validation
+// or deployment happens.
+func TestDeployEnvVarRejectsMalformedValue(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	dir := t.TempDir()
+
+	err := cli.Run([]string{"deploy", "--env-var", "FOO", dir})
+
+	assert.NotNil(t, err)
+}
+
+// TestDeployEnvVarFallsBackToEnvironment verifies a VESPA_CLI_DEPLOY_ENV_* variable is only a fallback: when
+// --env-var sets the same key, the flag's value wins.
+func TestDeployEnvVarFallsBackToEnvironment(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cli.env["VESPA_CLI_DEPLOY_ENV_FOO"] = "from-env"
+
+	err := cli.Run([]string{"deploy", "--env-var", "FOO=from-flag", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Env vars: FOO=from-flag\n")
+}
+
+// TestDeployEnvVarReadsFromEnvironmentWithoutAFlag verifies VESPA_CLI_DEPLOY_ENV_* alone, with no --env-var
+// at all, is enough to resolve an environment variable.
+func TestDeployEnvVarReadsFromEnvironmentWithoutAFlag(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cli.env["VESPA_CLI_DEPLOY_ENV_FOO"] = "from-env"
+
+	err := cli.Run([]string{"deploy", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Env vars: FOO=from-env\n")
+}
+
+func TestDeployFromURLDownloadsAndDeploysPackage(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(string(zipPackage(t)))}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"deploy", "--from-url", "https://artifacts.example.com/my-app.zip"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://artifacts.example.com/my-app.zip", httpClient.LastRequest.URL.String())
+	assert.Contains(t, stdout.String(), "Deploying")
+	assert.Contains(t, stdout.String(), ".zip")
+}
+
+func TestDeployFromURLSendsBasicAuthFromEnv(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(string(zipPackage(t)))}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[fromURLUsernameEnv] = "alice"
+	cli.env[fromURLPasswordEnv] = "secret"
+
+	err := cli.Run([]string{"deploy", "--from-url", "https://artifacts.example.com/my-app.zip"})
+
+	assert.Nil(t, err)
+	username, password, ok := httpClient.LastRequest.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "alice", username)
+	assert.Equal(t, "secret", password)
+}
+
+func TestDeployFromURLRejectsNonZipContent(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("<html>not a zip</html>")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"deploy", "--from-url", "https://artifacts.example.com/my-app.zip"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "zip")
+	}
+}
+
+func TestDeployFromURLRejectsErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 404, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"deploy", "--from-url", "https://artifacts.example.com/my-app.zip"})
+
+	assert.NotNil(t, err)
+}
+
+func TestDeployRejectsBothPathAndFromURL(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"deploy", "--from-url", "https://artifacts.example.com/my-app.zip", "my-app/"})
+
+	assert.NotNil(t, err)
+}
+
+func TestDeployToCloudRequiresDataPlaneCertificateNonInteractively(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "vespa auth cert")
+	}
+	_, statErr := os.Stat(dataPlaneKeyPath(cli))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestDeployToCloudPromptsToGenerateMissingCertificateOnTerminalStdin(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.stdinIsTerminal = true
+	cli.Stdin = strings.NewReader("y\n")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "generate one now?")
+	assert.Contains(t, stdout.String(), "Certificate written to")
+	assert.Contains(t, stdout.String(), "Deploying "+dir)
+	key, err := os.ReadFile(dataPlaneKeyPath(cli))
+	assert.Nil(t, err)
+	assert.Contains(t, string(key), "PRIVATE KEY")
+}
+
+func TestDeployToCloudDecliningPromptKeepsErroring(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.stdinIsTerminal = true
+	cli.Stdin = strings.NewReader("n\n")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "vespa auth cert")
+	}
+}
+
+func TestDeployToCloudQuietNeverPrompts(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.stdinIsTerminal = true
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--quiet", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "vespa auth cert")
+	}
+	assert.Equal(t, "", stdout.String())
+}
+
+func TestDeployToCloudSkipsPromptWhenCertificateAlreadyExists(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.stdinIsTerminal = true
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, cli.Run([]string{"auth", "cert", dir}))
+	stdout.Reset()
+
+	err := cli.Run([]string{"deploy", dir})
+
+	assert.Nil(t, err)
+	assert.NotContains(t, stdout.String(), "generate one now?")
+	assert.Contains(t, stdout.String(), "Deploying "+dir)
+}
+
+func TestDeployMultiInstanceDeploysEachInstance(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, cli.Run([]string{"auth", "cert", dir}))
+	stdout.Reset()
+
+	err := cli.Run([]string{"deploy", "--instance", "default,beta", dir})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default: Deploying "+dir+"\nbeta: Deploying "+dir+"\n", stdout.String())
+}
+
+func TestDeployMultiInstanceRequiresCloudTarget(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--instance", "default,beta", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Vespa Cloud")
+	}
+}
+
+// TestDeployMultiInstanceAggregatesFailures verifies a failure deploying one instance doesn't stop the
+// others, and is reported as part of an aggregate error naming how many instances failed.
+func TestDeployMultiInstanceAggregatesFailures(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[]`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--diff", "--instance", "default,beta", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeDeployFailed, codeOf(err))
+		assert.Contains(t, err.Error(), "2 of 2")
+	}
+	assert.Contains(t, stdout.String(), "default: ")
+	assert.Contains(t, stdout.String(), "beta: ")
+}
+
+func TestDeployWaitForPollsUntilServiceIsHealthy(t *testing.T) {
+	deploySleep = func(time.Duration) {}
+	defer func() { deploySleep = time.Sleep }()
+	var healthChecks int
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		healthChecks++
+		if healthChecks < 2 {
+			return &http.Response{StatusCode: 503, Body: noopCloser{strings.NewReader("")}, Header: make(http.Header)}, nil
+		}
+		return jsonResponse("{}"), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--wait-for", "query", dir})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, healthChecks)
+	assert.Contains(t, stdout.String(), "Waiting for query service")
+}
+
+func TestDeployWaitForAllWaitsForEveryService(t *testing.T) {
+	deploySleep = func(time.Duration) {}
+	defer func() { deploySleep = time.Sleep }()
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse("{}")}
+	cli, stdout, _ := newTestCLI(httpClient)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--wait-for", "all", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Waiting for query service")
+	assert.Contains(t, stdout.String(), "Waiting for document service")
+	assert.Contains(t, stdout.String(), "Waiting for config service")
+}
+
+func TestDeployWaitForRejectsUnknownValue(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--wait-for", "bogus", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--wait-for")
+	}
+}
+
+func TestDeployWaitForRejectsInstanceCombination(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"deploy", "--wait-for", "query", "--instance", "default,beta", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--instance")
+	}
+}
@@ -0,0 +1,605 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newDocumentCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "document",
+		Short: "Issue document operations to a Vespa endpoint",
+		Long: `Issue document operations to a Vespa endpoint.
+
+--cluster, accepted by every subcommand below, names the container cluster to send the operation to,
+required if the application has more than one.
+
+--stats, also accepted by every subcommand below, prints the operation's round-trip time to stderr after it
+completes, or as a JSON object under --format json.`,
+		Example: `$ vespa document put id:mynamespace:music::a-head-full-of-dreams document.json --cluster mycluster`,
+	}
+	cmd.PersistentFlags().StringVar(&cli.clusterOverride, "cluster", "", "Container cluster to use, required if the application has more than one")
+	cmd.PersistentFlags().BoolVar(&cli.statsOverride, "stats", false, "Print the operation's round-trip time to stderr")
+	cmd.AddCommand(newDocumentGetCmd(cli))
+	cmd.AddCommand(newDocumentPutCmd(cli))
+	cmd.AddCommand(newDocumentUpdateCmd(cli))
+	cmd.AddCommand(newDocumentRemoveCmd(cli))
+	cmd.AddCommand(newDocumentBatchRemoveCmd(cli))
+	cmd.AddCommand(newDocumentSendCmd(cli))
+	cmd.AddCommand(newDocumentReprocessCmd(cli))
+	cmd.AddCommand(newDocumentNamespaceCmd(cli))
+	cmd.AddCommand(newDocumentExportCmd(cli))
+	cmd.AddCommand(newDocumentImportCmd(cli))
+	return cmd
+}
+
+func newDocumentNamespaceCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "namespace",
+		Short: "Manage document namespaces",
+	}
+	cmd.AddCommand(newDocumentNamespaceListCmd(cli))
+	return cmd
+}
+
+func newDocumentNamespaceListCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List all document namespaces present on a Vespa endpoint",
+		Example: `$ vespa document namespace list`,
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			namespaces, err := vespa.ListNamespaces(service, cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			for _, namespace := range namespaces {
+				fmt.Fprintln(cli.Stdout, namespace)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// documentBody resolves the operation body and document ID from either a file argument or the --data
+// flag, exactly one of which must be set. The document ID argument, if given, takes precedence over any
+// ID found inside the body, and is expanded with expandDocumentId before being returned.
+func documentBody(cli *CLI, args []string, data string) (body []byte, documentId string, err error) {
+	if data != "" {
+		if len(args) > 1 {
+			return nil, "", fmt.Errorf("cannot use --data together with a file argument")
+		}
+		if !json.Valid([]byte(data)) {
+			return nil, "", invalidJSONError(data)
+		}
+		if len(args) == 1 {
+			documentId = expandDocumentId(cli, args[0])
+		}
+		return []byte(data), documentId, nil
+	}
+	switch len(args) {
+	case 0:
+		return nil, "", fmt.Errorf("no document data given: pass a file argument or --data")
+	case 1:
+		return nil, "", nil
+	case 2:
+		return nil, expandDocumentId(cli, args[0]), nil
+	default:
+		return nil, "", fmt.Errorf("invalid arguments: expected an optional document id and a file, or --data")
+	}
+}
+
+// expandDocumentId expands id if it's a shorthand document ID missing its modifier segment, using the
+// configured document.group default, if any, leaving any other ID (already complete, or simply invalid)
+// unchanged for vespa.ParseDocumentId to accept or reject.
+func expandDocumentId(cli *CLI, id string) string {
+	config, err := newConfig(cli)
+	if err != nil {
+		return id
+	}
+	return vespa.ExpandShorthandId(id, config.get(documentGroupFlag))
+}
+
+// invalidJSONError re-parses data to produce an error message that includes the byte offset of the
+// syntax error, for quick diagnosis of a bad --data argument.
+func invalidJSONError(data string) error {
+	var v interface{}
+	err := json.Unmarshal([]byte(data), &v)
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		return fmt.Errorf("invalid JSON in --data at byte offset %d: %w", syntaxErr.Offset, err)
+	}
+	return fmt.Errorf("invalid JSON in --data: %w", err)
+}
+
+func newDocumentGetCmd(cli *CLI) *cobra.Command {
+	var (
+		tensorFormat string
+		field        string
+	)
+	cmd := &cobra.Command{
+		Use:   "get id",
+		Short: "Fetches a document from Vespa",
+		Long: `Fetches a document from Vespa by ID and prints its fields as JSON.
+
+--tensor-format renders a tensor field compactly instead of as its raw JSON literal: "short" prints a small
+tensor as an aligned table and falls back to "summary" for larger ones, "full" always lists every cell, and
+"summary" always prints the tensor's shape and value statistics plus its first few cells.
+
+--field prints only the named field's value instead of the whole document: a string field prints unquoted,
+any other field prints its JSON literal as-is, saving a pipe through jq for a quick check. It fails, printing
+nothing, if the document has no such field.`,
+		Example: `$ vespa document get id:mynamespace:music::a-head-full-of-dreams
+$ vespa document get id:mynamespace:music::a-head-full-of-dreams --tensor-format summary
+$ vespa document get id:mynamespace:music::a-head-full-of-dreams --field title`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			start := time.Now()
+			doc, err := vespa.GetDocument(service, expandDocumentId(cli, args[0]), cli.requestTimeout)
+			elapsed := time.Since(start)
+			if err != nil {
+				return err
+			}
+			fields, err := renderDocumentFields(doc.Fields, tensorFormat)
+			if err != nil {
+				return err
+			}
+			if field != "" {
+				value, ok, err := documentFieldValue(fields, field)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return errCLI(ErrCodeDocumentFieldNotFound, "field %q not found in document %s", field, doc.Id)
+				}
+				fmt.Fprintln(cli.Stdout, value)
+			} else {
+				fmt.Fprintf(cli.Stdout, "%s: %s\n", doc.Id, fields)
+			}
+			if cli.statsOverride {
+				printElapsed(cli, elapsed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&tensorFormat, "tensor-format", "", "Render tensor fields as short|full|summary instead of raw JSON")
+	cmd.Flags().StringVar(&field, "field", "", "Print only this field's value instead of the whole document")
+	return cmd
+}
+
+// documentFieldValue looks up field in fields, a document's fields object as JSON, returning its value: a
+// string field's value unquoted, any other field's value as its raw JSON literal. ok is false if fields has
+// no such field.
+func documentFieldValue(fields []byte, field string) (value string, ok bool, err error) {
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal(fields, &parsed); err != nil {
+		return "", false, err
+	}
+	raw, ok := parsed[field]
+	if !ok {
+		return "", false, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true, nil
+	}
+	return string(raw), true, nil
+}
+
+// renderDocumentFields returns fields as-is if tensorFormat is empty, or with every tensor field replaced
+// by its vespa.FormatTensor rendering under tensorFormat otherwise.
+func renderDocumentFields(fields []byte, tensorFormat string) ([]byte, error) {
+	if tensorFormat == "" {
+		return fields, nil
+	}
+	format, err := vespa.ParseTensorFormat(tensorFormat)
+	if err != nil {
+		return nil, err
+	}
+	return vespa.RenderTensors(fields, format)
+}
+
+func newDocumentPutCmd(cli *CLI) *cobra.Command {
+	var (
+		dataArg         string
+		compress        bool
+		retryOnThrottle bool
+		retryUnsafe     bool
+		condition       string
+		conditionType   string
+		stream          bool
+		bandwidthFloor  float64
+		recursive       bool
+		maxInflight     int
+	)
+	cmd := &cobra.Command{
+		Use:   "put [id] json-file",
+		Short: "Writes a document to Vespa",
+		Long: `Writes a document to Vespa.
+
+--condition restricts the write to a document matching the given selection expression, e.g.
+'music.year < 2000', so it fails rather than overwrite a document that has since changed (test-and-set).
+--condition-type chooses how that's enforced: "test-and-set" (the default) requires a matching document to
+already exist; "all" also allows the write when no document exists yet.
+
+--stream reads json-file straight off disk and sends it with Content-Length set instead of buffering it all
+in memory first, for a document too large to comfortably load whole (e.g. a multi-hundred-megabyte blob
+field). It requires an explicit id argument, since finding one embedded in the file would mean reading the
+file anyway, and it cannot be combined with --data or --compress. --bandwidth-floor assumes a transfer rate
+of at least this many megabytes/second, extending the request timeout by however long the file would take to
+send at that rate, on top of the usual per-request timeout. A connection-level failure before any of the
+body was sent is retried automatically; one after part of the body was sent is not, since /document/v1 has
+no way to resume a put partway through.
+
+If json-file is a directory, every *.json file directly inside it (or, with --recursive, inside it and every
+subdirectory) is fed as a separate put, up to --max-inflight at once, discovering each document's ID from
+its own content the same way a single file is when no ID argument is given. This is for a dataset already
+exported as one file per document, which otherwise needs a shell loop around single-document puts. A
+directory cannot be combined with an ID argument, --data, --stream or --condition, since each file is fed
+independently. A per-file result line is printed as it completes, followed by an overall summary.`,
+		Example: `$ vespa document put src/test/resources/A-Head-Full-of-Dreams.json
+$ vespa document put id:mynamespace:music::a-head-full-of-dreams src/test/resources/A-Head-Full-of-Dreams.json
+$ vespa document put id:mynamespace:music::a-head-full-of-dreams --data '{"fields": {"title": "A Head Full of Dreams"}}'
+$ vespa document put src/test/resources/A-Head-Full-of-Dreams.json.gz
+$ vespa document put id:mynamespace:music::a-head-full-of-dreams --data '{"fields": {"title": "A Head Full of Dreams"}}' --condition 'music.year < 2000'
+$ vespa document put id:mynamespace:music::a-head-full-of-dreams huge-blob.json --stream --bandwidth-floor 5
+$ vespa document put exported-docs/ --recursive`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(thisCmd *cobra.Command, args []string) error {
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			opts := vespa.OperationOptions{Compress: compress, RetryOnThrottle: retryOnThrottle, RetryUnsafe: retryUnsafe, Condition: condition, ConditionType: conditionType}
+			if len(args) > 0 {
+				if info, statErr := os.Stat(args[len(args)-1]); statErr == nil && info.IsDir() {
+					dir := args[len(args)-1]
+					if dataArg != "" {
+						return fmt.Errorf("--data cannot be combined with a directory argument")
+					}
+					if stream {
+						return fmt.Errorf("--stream cannot be combined with a directory argument")
+					}
+					if condition != "" {
+						return fmt.Errorf("--condition cannot be combined with a directory argument: each file is fed independently, with no shared selection expression to apply")
+					}
+					if len(args) == 2 {
+						return fmt.Errorf("a document ID argument cannot be combined with a directory: every file in it must carry its own \"id\"")
+					}
+					return putDirectory(cli, service, dir, recursive, maxInflight, opts)
+				}
+			}
+			if stream {
+				if dataArg != "" {
+					return fmt.Errorf("--stream cannot be combined with --data, it reads the file argument from disk")
+				}
+				if len(args) == 0 {
+					return fmt.Errorf("--stream requires a json-file argument")
+				}
+				id := ""
+				if len(args) == 2 {
+					id = expandDocumentId(cli, args[0])
+				}
+				start := time.Now()
+				result, err := vespa.PutOperationStreamed(args[len(args)-1], id, service, cli.requestTimeout, opts, bandwidthFloor)
+				return printResult(cli, result, err, time.Since(start))
+			}
+			data, id, err := documentBody(cli, args, dataArg)
+			if err != nil {
+				return err
+			}
+			var result vespa.OperationResult
+			start := time.Now()
+			if data != nil {
+				result, err = vespa.PutOperationWithData(data, id, service, cli.requestTimeout, opts)
+			} else {
+				result, err = vespa.PutOperation(args[len(args)-1], id, service, cli.requestTimeout, opts)
+			}
+			return printResult(cli, result, err, time.Since(start))
+		},
+	}
+	cmd.Flags().StringVar(&dataArg, "data", "", "Document operation body as inline JSON, instead of a file argument")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress the request body sent to Vespa")
+	cmd.Flags().BoolVar(&retryOnThrottle, "retry-on-throttle", false, "Automatically retry, honoring Retry-After, when the server responds with 429 or a throttling 503")
+	cmd.Flags().BoolVar(&retryUnsafe, "retry-unsafe", false, "Acknowledge that retrying this operation on throttle may double-apply it")
+	cmd.Flags().StringVar(&condition, "condition", "", "Only write if the document currently stored at this ID matches this selection expression (test-and-set)")
+	cmd.Flags().StringVar(&conditionType, "condition-type", "", `How --condition is enforced: "test-and-set" (default) or "all"`)
+	cmd.Flags().BoolVar(&stream, "stream", false, "Stream json-file from disk instead of buffering it in memory, for very large documents")
+	cmd.Flags().Float64Var(&bandwidthFloor, "bandwidth-floor", 1, "Assumed minimum transfer rate in megabytes/second, used with --stream to size the request timeout")
+	cmd.Flags().BoolVar(&recursive, "recursive", false, "When json-file is a directory, also descend into its subdirectories")
+	cmd.Flags().IntVar(&maxInflight, "max-inflight", 128, "When json-file is a directory, maximum number of files in flight at any time")
+	return cmd
+}
+
+// putDirectory feeds every *.json file in dir as a put operation, printing a line per file as its result
+// comes in, followed by an overall summary. It returns a non-nil error, after printing the summary, if any
+// file failed.
+func putDirectory(cli *CLI, service *vespa.Service, dir string, recursive bool, maxInflight int, opts vespa.OperationOptions) error {
+	start := time.Now()
+	summary, err := vespa.PutDirectory(dir, recursive, service, cli.requestTimeout, opts, maxInflight, func(r vespa.DirectoryPutResult) {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(cli.Stderr, "%s: %s\n", r.File, r.Err)
+		case !r.Result.Success:
+			fmt.Fprintf(cli.Stderr, "%s: status %d: %s\n", r.File, r.Result.Status, r.Result.Message)
+		default:
+			fmt.Fprintf(cli.Stderr, "%s: success\n", r.File)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "%d file(s) fed, %d succeeded, %d failed, in %s\n", summary.FileCount, summary.Success, summary.Failed, time.Since(start).Round(time.Millisecond))
+	if summary.Failed > 0 {
+		return errCLI(ErrCodeDocumentFailed, "%d file(s) failed to feed", summary.Failed)
+	}
+	return nil
+}
+
+func newDocumentUpdateCmd(cli *CLI) *cobra.Command {
+	var (
+		dataArg         string
+		compress        bool
+		retryOnThrottle bool
+		retryUnsafe     bool
+		condition       string
+		conditionType   string
+	)
+	cmd := &cobra.Command{
+		Use:   "update [id] json-file",
+		Short: "Modifies some fields of an existing document",
+		Long: `Modifies some fields of an existing document.
+
+A field update using an operator other than "assign", e.g. "increment" or "add", is not idempotent:
+applying it twice has a different effect than applying it once. Since --retry-on-throttle retries such an
+update automatically, it additionally requires --retry-unsafe, acknowledging that a retry may double-apply
+the update if the original request actually succeeded server-side before timing out.
+
+--condition restricts the update to a document matching the given selection expression (test-and-set).
+--condition-type chooses how that's enforced: "test-and-set" (the default) requires a matching document to
+already exist; "all" also allows the update when no document exists yet.`,
+		Example: `$ vespa document update id:mynamespace:music::a-head-full-of-dreams --data '{"fields": {"year": {"assign": 2015}}}'`,
+		Args:    cobra.RangeArgs(0, 2),
+		RunE: func(thisCmd *cobra.Command, args []string) error {
+			data, id, err := documentBody(cli, args, dataArg)
+			if err != nil {
+				return err
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			opts := vespa.OperationOptions{Compress: compress, RetryOnThrottle: retryOnThrottle, RetryUnsafe: retryUnsafe, Condition: condition, ConditionType: conditionType}
+			var result vespa.OperationResult
+			start := time.Now()
+			if data != nil {
+				result, err = vespa.UpdateOperationWithData(data, id, service, cli.requestTimeout, opts)
+			} else {
+				result, err = vespa.UpdateOperation(args[len(args)-1], id, service, cli.requestTimeout, opts)
+			}
+			return printResult(cli, result, err, time.Since(start))
+		},
+	}
+	cmd.Flags().StringVar(&dataArg, "data", "", "Document operation body as inline JSON, instead of a file argument")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress the request body sent to Vespa")
+	cmd.Flags().BoolVar(&retryOnThrottle, "retry-on-throttle", false, "Automatically retry, honoring Retry-After, when the server responds with 429 or a throttling 503")
+	cmd.Flags().BoolVar(&retryUnsafe, "retry-unsafe", false, "Acknowledge that retrying this operation on throttle may double-apply it")
+	cmd.Flags().StringVar(&condition, "condition", "", "Only update if the document currently stored at this ID matches this selection expression (test-and-set)")
+	cmd.Flags().StringVar(&conditionType, "condition-type", "", `How --condition is enforced: "test-and-set" (default) or "all"`)
+	return cmd
+}
+
+func newDocumentRemoveCmd(cli *CLI) *cobra.Command {
+	var (
+		dataArg         string
+		compress        bool
+		retryOnThrottle bool
+		retryUnsafe     bool
+	)
+	cmd := &cobra.Command{
+		Use:     "remove id|json-file",
+		Short:   "Removes a document from Vespa",
+		Example: `$ vespa document remove id:mynamespace:music::a-head-full-of-dreams
+$ vespa document remove --data '{"id": "id:mynamespace:music::a-head-full-of-dreams"}'`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(thisCmd *cobra.Command, args []string) error {
+			var (
+				data []byte
+				id   string
+				err  error
+			)
+			switch {
+			case dataArg != "":
+				data, id, err = documentBody(cli, args, dataArg)
+			case len(args) == 1:
+				id = expandDocumentId(cli, args[0])
+			case len(args) == 2:
+				data, id, err = documentBody(cli, args, "")
+			default:
+				err = fmt.Errorf("invalid arguments: expected a document id or --data")
+			}
+			if err != nil {
+				return err
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			opts := vespa.OperationOptions{Compress: compress, RetryOnThrottle: retryOnThrottle, RetryUnsafe: retryUnsafe}
+			start := time.Now()
+			result, err := vespa.RemoveOperationWithData(data, id, service, cli.requestTimeout, opts)
+			return printResult(cli, result, err, time.Since(start))
+		},
+	}
+	cmd.Flags().StringVar(&dataArg, "data", "", "Document operation body as inline JSON, instead of a file argument")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress the request body sent to Vespa")
+	cmd.Flags().BoolVar(&retryOnThrottle, "retry-on-throttle", false, "Automatically retry, honoring Retry-After, when the server responds with 429 or a throttling 503")
+	cmd.Flags().BoolVar(&retryUnsafe, "retry-unsafe", false, "Acknowledge that retrying this operation on throttle may double-apply it")
+	return cmd
+}
+
+func newDocumentBatchRemoveCmd(cli *CLI) *cobra.Command {
+	var (
+		idsFile     string
+		concurrency int
+	)
+	cmd := &cobra.Command{
+		Use:   "batch-remove",
+		Short: "Removes documents listed in a file, one ID per line",
+		Long: `Removes documents listed in a file, one document ID per line, issuing up to --concurrency
+remove operations at a time. Progress is reported to stderr as N/Total while it runs; once done, the number
+of documents removed and the number that failed are printed to stdout.`,
+		Example: `$ vespa document batch-remove --ids-file ids.txt
+$ vespa document batch-remove --ids-file ids.txt --concurrency 16`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if idsFile == "" {
+				return fmt.Errorf("--ids-file is required")
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			return batchRemoveDocuments(cli, service, idsFile, concurrency)
+		},
+	}
+	cmd.Flags().StringVar(&idsFile, "ids-file", "", "File with one document ID per line to remove")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 8, "Maximum number of remove operations in flight at any time")
+	return cmd
+}
+
+// batchRemoveDocuments removes each document ID listed in idsFile, one per line, using up to concurrency
+// concurrent remove operations. Progress is reported to stderr as each operation completes.
+func batchRemoveDocuments(cli *CLI, service *vespa.Service, idsFile string, concurrency int) error {
+	f, err := os.Open(idsFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var (
+		sem       = make(chan struct{}, concurrency)
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		removed   int
+		failed    int
+		completed int
+	)
+	for _, id := range ids {
+		id := expandDocumentId(cli, id)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result, err := vespa.RemoveOperationWithData(nil, id, service, cli.requestTimeout, vespa.OperationOptions{})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil || !result.Success {
+				failed++
+			} else {
+				removed++
+			}
+			completed++
+			fmt.Fprintf(cli.Stderr, "%d/%d\n", completed, len(ids))
+		}()
+	}
+	wg.Wait()
+
+	fmt.Fprintf(cli.Stdout, "Removed %d document(s), %d failed\n", removed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d document(s) failed to remove", failed)
+	}
+	return nil
+}
+
+func newDocumentSendCmd(cli *CLI) *cobra.Command {
+	var (
+		dataArg         string
+		compress        bool
+		retryOnThrottle bool
+		retryUnsafe     bool
+	)
+	cmd := &cobra.Command{
+		Use:     "send [id] json-file",
+		Short:   "Sends a document operation, determining the operation type from the file's contents",
+		Example: `$ vespa document send --data '{"id": "id:mynamespace:music::a-head-full-of-dreams", "fields": {"title": "A Head Full of Dreams"}}'`,
+		Args:    cobra.RangeArgs(0, 2),
+		RunE: func(thisCmd *cobra.Command, args []string) error {
+			data, id, err := documentBody(cli, args, dataArg)
+			if err != nil {
+				return err
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			opts := vespa.OperationOptions{Compress: compress, RetryOnThrottle: retryOnThrottle, RetryUnsafe: retryUnsafe}
+			var result vespa.OperationResult
+			start := time.Now()
+			if data != nil {
+				result, err = vespa.SendOperationWithData(data, id, vespa.OperationPut, service, cli.requestTimeout, opts)
+			} else {
+				result, err = vespa.SendOperation(args[len(args)-1], id, vespa.OperationPut, service, cli.requestTimeout, opts)
+			}
+			return printResult(cli, result, err, time.Since(start))
+		},
+	}
+	cmd.Flags().StringVar(&dataArg, "data", "", "Document operation body as inline JSON, instead of a file argument")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress the request body sent to Vespa")
+	cmd.Flags().BoolVar(&retryOnThrottle, "retry-on-throttle", false, "Automatically retry, honoring Retry-After, when the server responds with 429 or a throttling 503")
+	cmd.Flags().BoolVar(&retryUnsafe, "retry-unsafe", false, "Acknowledge that retrying this operation on throttle may double-apply it")
+	return cmd
+}
+
+func printResult(cli *CLI, result vespa.OperationResult, err error, elapsed time.Duration) error {
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		code := ErrCodeDocumentFailed
+		if result.Status == 404 {
+			code = ErrCodeDocumentNotFound
+		}
+		if result.Detail != "" {
+			return errCLI(code, "status %d: %s (%s)", result.Status, result.Message, result.Detail)
+		}
+		return errCLI(code, "status %d: %s", result.Status, result.Message)
+	}
+	if result.Detail != "" {
+		cli.printWarning("%s", result.Detail)
+	}
+	cli.printSuccess("")
+	if cli.statsOverride {
+		printElapsed(cli, elapsed)
+	}
+	return nil
+}
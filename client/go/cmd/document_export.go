@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// exportPageSize is the number of documents requested per visit page while exporting, balancing memory use
+// (a page is the most ever held in memory at once) against request overhead.
+const exportPageSize = 1000
+
+func newDocumentExportCmd(cli *CLI) *cobra.Command {
+	var (
+		namespace string
+		docType   string
+		selection string
+	)
+	cmd := &cobra.Command{
+		Use:   "export output-file",
+		Short: "Export all documents in a namespace to a JSON-lines file",
+		Long: `Export all documents in a namespace, optionally restricted to a document type or filtered with
+--selection, to a JSON-lines file of documents, suitable for feeding back with vespa document import.
+
+Documents are streamed to disk as they're visited rather than buffered in memory, so export can cover a
+namespace far larger than what would fit in RAM.`,
+		Example: `$ vespa document export --namespace music backup.jsonl
+$ vespa document export --namespace music --doctype song backup.jsonl
+$ vespa document export --namespace music --selection "song.year > 2020" backup.jsonl`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if namespace == "" {
+				return fmt.Errorf("--namespace is required")
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			return exportDocuments(cli, service, args[0], vespa.VisitOptions{
+				Namespace: namespace,
+				DocType:   docType,
+				Selection: selection,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Document namespace to export (required)")
+	cmd.Flags().StringVar(&docType, "doctype", "", "Restrict export to this document type")
+	cmd.Flags().StringVar(&selection, "selection", "", "Further restrict export with a document selection expression")
+	return cmd
+}
+
+// exportDocuments visits every document matching opts and writes it as a JSON-lines document to
+// outputFile, following the visit continuation token until the namespace is exhausted. A zero-count visit
+// is issued first to estimate the total number of documents, so progress can be reported as a percentage
+// and ETA instead of just a running count.
+func exportDocuments(cli *CLI, service *vespa.Service, outputFile string, opts vespa.VisitOptions) error {
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	writer := bufio.NewWriter(f)
+
+	estimate, err := vespa.Visit(service, vespa.VisitOptions{
+		Namespace: opts.Namespace, DocType: opts.DocType, Selection: opts.Selection, WantedDocumentCount: 0,
+	}, cli.requestTimeout)
+	if err != nil {
+		return err
+	}
+	bar := util.NewProgressBar(cli.Stdout, estimate.DocumentCount, cli.stdoutIsTerminal)
+
+	opts.WantedDocumentCount = exportPageSize
+	var documents, bytesWritten int64
+	for {
+		page, err := vespa.Visit(service, opts, cli.requestTimeout)
+		if err != nil {
+			return err
+		}
+		for _, doc := range page.Documents {
+			line, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			line = append(line, '\n')
+			n, err := writer.Write(line)
+			if err != nil {
+				return err
+			}
+			documents++
+			bytesWritten += int64(n)
+		}
+		bar.Add(int64(len(page.Documents)))
+		opts.Continuation = page.Continuation
+		if page.Continuation == "" {
+			break
+		}
+	}
+	bar.Finish()
+	fmt.Fprintf(cli.Stdout, "Exported %d documents (%d bytes)\n", documents, bytesWritten)
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestDocumentExportRequiresNamespace(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+
+	err := cli.Run([]string{"document", "export", filepath.Join(t.TempDir(), "out.jsonl")})
+
+	assert.NotNil(t, err)
+}
+
+// TestDocumentExportFollowsContinuationToken drives two visit pages and verifies every document from both
+// pages is written to the output file.
+func TestDocumentExportFollowsContinuationToken(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if strings.Contains(req.URL.RawQuery, "continuation=page2") {
+			return jsonResponse(`{"documents": [{"id": "id:music:song::b", "fields": {"title": "b"}}], "continuation": ""}`), nil
+		}
+		return jsonResponse(`{"documents": [{"id": "id:music:song::a", "fields": {"title": "a"}}], "continuation": "page2"}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	out := filepath.Join(t.TempDir(), "out.jsonl")
+
+	err := cli.Run([]string{"document", "export", "--namespace", "music", out})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Exported 2 documents")
+	data, readErr := os.ReadFile(out)
+	assert.Nil(t, readErr)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if assert.Equal(t, 2, len(lines)) {
+		assert.Contains(t, lines[0], "id:music:song::a")
+		assert.Contains(t, lines[1], "id:music:song::b")
+	}
+}
+
+// TestDocumentExportReportsETA verifies that, once a document count estimate is available from the initial
+// zero-count visit, the progress output includes an ETA derived from the observed feed rate.
+func TestDocumentExportReportsETA(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.URL.Query().Get("wantedDocumentCount") == "" {
+			return jsonResponse(`{"documents": [], "continuation": "", "documentCount": 4}`), nil
+		}
+		if strings.Contains(req.URL.RawQuery, "continuation=page2") {
+			return jsonResponse(`{"documents": [{"id": "id:music:song::b", "fields": {"title": "b"}}], "continuation": ""}`), nil
+		}
+		return jsonResponse(`{"documents": [{"id": "id:music:song::a", "fields": {"title": "a"}}], "continuation": "page2"}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.stdoutIsTerminal = true // render every update instead of throttling to one print per second
+	out := filepath.Join(t.TempDir(), "out.jsonl")
+
+	err := cli.Run([]string{"document", "export", "--namespace", "music", out})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "ETA")
+}
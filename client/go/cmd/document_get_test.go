@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestDocumentGetPrintsRawFieldsByDefault(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{"title":"A Head Full of Dreams","embedding":{"type":"tensor(x[2])","values":[1,2]}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"title":"A Head Full of Dreams"`)
+	assert.Contains(t, stdout.String(), `"values":[1,2]`)
+}
+
+func TestDocumentGetRendersTensorFields(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{"title":"A Head Full of Dreams","embedding":{"type":"tensor(x[2])","values":[1,2]}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams", "--tensor-format", "full"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "A Head Full of Dreams")
+	assert.Contains(t, stdout.String(), "tensor(x[2]):")
+	assert.NotContains(t, stdout.String(), `"values"`)
+}
+
+func TestDocumentGetRejectsInvalidTensorFormat(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams", "--tensor-format", "bogus"})
+
+	assert.NotNil(t, err)
+}
+
+func TestDocumentGetFieldPrintsStringFieldUnquoted(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{"title":"A Head Full of Dreams","embedding":{"type":"tensor(x[2])","values":[1,2]}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams", "--field", "title"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "A Head Full of Dreams\n", stdout.String())
+}
+
+func TestDocumentGetFieldPrintsStructuredFieldAsJSON(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{"title":"A Head Full of Dreams","embedding":{"type":"tensor(x[2])","values":[1,2]}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams", "--field", "embedding"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"type":"tensor(x[2])","values":[1,2]}`+"\n", stdout.String())
+}
+
+func TestDocumentGetFieldFailsAndPrintsNothingWhenFieldIsAbsent(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id":"id:mynamespace:music::a-head-full-of-dreams","fields":{"title":"A Head Full of Dreams"}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "get", "id:mynamespace:music::a-head-full-of-dreams", "--field", "missing"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeDocumentFieldNotFound, codeOf(err))
+	}
+	assert.Equal(t, "", stdout.String())
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newDocumentImportCmd(cli *CLI) *cobra.Command {
+	var (
+		failedFile  string
+		maxInflight int
+	)
+	cmd := &cobra.Command{
+		Use:   "import jsonl-file",
+		Short: "Feed documents from a JSON-lines file produced by vespa document export",
+		Long: `Feed documents from a JSON-lines file, one document per line, preserving each document's id.
+
+Every line that fails to feed is recorded, unchanged, to --failed-file (default: jsonl-file with its
+extension replaced by .failed.jsonl), so a subsequent import of just that file retries only the failures.`,
+		Example: `$ vespa document import backup.jsonl
+$ vespa document import backup.jsonl --failed-file retry.jsonl`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			file := args[0]
+			if failedFile == "" {
+				failedFile = strings.TrimSuffix(file, filepath.Ext(file)) + ".failed.jsonl"
+			}
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			return importDocuments(cli, service, file, failedFile, maxInflight)
+		},
+	}
+	cmd.Flags().StringVar(&failedFile, "failed-file", "", "Where to record lines that failed to feed, for retrying (default: jsonl-file with its extension replaced by .failed.jsonl)")
+	cmd.Flags().IntVar(&maxInflight, "max-connections", 8, "Maximum number of puts in flight at any time")
+	return cmd
+}
+
+// importDocuments feeds each line of file as a put, using up to maxInflight concurrent requests. Lines
+// that fail are written, unchanged, to failedFile so they can be retried on their own.
+func importDocuments(cli *CLI, service *vespa.Service, file, failedFile string, maxInflight int) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var (
+		sem      = make(chan struct{}, maxInflight)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		imported int64
+		failed   []string
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result, err := vespa.PutOperationWithData([]byte(line), "", service, cli.requestTimeout, vespa.OperationOptions{})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil || !result.Success {
+				failed = append(failed, line)
+				return
+			}
+			imported++
+		}()
+	}
+	wg.Wait()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		if err := os.WriteFile(failedFile, []byte(strings.Join(failed, "\n")+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(cli.Stdout, "Imported %d documents, %d failed (recorded to %s)\n", imported, len(failed), failedFile)
+		return fmt.Errorf("%d document(s) failed to import", len(failed))
+	}
+	fmt.Fprintf(cli.Stdout, "Imported %d documents\n", imported)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// TestDocumentImportRecordsFailedLinesToFile feeds a file with one document that succeeds and one that the
+// server rejects, and verifies the failing line, and only that line, ends up in the failed file.
+func TestDocumentImportRecordsFailedLinesToFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "bad") {
+			return &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader(`{"message": "boom"}`)}, Header: make(http.Header)}, nil
+		}
+		return jsonResponse(`{}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	dir := t.TempDir()
+	in := filepath.Join(dir, "backup.jsonl")
+	failed := filepath.Join(dir, "backup.failed.jsonl")
+	lines := `{"id": "id:music:song::good", "fields": {"title": "good"}}
+{"id": "id:music:song::bad", "fields": {"title": "bad"}}
+`
+	assert.Nil(t, os.WriteFile(in, []byte(lines), 0644))
+
+	err := cli.Run([]string{"document", "import", in})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "Imported 1 documents, 1 failed")
+	data, readErr := os.ReadFile(failed)
+	assert.Nil(t, readErr)
+	assert.Equal(t, `{"id": "id:music:song::bad", "fields": {"title": "bad"}}`, strings.TrimSpace(string(data)))
+}
+
+func TestDocumentImportSucceedsWhenAllDocumentsFeed(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	in := filepath.Join(t.TempDir(), "backup.jsonl")
+	assert.Nil(t, os.WriteFile(in, []byte(`{"id": "id:music:song::good", "fields": {"title": "good"}}`+"\n"), 0644))
+
+	err := cli.Run([]string{"document", "import", in})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Imported 1 documents")
+}
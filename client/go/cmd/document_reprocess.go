@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newDocumentReprocessCmd(cli *CLI) *cobra.Command {
+	var (
+		selection   string
+		docType     string
+		namespace   string
+		toCluster   string
+		maxInflight int
+		journalPath string
+		force       bool
+	)
+	cmd := &cobra.Command{
+		Use:   "reprocess",
+		Short: "Re-feed matching documents through the indexing chain",
+		Long: `Re-feed matching documents through the indexing chain.
+
+This visits the documents matching --selection and/or --type, and puts each one straight back, without
+ever materializing the full dump on disk. Use this after changing indexing statements, e.g. after adding
+a field that must be populated by an embedder, so that existing documents pick up the new behavior.`,
+		Example: `$ vespa document reprocess --type music --selection "true"
+$ vespa document reprocess --type music --to-cluster other --journal reprocess.json`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if selection == "" && docType == "" {
+				return fmt.Errorf("--selection or --type must be given")
+			}
+			if toCluster == "" {
+				toCluster = cli.clusterOverride
+			}
+			service, err := containerService(cli, toCluster)
+			if err != nil {
+				return err
+			}
+			sourceService, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			continuation, err := readJournal(journalPath)
+			if err != nil {
+				return err
+			}
+			var estimatedTotal int64 = -1
+			if continuation == "" {
+				estimate, err := vespa.Visit(sourceService, vespa.VisitOptions{
+					Namespace: namespace, DocType: docType, Selection: selection, WantedDocumentCount: 0,
+				}, cli.requestTimeout)
+				if err != nil {
+					return err
+				}
+				estimatedTotal = estimate.DocumentCount
+				if !force {
+					fmt.Fprintf(cli.Stdout, "This will reprocess an estimated %d documents. Continue? [y/N] ", estimate.DocumentCount)
+					if !cli.confirm() {
+						return fmt.Errorf("reprocess cancelled")
+					}
+				}
+			}
+			return reprocess(cli, sourceService, service, vespa.VisitOptions{
+				Namespace: namespace, DocType: docType, Selection: selection, Continuation: continuation,
+			}, maxInflight, journalPath, estimatedTotal)
+		},
+	}
+	cmd.Flags().StringVar(&selection, "selection", "", "Document selection expression restricting which documents to reprocess")
+	cmd.Flags().StringVar(&docType, "type", "", "Document type to reprocess")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Document namespace to reprocess (default: all namespaces)")
+	cmd.Flags().StringVar(&toCluster, "to-cluster", "", "Cluster to feed into, if different from the one visited")
+	cmd.Flags().IntVar(&maxInflight, "max-connections", 8, "Maximum number of puts in flight at any time")
+	cmd.Flags().StringVar(&journalPath, "journal", "", "File used to persist progress, so an interrupted run can be resumed")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+// reprocess visits documents matching opts on source, and puts each one to dest, respecting maxInflight
+// concurrent put operations. The visit continuation token is written to journalPath after every page, so
+// an interrupted run can be resumed by passing the same --journal again.
+func reprocess(cli *CLI, source, dest *vespa.Service, opts vespa.VisitOptions, maxInflight int, journalPath string, estimatedTotal int64) error {
+	var (
+		total int64
+		sem   = make(chan struct{}, maxInflight)
+	)
+	var bar *util.ProgressBar
+	if estimatedTotal > 0 {
+		bar = util.NewProgressBar(cli.Stdout, estimatedTotal, cli.stdoutIsTerminal)
+	}
+	for {
+		page, err := vespa.Visit(source, opts, cli.requestTimeout)
+		if err != nil {
+			return err
+		}
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			firstErr error
+		)
+		for _, doc := range page.Documents {
+			doc := doc
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				body := []byte(fmt.Sprintf(`{"fields": %s}`, []byte(doc.Fields)))
+				_, err := vespa.PutOperationWithData(body, doc.Id, dest, cli.requestTimeout, vespa.OperationOptions{})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s: %w", doc.Id, err)
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if firstErr != nil {
+			return firstErr
+		}
+		total += int64(len(page.Documents))
+		if bar != nil {
+			bar.Add(int64(len(page.Documents)))
+		} else {
+			fmt.Fprintf(cli.Stdout, "Reprocessed %d documents\n", total)
+		}
+		opts.Continuation = page.Continuation
+		if err := writeJournal(journalPath, opts.Continuation); err != nil {
+			return err
+		}
+		if page.Continuation == "" {
+			break
+		}
+	}
+	if bar != nil {
+		bar.Finish()
+	}
+	return removeJournal(journalPath)
+}
+
+func readJournal(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func writeJournal(path, continuation string) error {
+	if path == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(continuation), 0644)
+}
+
+func removeJournal(path string) error {
+	if path == "" {
+		return nil
+	}
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
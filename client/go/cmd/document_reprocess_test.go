@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+type closingReader struct{ *strings.Reader }
+
+func (closingReader) Close() error { return nil }
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{StatusCode: 200, Body: closingReader{strings.NewReader(body)}, Header: make(http.Header)}
+}
+
+func TestDocumentReprocessRequiresSelectionOrType(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+
+	err := cli.Run([]string{"document", "reprocess"})
+
+	assert.NotNil(t, err)
+}
+
+// TestDocumentReprocessPaginatesAndFeeds drives two visit pages into puts, and verifies that the journal
+// file is removed once the run completes.
+func TestDocumentReprocessPaginatesAndFeeds(t *testing.T) {
+	var puts int32
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			if strings.Contains(req.URL.RawQuery, "continuation=page2") {
+				return jsonResponse(`{"documents": [{"id": "id:ns:music::b", "fields": {"title": "b"}}], "continuation": ""}`), nil
+			}
+			return jsonResponse(`{"documents": [{"id": "id:ns:music::a", "fields": {"title": "a"}}], "continuation": "page2"}`), nil
+		}
+		atomic.AddInt32(&puts, 1)
+		return jsonResponse("{}"), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	journal := filepath.Join(t.TempDir(), "reprocess.json")
+
+	err := cli.Run([]string{"document", "reprocess", "--type", "music", "--force", "--journal", journal})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&puts))
+	assert.Contains(t, stdout.String(), "Reprocessed 2 documents")
+	if _, statErr := os.Stat(journal); !os.IsNotExist(statErr) {
+		t.Errorf("expected journal to be removed on successful completion, got err=%v", statErr)
+	}
+}
+
+// TestDocumentReprocessResumesFromJournal simulates an interrupted run by pre-seeding the journal with a
+// continuation token, and verifies the visit starts from there instead of the beginning.
+func TestDocumentReprocessResumesFromJournal(t *testing.T) {
+	var seenContinuations []string
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			seenContinuations = append(seenContinuations, req.URL.Query().Get("continuation"))
+			return jsonResponse(`{"documents": [], "continuation": ""}`), nil
+		}
+		return jsonResponse("{}"), nil
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	journal := filepath.Join(t.TempDir(), "reprocess.json")
+	if err := os.WriteFile(journal, []byte("page2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := cli.Run([]string{"document", "reprocess", "--type", "music", "--force", "--journal", journal})
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(seenContinuations)) {
+		assert.Equal(t, "page2", seenContinuations[0])
+	}
+}
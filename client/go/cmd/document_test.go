@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newTestCLI(httpClient *mock.HTTPClient) (*CLI, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	cli, err := NewForTesting(strings.NewReader(""), &stdout, &stderr, nil, func(_ time.Duration) util.HTTPClient { return httpClient })
+	if err != nil {
+		panic(err)
+	}
+	return cli, &stdout, &stderr
+}
+
+func okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       noopCloser{strings.NewReader(body)},
+		Header:     make(http.Header),
+	}
+}
+
+type noopCloser struct{ *strings.Reader }
+
+func (noopCloser) Close() error { return nil }
+
+func TestDocumentPutFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, stdout, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"fields": {"title": "A Head Full of Dreams"}}`)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", file})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+	assert.Equal(t, "/document/v1/mynamespace/music/docid/a-head-full-of-dreams", httpClient.LastRequest.URL.Path)
+}
+
+func TestDocumentPutRetriesOnThrottleWithRetryOnThrottleFlag(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			header := make(http.Header)
+			header.Set("Retry-After", "0")
+			return &http.Response{StatusCode: 429, Body: noopCloser{strings.NewReader("throttled")}, Header: header}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: noopCloser{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`, "--retry-on-throttle"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+	assert.Equal(t, 2, attempts)
+}
+
+func TestDocumentPutWarnsAndFallsBackOn415(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			return &http.Response{StatusCode: 415, Body: noopCloser{strings.NewReader("unsupported")}, Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: noopCloser{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`, "--compress"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+	assert.Contains(t, stderr.String(), "Warning:")
+	assert.Contains(t, stderr.String(), "retried uncompressed")
+}
+
+func TestDocumentPutFailsOnThrottleWithoutRetryFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 429, Body: noopCloser{strings.NewReader("throttled")}, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "status 429")
+}
+
+func TestDocumentUpdateRefusesUnsafeRetryWithoutAcknowledgment(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "update", "id:ns:type::1", "--data", `{"fields": {"count": {"increment": 1}}}`, "--retry-on-throttle"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "retryUnsafe")
+	}
+}
+
+func TestDocumentUpdateAllowsUnsafeRetryWithAcknowledgment(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "update", "id:ns:type::1", "--data", `{"fields": {"count": {"increment": 1}}}`, "--retry-on-throttle", "--retry-unsafe"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+}
+
+func TestDocumentNamespaceList(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"pathPrefix": ["/mynamespace/", "/other/"]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "namespace", "list"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mynamespace\nother\n", stdout.String())
+	assert.Equal(t, "/document/v1/", httpClient.LastRequest.URL.Path)
+}
+
+func TestDocumentPutData(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "A Head Full of Dreams"}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+}
+
+func TestDocumentPutDataWithIdFromBody(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "--data", `{"id": "id:mynamespace:music::a-head-full-of-dreams", "fields": {"title": "x"}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/document/v1/mynamespace/music/docid/a-head-full-of-dreams", httpClient.LastRequest.URL.Path)
+}
+
+func TestDocumentPutInvalidData(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::x", "--data", `{"fields": `})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "byte offset")
+	}
+}
+
+func TestDocumentPutDataAndFileIsInvalid(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"fields": {}}`)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::x", file, "--data", `{"fields": {}}`})
+
+	assert.NotNil(t, err)
+}
+
+func TestDocumentPutExpandsShorthandIdWithConfiguredGroup(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "set", "document.group", "user1"}))
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music:a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/document/v1/mynamespace/music/group/user1/a-head-full-of-dreams", httpClient.LastRequest.URL.Path)
+}
+
+func TestDocumentPutExpandsShorthandIdWithoutConfiguredGroup(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music:a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/document/v1/mynamespace/music/docid/a-head-full-of-dreams", httpClient.LastRequest.URL.Path)
+}
+
+func TestDocumentPutSendsConditionAsQueryParameter(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`, "--condition", "music.year < 2000"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "music.year < 2000", httpClient.LastRequest.URL.Query().Get("condition"))
+}
+
+func TestDocumentUpdateSendsConditionAndConditionType(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "update", "id:ns:type::1", "--data", `{"fields": {"year": {"assign": 2015}}}`, "--condition", "type.year < 2020", "--condition-type", "all"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "type.year < 2020", httpClient.LastRequest.URL.Query().Get("condition"))
+	assert.Equal(t, "all", httpClient.LastRequest.URL.Query().Get("conditionType"))
+}
+
+func TestDocumentPutRejectsInvalidConditionType(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:mynamespace:music::a-head-full-of-dreams", "--data", `{"fields": {"title": "x"}}`, "--condition", "true", "--condition-type", "bogus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `"bogus"`)
+	}
+}
+
+func TestDocumentPutClusterSelectsEndpointFromVespaCliEndpoints(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[vespa.EndpointsEnv] = `{"default": "https://default.example.com", "feed": "https://feed.example.com"}`
+
+	err := cli.Run([]string{"document", "put", "--cluster", "feed", "id:ns:type::1", "--data", `{"fields": {"title": "x"}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "feed.example.com", httpClient.LastRequest.URL.Host)
+}
+
+// TestDocumentPutStatsPrintsRoundTripTime verifies that --stats prints a round-trip timing line to stderr
+// after the usual "Success" output, rather than replacing or preceding it.
+func TestDocumentPutStatsPrintsRoundTripTime(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, stdout, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "--stats", "id:ns:type::1", "--data", `{"fields": {}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Success\n", stdout.String())
+	assert.Contains(t, stderr.String(), "round-trip:")
+}
+
+// TestDocumentPutWithoutStatsPrintsNoTiming verifies --stats is opt-in: without it, nothing besides the
+// usual output reaches stderr.
+func TestDocumentPutWithoutStatsPrintsNoTiming(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("{}")}
+	cli, _, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", "id:ns:type::1", "--data", `{"fields": {}}`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", stderr.String())
+}
+
+func TestDocumentBatchRemoveRemovesEachListedId(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{}`), nil
+	}
+	cli, stdout, stderr := newTestCLI(httpClient)
+	idsFile := writeTempFile(t, "id:music:song::a\nid:music:song::b\nid:music:song::c\n")
+
+	err := cli.Run([]string{"document", "batch-remove", "--ids-file", idsFile})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Removed 3 document(s), 0 failed\n", stdout.String())
+	assert.Contains(t, stderr.String(), "3/3")
+}
+
+func TestDocumentBatchRemoveReportsFailures(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "bad") {
+			return &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader(`{"message": "boom"}`)}, Header: make(http.Header)}, nil
+		}
+		return jsonResponse(`{}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	idsFile := writeTempFile(t, "id:music:song::good\nid:music:song::bad\n")
+
+	err := cli.Run([]string{"document", "batch-remove", "--ids-file", idsFile})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stdout.String(), "Removed 1 document(s), 1 failed")
+}
+
+func TestDocumentBatchRemoveRequiresIdsFile(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"document", "batch-remove"})
+
+	assert.NotNil(t, err)
+}
+
+func TestDocumentPutDirectoryFeedsEveryJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	writeAppFile(t, dir, "a.json", `{"id": "id:ns:type::a", "fields": {}}`)
+	writeAppFile(t, dir, "b.json", `{"id": "id:ns:type::b", "fields": {}}`)
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return okResponse("{}"), nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"document", "put", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "2 file(s) fed, 2 succeeded, 0 failed")
+}
+
+func TestDocumentPutDirectoryRejectsIdArgument(t *testing.T) {
+	dir := t.TempDir()
+	writeAppFile(t, dir, "a.json", `{"id": "id:ns:type::a", "fields": {}}`)
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+
+	err := cli.Run([]string{"document", "put", "id:ns:type::a", dir})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "cannot be combined with a directory")
+	}
+}
+
+func writeTempFile(t *testing.T, contents string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import "github.com/vespa-engine/vespa/client/go/vespa"
+
+// envVar describes a single VESPA_CLI_* environment variable the CLI reads, for `vespa config show-env` to
+// enumerate.
+type envVar struct {
+	name   string
+	secret bool // true if the value should be masked rather than printed verbatim
+	usage  string
+}
+
+// knownEnvVars lists every VESPA_CLI_* environment variable the CLI reads, kept in one place so `vespa
+// config show-env` doesn't need to grep the source for them whenever one is added.
+var knownEnvVars = []envVar{
+	{authHomeEnv, false, "overrides the directory credentials and per-user config are stored under"},
+	{apiKeyEnv, true, "Vespa Cloud API key used for control-plane authentication"},
+	{vespa.EndpointsEnv, false, "JSON map of cluster name to data-plane URL(s)"},
+	{cloudSystemEnv, false, "Vespa Cloud system to target"},
+	{noUpdateCheckEnv, false, "suppresses the update check vespa version otherwise makes"},
+	{versionCheckTTLEnv, false, "how long a cached latest-version lookup is considered valid"},
+	{cacheDirEnv, false, "directory CLI-managed cache files (e.g. the version check cache) are stored in"},
+	{requestSignerAccessKeyIdEnv, false, "AWS access key ID used by the \"sigv4\" request signer"},
+	{requestSignerSecretAccessKeyEnv, true, "AWS secret access key used by the \"sigv4\" request signer"},
+	{requestSignerSessionTokenEnv, true, "AWS session token used by the \"sigv4\" request signer, for temporary credentials"},
+	{requestSignerRegionEnv, false, "AWS region the \"sigv4\" request signer signs for"},
+	{requestSignerServiceEnv, false, "AWS service name the \"sigv4\" request signer signs for, defaults to \"execute-api\""},
+}
+
+// maskedEnvValue returns "***" for a secret env var whose value is non-empty, so `vespa config show-env`
+// never prints a credential, and value unchanged otherwise.
+func maskedEnvValue(v envVar, value string) string {
+	if v.secret && value != "" {
+		return "***"
+	}
+	return value
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import "fmt"
+
+// Error codes returned by ErrCLI.Code. These are part of the CLI's machine-parseable surface: scripts may
+// branch on them, so existing codes must not be renamed or repurposed once released.
+const (
+	ErrCodeGeneric                = "generic"
+	ErrCodeAuthExpired            = "auth.expired"
+	ErrCodeDeployTimeout          = "deploy.timeout"
+	ErrCodeDeployInvalidPackage   = "deploy.invalid_package"
+	ErrCodeDeployFailed           = "deploy.failed"
+	ErrCodeDocumentNotFound       = "document.not_found"
+	ErrCodeDocumentFailed         = "document.failed"
+	ErrCodeDocumentFieldNotFound  = "document.field_not_found"
+	ErrCodeQuerySearchUnavailable = "query.search_unavailable"
+	ErrCodeStatusNotReady         = "status.not_ready"
+	ErrCodeStatusFeedBlocked      = "status.feed_blocked"
+	ErrCodeConfigInvalid          = "config.invalid"
+)
+
+// ErrCLI is an error that additionally carries a process exit status and a machine-parseable code, so CI
+// scripts driving this CLI can branch on specific failure types instead of parsing a message string.
+type ErrCLI struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *ErrCLI) Error() string { return e.Message }
+
+// errCLI builds an ErrCLI with exit status 1 and the given code, formatting Message like fmt.Errorf.
+func errCLI(code, format string, args ...interface{}) *ErrCLI {
+	return &ErrCLI{Status: 1, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// codeOf returns the machine-parseable code for err, or ErrCodeGeneric if err is not an *ErrCLI.
+func codeOf(err error) string {
+	if cliErr, ok := err.(*ErrCLI); ok {
+		return cliErr.Code
+	}
+	return ErrCodeGeneric
+}
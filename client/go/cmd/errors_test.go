@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"plain error", errors.New("boom"), ErrCodeGeneric},
+		{"ErrCLI with code", errCLI(ErrCodeDocumentNotFound, "not found"), ErrCodeDocumentNotFound},
+		{"ErrCLI with empty code", &ErrCLI{Status: 1, Message: "boom"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, codeOf(tt.err))
+		})
+	}
+}
+
+func TestPrintErrJSONFormat(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+	cli.outputFormat = "json"
+
+	cli.printErr(errCLI(ErrCodeDocumentNotFound, "document not found"))
+
+	assert.JSONEq(t, `{"error":{"code":"document.not_found","message":"document not found"}}`, stderr.String())
+}
+
+func TestPrintErrHumanFormat(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+
+	cli.printErr(errCLI(ErrCodeDocumentNotFound, "document not found"))
+
+	assert.Equal(t, "Error: document not found\n", stderr.String())
+}
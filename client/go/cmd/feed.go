@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newFeedCmd(cli *CLI) *cobra.Command {
+	var (
+		dryRun         bool
+		validateSchema bool
+		schemaPath     string
+		compress       bool
+		maxInflight    int
+		rejectsFile    string
+	)
+	cmd := &cobra.Command{
+		Use:   "feed jsonl-file",
+		Short: "Feed a stream of document operations from a JSON-lines file",
+		Long: `Feed a stream of document operations from a JSON-lines file, one operation per line. Pass "-"
+instead of a file to read from stdin.
+
+Each line is either the vespa-feed-client envelope, exactly one of {"put": id, "fields": {...}},
+{"update": id, "fields": {...}} or {"remove": id}, or the bare {"id": id, "fields": {...}} form produced by
+vespa document export, which is always a put.
+
+Up to --max-inflight operations are sent at once over persistent, reused connections, which this command
+relies on for throughput instead of spawning a process per document the way shelling out to curl would. An
+operation throttled with a 429 is retried automatically with backoff. Progress (operations/sec, successes,
+failures and p99 latency) is printed to stderr about once a second; a JSON summary is printed to stdout once
+feeding completes. Every failed operation, together with the server's response, is recorded to
+--rejects-file.
+
+Use --validate-schema together with --schema to check every operation against the application's schema
+before anything is sent: unknown fields, obvious type mismatches, and document IDs missing required
+components are reported with their line number, so schema/feed drift is caught offline instead of one
+document at a time by the server. Combine with --dry-run to validate without feeding anything.`,
+		Example: `$ vespa feed docs.jsonl
+$ cat docs.jsonl | vespa feed -
+$ vespa feed docs.jsonl --max-inflight 256
+$ vespa feed docs.jsonl --dry-run --validate-schema --schema src/main/application/schemas/music.sd`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			file := args[0]
+			if validateSchema {
+				if schemaPath == "" {
+					return fmt.Errorf("--validate-schema requires --schema")
+				}
+				if file == "-" {
+					return fmt.Errorf("--validate-schema cannot be used when reading from stdin")
+				}
+				schema, err := vespa.ParseSchema(schemaPath)
+				if err != nil {
+					return err
+				}
+				violations, err := vespa.ValidateFeedFile(file, schema)
+				if err != nil {
+					return err
+				}
+				printViolations(cli, violations)
+				if len(violations) > 0 {
+					return fmt.Errorf("%d schema violation(s) found in %s", len(violations), file)
+				}
+			}
+			if dryRun {
+				fmt.Fprintln(cli.Stdout, "Dry run: no documents were fed")
+				return nil
+			}
+			service, err := containerService(cli, "")
+			if err != nil {
+				return err
+			}
+			if rejectsFile == "" {
+				rejectsFile = defaultRejectsFile(file)
+			}
+			return feedFile(cli, service, file, rejectsFile, maxInflight, compress)
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate only, without feeding any documents")
+	cmd.Flags().BoolVar(&validateSchema, "validate-schema", false, "Validate each operation against --schema before feeding")
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to the .sd file to validate operations against")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress each request body sent to Vespa")
+	cmd.Flags().IntVar(&maxInflight, "max-inflight", 128, "Maximum number of feed operations in flight at any time")
+	cmd.Flags().StringVar(&rejectsFile, "rejects-file", "", "Where to record failed operations with the server's response (default <jsonl-file>.rejected.jsonl, or rejected.jsonl when reading from stdin)")
+	return cmd
+}
+
+// defaultRejectsFile derives the default --rejects-file path from the input file, falling back to a fixed
+// name when reading from stdin, which has no path of its own to derive one from.
+func defaultRejectsFile(file string) string {
+	if file == "-" {
+		return "rejected.jsonl"
+	}
+	return file + ".rejected.jsonl"
+}
+
+// printViolations writes one line per violation, followed by a summary count per ViolationKind.
+func printViolations(cli *CLI, violations []vespa.Violation) {
+	counts := make(map[vespa.ViolationKind]int)
+	for _, v := range violations {
+		fmt.Fprintf(cli.Stderr, "line %d: %s: %s\n", v.Line, v.Kind, v.Message)
+		counts[v.Kind]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+	var kinds []string
+	for kind := range counts {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	var summary []string
+	for _, kind := range kinds {
+		summary = append(summary, fmt.Sprintf("%s=%d", kind, counts[vespa.ViolationKind(kind)]))
+	}
+	fmt.Fprintf(cli.Stderr, "%d violation(s): %s\n", len(violations), strings.Join(summary, ", "))
+}
+
+// feedRejection is one line of --rejects-file: the original operation, unchanged, alongside the status and
+// message the server (or local parsing) returned for it, so the file can be inspected or fed again after
+// the underlying problem is fixed.
+type feedRejection struct {
+	Operation json.RawMessage `json:"operation"`
+	Status    int             `json:"status"`
+	Message   string          `json:"message"`
+}
+
+// feedFile feeds every operation in file (or stdin, if file is "-") through a vespa.Feeder with up to
+// maxInflight requests in flight at once, printing progress to cli.Stderr and a JSON summary to cli.Stdout.
+// Failed operations are recorded to rejectsFile.
+func feedFile(cli *CLI, service *vespa.Service, file, rejectsFile string, maxInflight int, compress bool) error {
+	var reader io.Reader
+	if file == "-" {
+		reader = cli.Stdin
+	} else {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var rejectsMu sync.Mutex
+	var rejects []feedRejection
+	feeder := &vespa.Feeder{
+		Service:     service,
+		Timeout:     cli.requestTimeout,
+		MaxInflight: maxInflight,
+		Compress:    compress,
+		OnProgress: func(p vespa.FeedProgress) {
+			fmt.Fprintf(cli.Stderr, "%d sent, %d ok, %d failed, p99 %s\n", p.Sent, p.Success, p.Failed, p.P99Latency.Round(time.Millisecond))
+		},
+		OnReject: func(line []byte, result vespa.OperationResult, err error) {
+			message := result.Message
+			if err != nil {
+				message = err.Error()
+			}
+			rejectsMu.Lock()
+			rejects = append(rejects, feedRejection{Operation: json.RawMessage(line), Status: result.Status, Message: message})
+			rejectsMu.Unlock()
+		},
+	}
+
+	lines := make(chan []byte)
+	var scanErr error
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			lines <- append([]byte(nil), line...)
+		}
+		scanErr = scanner.Err()
+	}()
+
+	summary := feeder.Feed(lines)
+	if scanErr != nil {
+		return scanErr
+	}
+
+	if len(rejects) > 0 {
+		if err := writeRejectsFile(rejectsFile, rejects); err != nil {
+			return err
+		}
+	}
+
+	encoded, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Stdout, string(encoded))
+	if summary.Failed > 0 {
+		return errCLI(ErrCodeDocumentFailed, "%d operation(s) failed to feed, see %s", summary.Failed, rejectsFile)
+	}
+	return nil
+}
+
+// writeRejectsFile writes one JSON object per line to path, one per element of rejects.
+func writeRejectsFile(path string, rejects []feedRejection) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	for _, r := range rejects {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+func TestFeedSendsEachLine(t *testing.T) {
+	var puts int32
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		atomic.AddInt32(&puts, 1)
+		return jsonResponse("{}"), nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"id": "id:ns:music::1", "fields": {"title": "a"}}
+{"id": "id:ns:music::2", "fields": {"title": "b"}}
+`)
+
+	err := cli.Run([]string{"feed", file})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&puts))
+	var summary map[string]interface{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &summary))
+	assert.EqualValues(t, 2, summary["documentCount"])
+	assert.EqualValues(t, 2, summary["success"])
+	assert.EqualValues(t, 0, summary["failed"])
+}
+
+// TestFeedSupportsEnvelopeOperations verifies the vespa-feed-client "put"/"update"/"remove" envelope forms
+// are each dispatched with the right HTTP method.
+func TestFeedSupportsEnvelopeOperations(t *testing.T) {
+	var methods []string
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		methods = append(methods, req.Method)
+		return jsonResponse("{}"), nil
+	}}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"put": "id:ns:music::1", "fields": {"title": "a"}}
+{"update": "id:ns:music::1", "fields": {"title": {"assign": "b"}}}
+{"remove": "id:ns:music::1"}
+`)
+
+	err := cli.Run([]string{"feed", file})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{http.MethodPost, http.MethodPost, http.MethodDelete}, methods)
+}
+
+// TestFeedReadsFromStdin verifies "-" is read as stdin rather than a file named "-".
+func TestFeedReadsFromStdin(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse("{}")}
+	var stdout, stderr bytes.Buffer
+	cli, err := NewForTesting(strings.NewReader(`{"id": "id:ns:music::1", "fields": {"title": "a"}}`+"\n"), &stdout, &stderr, nil, func(_ time.Duration) util.HTTPClient { return httpClient })
+	assert.Nil(t, err)
+
+	runErr := cli.Run([]string{"feed", "-"})
+
+	assert.Nil(t, runErr)
+	assert.Equal(t, "/document/v1/ns/music/docid/1", httpClient.LastRequest.URL.Path)
+}
+
+// TestFeedRecordsRejectsFile drives one failing operation through the feeder and verifies it's recorded,
+// with the server's response, to --rejects-file.
+func TestFeedRecordsRejectsFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader(`{"message": "boom"}`)}, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"id": "id:ns:music::1", "fields": {"title": "a"}}`)
+	rejects := file + ".rejected.jsonl"
+
+	err := cli.Run([]string{"feed", file})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeDocumentFailed, codeOf(err))
+	}
+	var summary map[string]interface{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &summary))
+	assert.EqualValues(t, 1, summary["failed"])
+	data, readErr := os.ReadFile(rejects)
+	assert.Nil(t, readErr)
+	assert.Contains(t, string(data), "id:ns:music::1")
+	assert.Contains(t, string(data), "boom")
+}
+
+func TestFeedDryRunSkipsSending(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, stdout, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"id": "id:ns:music::1", "fields": {"title": "a"}}`)
+
+	err := cli.Run([]string{"feed", file, "--dry-run"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Dry run")
+	assert.Nil(t, httpClient.LastRequest)
+}
+
+func TestFeedValidateSchemaReportsViolations(t *testing.T) {
+	cli, _, stderr := newTestCLI(&mock.HTTPClient{})
+	schema := writeTempFile(t, `
+schema music {
+    document music {
+        field title type string {
+            indexing: summary
+        }
+    }
+}
+`)
+	file := writeTempFile(t, `{"id": "id:ns:music::1", "fields": {"artist": "x"}}`)
+
+	err := cli.Run([]string{"feed", file, "--dry-run", "--validate-schema", "--schema", schema})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, stderr.String(), "unknown_field")
+}
+
+func TestFeedValidateSchemaRejectsStdin(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+	schema := writeTempFile(t, `schema music { document music { } }`)
+
+	err := cli.Run([]string{"feed", "-", "--validate-schema", "--schema", schema})
+
+	assert.NotNil(t, err)
+}
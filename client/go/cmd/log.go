@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// logFollowPollInterval is how often --follow re-polls the log API for entries newer than the last one seen.
+const logFollowPollInterval = 2 * time.Second
+
+// logEncoding selects how streamLog renders each log entry.
+type logEncoding int
+
+const (
+	logEncodingHuman logEncoding = iota
+	logEncodingJSON
+)
+
+func logEncodingFromFormat(format string) (logEncoding, error) {
+	switch format {
+	case "", "human":
+		return logEncodingHuman, nil
+	case "json":
+		return logEncodingJSON, nil
+	default:
+		return 0, fmt.Errorf(`invalid format %q: must be "human" or "json"`, format)
+	}
+}
+
+func newLogCmd(cli *CLI) *cobra.Command {
+	var from, to, format string
+	var includeSource, follow bool
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the Vespa log",
+		Long: `Show the Vespa log, formatted one entry per line.
+
+Use --from and --to to bound the time range. --include-source additionally prints the source file:line an
+entry was logged from, when the log API reports one.
+
+--format json prints each entry as a JSON object instead, with fields timestamp (RFC3339 with nanoseconds),
+host, pid, service, component, level and message, suitable for shipping into a log aggregator. A line that
+fails to parse is printed as {"raw": "..."} rather than dropped.
+
+--follow keeps polling for entries logged after the current log ends, printing each as it appears, and
+cannot be combined with --to.`,
+		Example: `$ vespa log
+$ vespa log --from 2023-01-01T00:00:00Z --to 2023-01-02T00:00:00Z
+$ vespa log --include-source
+$ vespa log --format json
+$ vespa log --follow`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if follow && to != "" {
+				return fmt.Errorf("--follow cannot be combined with --to")
+			}
+			encoding, err := logEncodingFromFormat(format)
+			if err != nil {
+				return err
+			}
+			params := url.Values{}
+			if from != "" {
+				params.Set("from", from)
+			}
+			if to != "" {
+				params.Set("to", to)
+			}
+			return streamLog(cli, params, vespa.LogOptions{ShowSource: includeSource}, encoding, follow)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Only show log entries at or after this RFC 3339 timestamp")
+	cmd.Flags().StringVar(&to, "to", "", "Only show log entries before this RFC 3339 timestamp")
+	cmd.Flags().BoolVar(&includeSource, "include-source", false, "Print the source file:line each entry was logged from, when available")
+	cmd.Flags().StringVar(&format, "format", "human", `Output format for log entries. Must be "human" or "json"`)
+	cmd.Flags().BoolVar(&follow, "follow", false, "Keep polling for new log entries instead of exiting once the current log is read")
+	cmd.AddCommand(newLogFilterCmd(cli))
+	return cmd
+}
+
+// streamLog requests the log API with params, rendering and printing each entry as it's read rather than
+// buffering the whole response, since a log stream can be large. If follow is set, it keeps re-requesting
+// with params' "from" advanced past the last entry seen, sleeping logFollowPollInterval between requests,
+// until a request fails.
+func streamLog(cli *CLI, params url.Values, opts vespa.LogOptions, encoding logEncoding, follow bool) error {
+	service, err := deployService(cli)
+	if err != nil {
+		return err
+	}
+	for {
+		lastTime, err := fetchLogPage(cli, service, params, opts, encoding)
+		if err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+		if !lastTime.IsZero() {
+			params.Set("from", lastTime.Add(time.Nanosecond).Format(time.RFC3339Nano))
+		}
+		cli.sleep(logFollowPollInterval)
+	}
+}
+
+// fetchLogPage requests a single page of the log API, printing each entry as it's read, and returns the
+// latest entry timestamp seen, so streamLog can resume --follow from just after it.
+func fetchLogPage(cli *CLI, service *vespa.Service, params url.Values, opts vespa.LogOptions, encoding logEncoding) (time.Time, error) {
+	req, err := http.NewRequest(http.MethodGet, "/logs?"+params.Encode(), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return time.Time{}, fmt.Errorf("log request failed with status %d: %s", resp.StatusCode, body)
+	}
+	var lastTime time.Time
+	err = vespa.ScanLogLines(resp.Body, func(line string) {
+		entry, parseErr := vespa.ParseLogLine(line)
+		if parseErr != nil {
+			if encoding == logEncodingJSON {
+				fmt.Fprintln(cli.Stdout, string(vespa.FormatRawLogLineJSON(line)))
+			}
+			return
+		}
+		if entry.Time.After(lastTime) {
+			lastTime = entry.Time
+		}
+		if encoding == logEncodingJSON {
+			fmt.Fprintln(cli.Stdout, string(vespa.FormatLogLineJSON(entry)))
+		} else {
+			fmt.Fprintln(cli.Stdout, vespa.FormatLogLine(entry, opts))
+		}
+	})
+	return lastTime, err
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// logLevelParams maps the level names accepted by --level to the value the log API's "level" parameter expects.
+var logLevelParams = map[string]string{
+	"FATAL":   "fatal",
+	"ERROR":   "error",
+	"WARNING": "warning",
+	"INFO":    "info",
+	"CONFIG":  "config",
+	"EVENT":   "event",
+	"DEBUG":   "debug",
+	"SPAM":    "spam",
+}
+
+func newLogFilterCmd(cli *CLI) *cobra.Command {
+	var (
+		components []string
+		level      string
+	)
+	cmd := &cobra.Command{
+		Use:   "filter",
+		Short: "Show the Vespa log, filtered server-side by component and level",
+		Long: `Show the Vespa log, filtered server-side by component and level.
+
+This applies the filter through the log API's own query parameters rather than piping ` + "`vespa log`" + ` through
+grep, so filtering a large log doesn't require streaming all of it to the client first.
+
+--component may be given multiple times to include more than one component.`,
+		Example: `$ vespa log filter --component search.handler --level WARNING
+$ vespa log filter --component search.handler --component container.jdisc`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			params := url.Values{}
+			if len(components) > 0 {
+				params.Set("component", strings.Join(components, ","))
+			}
+			if level != "" {
+				param, ok := logLevelParams[strings.ToUpper(level)]
+				if !ok {
+					return fmt.Errorf("invalid level %q: must be one of fatal, error, warning, info, config, event, debug, spam", level)
+				}
+				params.Set("level", param)
+			}
+			return streamLog(cli, params, vespa.LogOptions{}, logEncodingHuman, false)
+		},
+	}
+	cmd.Flags().StringArrayVar(&components, "component", nil, "Only show log entries from this component (may be given multiple times)")
+	cmd.Flags().StringVar(&level, "level", "", "Only show log entries at this level")
+	return cmd
+}
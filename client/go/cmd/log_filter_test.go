@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestLogFilterByComponentAndLevel(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "filter", "--component", "search.handler", "--component", "container.jdisc", "--level", "WARNING"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "search.handler,container.jdisc", httpClient.LastRequest.URL.Query().Get("component"))
+	assert.Equal(t, "warning", httpClient.LastRequest.URL.Query().Get("level"))
+}
+
+func TestLogFilterRejectsInvalidLevel(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "filter", "--level", "bogus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid level")
+	}
+}
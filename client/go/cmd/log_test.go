@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestLog(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\thello\n")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "hello")
+	assert.Equal(t, "/logs", httpClient.LastRequest.URL.Path)
+}
+
+func TestLogPassesFromAndTo(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("")}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--from", "2023-01-01T00:00:00Z", "--to", "2023-01-02T00:00:00Z"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2023-01-01T00:00:00Z", httpClient.LastRequest.URL.Query().Get("from"))
+	assert.Equal(t, "2023-01-02T00:00:00Z", httpClient.LastRequest.URL.Query().Get("to"))
+}
+
+func TestLogIncludeSourcePrintsSourceFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\tHandler.java:42\thello\n")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--include-source"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "search.handler [Handler.java:42]")
+}
+
+func TestLogWithoutIncludeSourceOmitsSourceFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\tHandler.java:42\thello\n")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log"})
+
+	assert.Nil(t, err)
+	assert.NotContains(t, stdout.String(), "Handler.java:42")
+}
+
+func TestLogFormatJSON(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\thello\n")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--format", "json"})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"timestamp": "2023-11-14T22:13:20Z",
+		"host": "myhost",
+		"pid": "1/1",
+		"service": "container",
+		"component": "search.handler",
+		"level": "info",
+		"message": "hello"
+	}`, strings.TrimSpace(stdout.String()))
+}
+
+func TestLogFormatJSONEmitsRawForMalformedLine(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse("not a log line\n")}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--format", "json"})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"raw": "not a log line"}`, strings.TrimSpace(stdout.String()))
+}
+
+func TestLogRejectsInvalidFormat(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--format", "xml"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `invalid format "xml"`)
+	}
+}
+
+func TestLogFollowRejectsTo(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log", "--follow", "--to", "2023-01-02T00:00:00Z"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--follow")
+	}
+}
+
+func TestLogFollowPollsAgainWithAdvancedFrom(t *testing.T) {
+	var froms []string
+	requests := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		froms = append(froms, req.URL.Query().Get("from"))
+		requests++
+		if requests == 1 {
+			return okResponse("1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\tfirst\n"), nil
+		}
+		return nil, fmt.Errorf("connection closed")
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.sleep = func(time.Duration) {}
+
+	err := cli.Run([]string{"log", "--follow"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "connection closed")
+	}
+	assert.Contains(t, stdout.String(), "first")
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "", froms[0])
+	assert.Equal(t, "2023-11-14T22:13:20.000000001Z", froms[1])
+}
+
+func TestLogFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: noopCloser{strings.NewReader("boom")}, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"log"})
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// logfmtPollInterval is how often --follow checks for new lines (and, via a stat, whether the file has been
+// replaced) once it has caught up to the end of the file.
+const logfmtPollInterval = 250 * time.Millisecond
+
+// logfmtLevelOrder ranks level names from most to least severe, so --level filters to that level and
+// everything more severe, the same threshold semantics as the original vespa-logfmt.
+var logfmtLevelOrder = []string{"fatal", "error", "warning", "info", "config", "event", "debug", "spam"}
+
+// defaultLogfmtPath returns the log file logfmt reads when no FILE argument is given.
+func defaultLogfmtPath(cli *CLI) string {
+	home := vespa.FindHome(func(name string) string { return cli.env[name] })
+	return filepath.Join(home, "logs", "vespa", "vespa.log")
+}
+
+func newLogfmtCmd(cli *CLI) *cobra.Command {
+	var (
+		components []string
+		level      string
+		follow     bool
+		jsonOutput bool
+		showSource bool
+	)
+	cmd := &cobra.Command{
+		Use:   "logfmt [file]",
+		Short: "Format a local Vespa log file",
+		Long: `Format a local Vespa log file the same way ` + "`vespa log`" + ` formats entries from the log API, for reading a
+self-hosted node's log directly rather than through a reachable config server.
+
+With no FILE argument, reads $VESPA_HOME/logs/vespa/vespa.log (or under /opt/vespa, if VESPA_HOME is unset).
+
+--level and --component filter client-side, the same names as ` + "`vespa log filter`" + `'s; --level shows that level
+and everything more severe, e.g. --level WARNING also shows ERROR and FATAL.
+
+-f/--follow behaves like tail -F: it keeps reading new lines as they're appended, and transparently reopens
+FILE if it's replaced, without dropping or duplicating lines around the switchover, the way Vespa's own log
+rotation replaces vespa.log in place. Exits with status 0 on Ctrl-C.
+
+--json prints each entry as a JSON object instead, one per line, the same shape ` + "`vespa log --format json`" + ` uses.`,
+		Example: `$ vespa logfmt
+$ vespa logfmt -f
+$ vespa logfmt --level WARNING /home/vespa/logs/vespa/vespa.log
+$ vespa logfmt --json -f`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			path := defaultLogfmtPath(cli)
+			if len(args) == 1 {
+				path = args[0]
+			}
+			threshold := ""
+			if level != "" {
+				threshold = strings.ToLower(level)
+				if !logfmtLevelKnown(threshold) {
+					return fmt.Errorf("invalid level %q: must be one of fatal, error, warning, info, config, event, debug, spam", level)
+				}
+			}
+			filter := logfmtFilter{components: components, level: threshold}
+			opts := vespa.LogOptions{ShowSource: showSource}
+			return runLogfmt(cli, path, filter, opts, jsonOutput, follow, nil)
+		},
+	}
+	cmd.Flags().StringArrayVar(&components, "component", nil, "Only show log entries from this component (may be given multiple times)")
+	cmd.Flags().StringVar(&level, "level", "", "Only show log entries at this level or more severe")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep reading the file as it grows, following log rotation")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print each entry as a JSON object instead of formatted text")
+	cmd.Flags().BoolVar(&showSource, "include-source", false, "Print the source file:line each entry was logged from, when available")
+	return cmd
+}
+
+// logfmtFilter is the client-side filter --level/--component apply, mirroring vespa log filter's server-side
+// parameters for a local file that has no server to filter for it.
+type logfmtFilter struct {
+	components []string
+	level      string // lowercase threshold, or "" for no filtering
+}
+
+func (f logfmtFilter) matches(e vespa.LogEntry) bool {
+	if len(f.components) > 0 {
+		found := false
+		for _, c := range f.components {
+			if c == e.Component {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.level != "" && !logfmtLevelAtOrAbove(e.Level, f.level) {
+		return false
+	}
+	return true
+}
+
+func logfmtLevelKnown(level string) bool {
+	return indexOfLevel(logfmtLevelOrder, level) >= 0
+}
+
+// logfmtLevelAtOrAbove reports whether level is at least as severe as threshold, per logfmtLevelOrder. A
+// level that isn't recognized is always shown, rather than silently dropped by an unexpectedly strict match.
+func logfmtLevelAtOrAbove(level, threshold string) bool {
+	levelIndex := indexOfLevel(logfmtLevelOrder, strings.ToLower(level))
+	if levelIndex < 0 {
+		return true
+	}
+	return levelIndex <= indexOfLevel(logfmtLevelOrder, threshold)
+}
+
+func indexOfLevel(levels []string, level string) int {
+	for i, l := range levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// runLogfmt reads path, printing every entry that matches filter, in opts' format (or as JSON, if jsonOutput
+// is set). If follow, it keeps reading as the file grows, transparently reopening it if it's replaced (as
+// Vespa's own log rotation does), until interrupted with Ctrl-C or, for tests, until stop is closed.
+func runLogfmt(cli *CLI, path string, filter logfmtFilter, opts vespa.LogOptions, jsonOutput, follow bool, stop <-chan struct{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+	render := func(line string) {
+		line = strings.TrimRight(line, "\n")
+		entry, parseErr := vespa.ParseLogLine(line)
+		if parseErr != nil {
+			if jsonOutput {
+				fmt.Fprintln(cli.Stdout, string(vespa.FormatRawLogLineJSON(line)))
+			}
+			return
+		}
+		if !filter.matches(entry) {
+			return
+		}
+		if jsonOutput {
+			fmt.Fprintln(cli.Stdout, string(vespa.FormatLogLineJSON(entry)))
+		} else {
+			fmt.Fprintln(cli.Stdout, vespa.FormatLogLine(entry, opts))
+		}
+	}
+	var interrupt chan os.Signal
+	if follow {
+		interrupt = make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+	}
+	ticker := time.NewTicker(logfmtPollInterval)
+	defer ticker.Stop()
+	var pending string
+	for {
+		line, readErr := reader.ReadString('\n')
+		line = pending + line
+		pending = ""
+		if len(line) > 0 && readErr == nil {
+			render(line)
+			continue
+		}
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if !follow {
+			if len(line) > 0 {
+				render(line)
+			}
+			return nil
+		}
+		// A line whose newline hasn't been written yet is kept until the rest of it arrives.
+		pending = line
+		if newInfo, statErr := os.Stat(path); statErr == nil && !os.SameFile(info, newInfo) {
+			if newFile, openErr := os.Open(path); openErr == nil {
+				if pending != "" {
+					render(pending)
+					pending = ""
+				}
+				file.Close()
+				file = newFile
+				info = newInfo
+				reader = bufio.NewReader(file)
+				continue
+			}
+		}
+		select {
+		case <-interrupt:
+			return nil
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func writeLogLine(t *testing.T, f *os.File, service, component, level, message string) {
+	t.Helper()
+	_, err := f.WriteString("1700000000.000000\thost\t1/1\t" + service + "\t" + component + "\t" + level + "\t" + message + "\n")
+	assert.Nil(t, err)
+}
+
+func TestLogfmtPrintsFormattedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vespa.log")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, f, "container", "search.handler", "info", "started")
+	assert.Nil(t, f.Close())
+	cli, stdout, _ := newTestCLI(nil)
+
+	err = cli.Run([]string{"logfmt", path})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "INFO")
+	assert.Contains(t, stdout.String(), "container/search.handler")
+	assert.Contains(t, stdout.String(), "started")
+}
+
+func TestLogfmtFiltersByLevelThreshold(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vespa.log")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, f, "container", "search.handler", "info", "quiet")
+	writeLogLine(t, f, "container", "search.handler", "warning", "loud")
+	assert.Nil(t, f.Close())
+	cli, stdout, _ := newTestCLI(nil)
+
+	err = cli.Run([]string{"logfmt", "--level", "WARNING", path})
+
+	assert.Nil(t, err)
+	assert.NotContains(t, stdout.String(), "quiet")
+	assert.Contains(t, stdout.String(), "loud")
+}
+
+func TestLogfmtFiltersByComponent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vespa.log")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, f, "container", "search.handler", "info", "from handler")
+	writeLogLine(t, f, "container", "other.component", "info", "from other")
+	assert.Nil(t, f.Close())
+	cli, stdout, _ := newTestCLI(nil)
+
+	err = cli.Run([]string{"logfmt", "--component", "search.handler", path})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "from handler")
+	assert.NotContains(t, stdout.String(), "from other")
+}
+
+func TestLogfmtJSONPrintsOneObjectPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vespa.log")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, f, "container", "search.handler", "info", "started")
+	assert.Nil(t, f.Close())
+	cli, stdout, _ := newTestCLI(nil)
+
+	err = cli.Run([]string{"logfmt", "--json", path})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"component":"search.handler"`)
+	assert.Contains(t, stdout.String(), `"message":"started"`)
+}
+
+// TestLogfmtFollowSurvivesRotation writes lines, rotates the file out from under runLogfmt by renaming it
+// aside and creating a new one at the same path (as Vespa's own log rotation does), and verifies every line
+// from both the original and the replacement file is printed exactly once.
+func TestLogfmtFollowSurvivesRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vespa.log")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, f, "container", "search.handler", "info", "before-rotation")
+	assert.Nil(t, f.Close())
+
+	cli, stdout, _ := newTestCLI(nil)
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- runLogfmt(cli, path, logfmtFilter{}, vespa.LogOptions{}, false, true, stop)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), "before-rotation")
+	}, time.Second, time.Millisecond)
+
+	assert.Nil(t, os.Rename(path, filepath.Join(dir, "vespa.log.1")))
+	newFile, err := os.Create(path)
+	assert.Nil(t, err)
+	writeLogLine(t, newFile, "container", "search.handler", "info", "after-rotation")
+	assert.Nil(t, newFile.Close())
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), "after-rotation")
+	}, time.Second, time.Millisecond)
+
+	close(stop)
+	assert.Nil(t, <-done)
+	assert.Equal(t, 1, strings.Count(stdout.String(), "before-rotation"))
+	assert.Equal(t, 1, strings.Count(stdout.String(), "after-rotation"))
+}
+
+func TestLogfmtDefaultPathUsesVespaHome(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[vespa.VespaHomeEnv] = "/my/vespa"
+
+	assert.Equal(t, "/my/vespa/logs/vespa/vespa.log", defaultLogfmtPath(cli))
+}
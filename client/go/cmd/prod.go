@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newProdCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prod",
+		Short: "Manage production deployments in Vespa Cloud",
+	}
+	cmd.AddCommand(newProdZonesCmd(cli))
+	cmd.AddCommand(newProdStatusCmd(cli))
+	cmd.AddCommand(newProdCertificatesCmd(cli))
+	return cmd
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// dataPlaneCertificate is a single data-plane client certificate authorized for an application instance, as
+// returned by the controller's access API.
+type dataPlaneCertificate struct {
+	Fingerprint string    `json:"fingerprint"`
+	Subject     string    `json:"subject"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+func newProdCertificatesCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "certificates",
+		Short: "Manage data-plane certificates authorized for a Vespa Cloud application",
+	}
+	cmd.AddCommand(newProdCertificatesListCmd(cli))
+	return cmd
+}
+
+func newProdCertificatesListCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the data-plane certificates currently authorized for this application",
+		Example: `$ vespa prod certificates list`,
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			certs, err := fetchDataPlaneCertificates(cli)
+			if err != nil {
+				return err
+			}
+			for _, c := range certs {
+				fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\n", c.Fingerprint, c.Subject, c.Expiry.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func fetchDataPlaneCertificates(cli *CLI) ([]dataPlaneCertificate, error) {
+	target, err := cli.createCloudTarget()
+	if err != nil {
+		return nil, err
+	}
+	service := target.ControlService()
+	d := target.Deployment
+	path := fmt.Sprintf("/application/v4/tenant/%s/application/%s/instance/%s/environment/%s/region/%s/access/data-plane/role/read",
+		d.Tenant, d.Application, d.Instance, d.Environment, d.Region)
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to list certificates: status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Certificates []dataPlaneCertificate `json:"certificates"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Certificates, nil
+}
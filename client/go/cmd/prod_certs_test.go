@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestProdCertificatesListPrintsFingerprintSubjectAndExpiry(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"certificates": [
+		{"fingerprint": "ab:cd:ef", "subject": "CN=alice", "expiry": "2027-01-02T15:04:05Z"}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"prod", "certificates", "list"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ab:cd:ef\tCN=alice\t2027-01-02T15:04:05Z\n", stdout.String())
+	assert.Contains(t, httpClient.LastRequest.URL.Path, "/access/data-plane/role/read")
+}
+
+func TestProdCertificatesListFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"message": "forbidden"}`)}
+	httpClient.NextResponse.StatusCode = 403
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"prod", "certificates", "list"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "status 403")
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// deployStatusPollInterval is how often --wait re-polls the controller while a build rolls out.
+const deployStatusPollInterval = 10 * time.Second
+
+func newProdStatusCmd(cli *CLI) *cobra.Command {
+	var (
+		build int64
+		wait  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the production deployment pipeline status for this application",
+		Long: `Show the production deployment pipeline status for this application: each job's most recent run, and
+which build is currently deployed to each production region.
+
+Use --build to track a specific build, and --wait to block until that build has rolled out to every
+production region, or a job fails, which makes this usable as a gate in a CI pipeline after 'vespa prod
+submit'.`,
+		Example: `$ vespa prod status
+$ vespa prod status --build 42 --wait`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if wait && build == 0 {
+				return fmt.Errorf("--wait requires --build")
+			}
+			target, err := cli.createCloudTarget()
+			if err != nil {
+				return err
+			}
+			service := target.ControlService()
+			retrier := newPollRetrier(cli, deployStatusPollInterval, time.Time{}, cli.sleep)
+			for {
+				status, err := vespa.FetchDeploymentStatus(service, target.Deployment, cli.requestTimeout)
+				if err != nil {
+					// Fails fast rather than retrying: there's no --wait deadline at this call site to bound
+					// a retry loop by, and this command is meant to be usable as a CI gate, where an
+					// indefinite retry on a persistent error would hang the pipeline instead of failing it.
+					return err
+				}
+				retrier.Success()
+				printDeploymentStatus(cli, status)
+				if job, failed := status.FailingJob(); failed {
+					return errCLI(ErrCodeDeployTimeout, "job %s failed", job.Name)
+				}
+				if build == 0 || !wait {
+					return nil
+				}
+				if status.RolledOutEverywhere(build) {
+					fmt.Fprintf(cli.Stdout, "Build %d is live in every production region\n", build)
+					return nil
+				}
+				retrier.Wait()
+			}
+		},
+	}
+	cmd.Flags().Int64Var(&build, "build", 0, "Track this build number, used together with --wait")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Block until --build has rolled out everywhere, or a job fails")
+	return cmd
+}
+
+// printDeploymentStatus prints one line per job, highlighting failing jobs in red, followed by one line per
+// production region and its currently deployed build.
+func printDeploymentStatus(cli *CLI, status vespa.DeploymentStatus) {
+	red := useColor(cli, cli.stdoutIsTerminal)
+	for _, job := range status.Jobs {
+		line := fmt.Sprintf("%s\t%s\tbuild %d", job.Name, job.Status, job.Build)
+		if job.Failed() && red {
+			fmt.Fprintf(cli.Stdout, "\033[31m%s\033[0m\n", line)
+		} else {
+			fmt.Fprintln(cli.Stdout, line)
+		}
+	}
+	for _, region := range status.Regions {
+		fmt.Fprintf(cli.Stdout, "%s: build %d\n", region.Region, region.Build)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestProdStatusPrintsJobsAndRegions(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{
+		"jobs": [{"jobName": "system-test", "status": "success", "build": 7}],
+		"regions": [{"region": "us-east-3", "build": 7}]
+	}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"prod", "status"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "system-test")
+	assert.Contains(t, stdout.String(), "us-east-3: build 7")
+}
+
+func TestProdStatusReturnsErrorOnFailingJob(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{
+		"jobs": [{"jobName": "production-us-east-3", "status": "failure", "build": 7}]
+	}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"prod", "status"})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrCodeDeployTimeout, codeOf(err))
+}
+
+func TestProdStatusWaitPollsUntilRolledOut(t *testing.T) {
+	calls := 0
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(*http.Request, time.Duration) (*http.Response, error) {
+		calls++
+		build := 6
+		if calls >= 2 {
+			build = 7
+		}
+		return jsonResponse(`{"regions": [{"region": "us-east-3", "build": ` + strconv.Itoa(build) + `}]}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+	var slept []time.Duration
+	cli.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	err := cli.Run([]string{"prod", "status", "--build", "7", "--wait"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, slept, 1)
+	assert.Contains(t, stdout.String(), "Build 7 is live in every production region")
+}
+
+func TestProdStatusWaitRequiresBuild(t *testing.T) {
+	cli, _, _ := newTestCLI(&mock.HTTPClient{})
+
+	err := cli.Run([]string{"prod", "status", "--wait"})
+
+	assert.NotNil(t, err)
+}
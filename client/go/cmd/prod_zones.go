@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+// zone is a single Vespa Cloud zone, as returned by the controller's zone listing API.
+type zone struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment"`
+	Region      string `json:"region"`
+	Cloud       string `json:"cloud"`
+}
+
+func newProdZonesCmd(cli *CLI) *cobra.Command {
+	var environment string
+	cmd := &cobra.Command{
+		Use:   "zones",
+		Short: "List available Vespa Cloud zones",
+		Example: `$ vespa prod zones
+$ vespa prod zones --environment prod`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			zones, err := fetchZones(cli)
+			if err != nil {
+				return err
+			}
+			for _, z := range zones {
+				if environment != "" && z.Environment != environment {
+					continue
+				}
+				fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\t%s\n", z.Name, z.Environment, z.Region, z.Cloud)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&environment, "environment", "", `Only list zones in this environment, e.g. "prod"`)
+	return cmd
+}
+
+func fetchZones(cli *CLI) ([]zone, error) {
+	target, err := cli.createCloudTarget()
+	if err != nil {
+		return nil, err
+	}
+	service := target.ControlService()
+	req, err := http.NewRequest(http.MethodGet, "/zone/v1/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to list zones: status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Zones []zone `json:"zones"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Zones, nil
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestProdZonesFiltersByEnvironment(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"zones": [
+		{"name": "prod.us-east-1", "environment": "prod", "region": "us-east-1", "cloud": "aws"},
+		{"name": "dev.us-east-1", "environment": "dev", "region": "us-east-1", "cloud": "aws"}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"prod", "zones", "--environment", "prod"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "prod.us-east-1")
+	assert.NotContains(t, stdout.String(), "dev.us-east-1")
+}
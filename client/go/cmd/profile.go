@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultProfileName is the profile in effect until `vespa config profile use` selects a different one, and
+// the one `vespa config profile list` always shows even if no profile has ever been created.
+const defaultProfileName = "default"
+
+// profilesDirName and activeProfileFile are homeDir-relative: each profile gets its own subdirectory under
+// profilesDirName holding its own config.yaml and credentials, while activeProfileFile sits at the top
+// level, next to profilesDirName, recording which one is currently selected.
+const profilesDirName = "profiles"
+const activeProfileFile = "active-profile"
+
+// homeDir returns the CLI home directory, mirroring authConfigPath's original fallback to os.TempDir() when
+// authHomeEnv isn't set.
+func homeDir(cli *CLI) string {
+	dir := cli.env[authHomeEnv]
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return dir
+}
+
+// activeProfile returns the name of the currently selected profile, defaulting to defaultProfileName if
+// `vespa config profile use` has never been run.
+func activeProfile(cli *CLI) string {
+	data, err := os.ReadFile(filepath.Join(homeDir(cli), activeProfileFile))
+	if err != nil {
+		return defaultProfileName
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return defaultProfileName
+	}
+	return name
+}
+
+// profileDir returns the directory holding the active profile's config.yaml and credentials. It's created
+// lazily: callers just write into it, same as homeDir itself.
+func profileDir(cli *CLI) string {
+	return filepath.Join(homeDir(cli), profilesDirName, activeProfile(cli))
+}
+
+func profileExists(cli *CLI, name string) bool {
+	info, err := os.Stat(filepath.Join(homeDir(cli), profilesDirName, name))
+	return err == nil && info.IsDir()
+}
+
+// listProfiles lists every profile that has been created, defaulting to just defaultProfileName if none
+// have.
+func listProfiles(cli *CLI) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(homeDir(cli), profilesDirName))
+	if os.IsNotExist(err) {
+		return []string{defaultProfileName}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != defaultProfileName {
+			others = append(others, entry.Name())
+		}
+	}
+	sort.Strings(others)
+	return append([]string{defaultProfileName}, others...), nil
+}
+
+func newConfigProfileCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named configuration profiles",
+		Long: `Manage named configuration profiles.
+
+Each profile has its own config.yaml and credentials, so switching between e.g. a local dev setup and
+separate cloud tenants is a single 'vespa config profile use' instead of repeated 'vespa config set' calls.`,
+	}
+	cmd.AddCommand(newConfigProfileCreateCmd(cli))
+	cmd.AddCommand(newConfigProfileUseCmd(cli))
+	cmd.AddCommand(newConfigProfileListCmd(cli))
+	return cmd
+}
+
+func newConfigProfileCreateCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "create name",
+		Short:   "Create a new, empty configuration profile",
+		Example: `$ vespa config profile create cloud-prod`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := args[0]
+			if profileExists(cli, name) {
+				return fmt.Errorf("profile %q already exists", name)
+			}
+			if err := os.MkdirAll(filepath.Join(homeDir(cli), profilesDirName, name), 0755); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Created profile %s\n", name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newConfigProfileUseCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "use name",
+		Short:   "Switch the active configuration profile",
+		Example: `$ vespa config profile use cloud-prod`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := args[0]
+			if name != defaultProfileName && !profileExists(cli, name) {
+				return fmt.Errorf("profile %q does not exist: create it first with 'vespa config profile create %s'", name, name)
+			}
+			if err := os.MkdirAll(homeDir(cli), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(homeDir(cli), activeProfileFile), []byte(name), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Now using profile %s\n", name)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newConfigProfileListCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configuration profiles, marking the active one",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			names, err := listProfiles(cli)
+			if err != nil {
+				return err
+			}
+			active := activeProfile(cli)
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Fprintf(cli.Stdout, "%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
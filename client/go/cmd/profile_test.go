@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigDefaultsToDefaultProfile(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "profile", "list"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "* default\n", stdout.String())
+}
+
+func TestConfigProfileCreateAndUse(t *testing.T) {
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	assert.Nil(t, cli.Run([]string{"config", "profile", "create", "cloud-prod"}))
+	assert.Nil(t, cli.Run([]string{"config", "profile", "use", "cloud-prod"}))
+	stdout.Reset()
+	assert.Nil(t, cli.Run([]string{"config", "profile", "list"}))
+
+	assert.Equal(t, "  default\n* cloud-prod\n", stdout.String())
+}
+
+func TestConfigProfileUseRejectsUnknownProfile(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"config", "profile", "use", "does-not-exist"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "does not exist")
+	}
+}
+
+func TestConfigProfileCreateRejectsDuplicate(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "profile", "create", "cloud-prod"}))
+
+	err := cli.Run([]string{"config", "profile", "create", "cloud-prod"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "already exists")
+	}
+}
+
+func TestConfigProfilesHaveIndependentConfigAndCredentials(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "set", "application", "default-app.instance"}))
+	assert.Nil(t, cli.Run([]string{"config", "profile", "create", "cloud-prod"}))
+	assert.Nil(t, cli.Run([]string{"config", "profile", "use", "cloud-prod"}))
+	assert.Nil(t, cli.Run([]string{"config", "set", "application", "prod-app.instance"}))
+
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-app.instance", config.get(applicationFlag))
+
+	assert.Nil(t, cli.Run([]string{"config", "profile", "use", "default"}))
+	config, err = newConfig(cli)
+	assert.Nil(t, err)
+	assert.Equal(t, "default-app.instance", config.get(applicationFlag))
+}
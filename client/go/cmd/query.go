@@ -0,0 +1,501 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newQueryCmd(cli *CLI) *cobra.Command {
+	var (
+		save           string
+		run            string
+		listSaved      bool
+		deleteName     string
+		fallbackDocAPI bool
+		groups         bool
+		flattenGroups  bool
+		tuning         []string
+		tuningUnsafe   bool
+		saveResponse   string
+		forceSave      bool
+		pretty         bool
+		stream         bool
+		tensorFormat   string
+		stats          bool
+		tensorFiles    []string
+	)
+	cmd := &cobra.Command{
+		Use:   "query parameter=value ...",
+		Short: "Issue a query to a Vespa endpoint",
+		Long: fmt.Sprintf(`Issue a query to a Vespa endpoint.
+
+Parameters are given as key=value pairs, e.g. yql='select * from music where true' hits=5.
+
+Repeatedly-run queries can be kept in a saved query library, stored under the CLI home directory so
+VESPA_CLI_HOME relocations are respected: --save name stores the given parameters under name, --run name
+executes a saved query (any key=value arguments given alongside --run override its stored parameters for
+that run only), --list-saved enumerates saved queries with their YQL, and --delete name removes one.
+
+A minimal container setup without a search chain returns 404 from /search/. If the query is a simple
+document ID lookup (an "id" parameter) or a document selection (a "selection" parameter), pass
+--fallback-docapi to automatically retry it against the Document API instead; other queries get a hint
+that search needs to be configured in services.xml.
+
+A grouping query's response is deeply nested JSON that's painful to read directly: --groups renders it as
+an indented tree of group values and counts instead, and --flatten-groups emits one CSV row per leaf group.
+Both are ignored, falling back to the raw response, when the top-level --format flag is set to "json".
+
+--tuning key=value sets a dispatch or match-phase tuning parameter, e.g. dispatch.maxHitsPerNode=50.
+Repeatable. Keys are checked against a known list, with type validation and a suggestion if a key looks like
+a typo of a known one; a key outside that list is rejected unless --tuning-unsafe is also given. Known keys:
+
+%s
+
+--save-response file writes the response body to file instead of printing it, for building a regression
+corpus of golden results; --pretty indents it first. A short confirmation is printed to stderr instead.
+Fails if file already exists, unless --force is also given.
+
+The response body is streamed directly to stdout (or the --save-response file) as it arrives, rather than
+buffered in memory first, whenever that's possible: --pretty and --groups/--flatten-groups all need the full
+body to reparse it, so they buffer as before. --stream forces raw streaming even then, trading those
+features for flat memory use on huge results.
+
+--cluster names the container cluster to query, required if the application has more than one.
+
+--tensor-format renders a tensor field compactly instead of as its raw JSON literal: "short" prints a small
+tensor as an aligned table and falls back to "summary" for larger ones, "full" always lists every cell, and
+"summary" always prints the tensor's shape and value statistics plus its first few cells. It forces the full
+response to be buffered and reparsed, the same as --groups/--flatten-groups.
+
+--stats prints a DNS/connect/TLS/time-to-first-byte/total timing breakdown for the request to stderr after
+the response, or as a JSON object under --format json.
+
+--tensor name=path.json reads a tensor literal from path.json and passes it as input.query(name), for a
+ranking input too large to comfortably fit in a GET query string. Repeatable. Using --tensor at all switches
+the request from GET to POST; a key=value argument of the same name still takes precedence over the file on
+conflict.`, vespa.TuningKeysHelp()),
+		Example: `$ vespa query "yql=select * from music where true" hits=5
+$ vespa query "yql=select * from music where true" --save top-hits
+$ vespa query --run top-hits hits=1
+$ vespa query --list-saved
+$ vespa query --delete top-hits
+$ vespa query id=id:mynamespace:music::a-head-full-of-dreams --fallback-docapi
+$ vespa query "yql=select * from music where true|all(group(artist) each(output(count())))" --groups
+$ vespa query "yql=select * from music where true" --tuning dispatch.maxHitsPerNode=50
+$ vespa query "yql=select * from music where true" --cluster mycluster
+$ vespa query "yql=select * from music where true" --tensor embedding=embedding.json`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			var format vespa.TensorFormat
+			if tensorFormat != "" {
+				var err error
+				format, err = vespa.ParseTensorFormat(tensorFormat)
+				if err != nil {
+					return err
+				}
+			}
+			rendering := queryRendering{groups: groups, flattenGroups: flattenGroups, format: cli.outputFormat, tensorFormat: format}
+			tuningParams, err := vespa.ParseTuningParameters(tuning, tuningUnsafe)
+			if err != nil {
+				return err
+			}
+			tensors, err := parseTensorInputs(tensorFiles)
+			if err != nil {
+				return err
+			}
+			saveOpts := saveResponseOptions{path: saveResponse, pretty: pretty, force: forceSave}
+			switch {
+			case listSaved:
+				return listSavedQueries(cli)
+			case deleteName != "":
+				return deleteSavedQuery(cli, deleteName)
+			case run != "":
+				return runSavedQuery(cli, run, args, tuningParams, tensors, fallbackDocAPI, rendering, saveOpts, stream, stats)
+			default:
+				return runQuery(cli, save, args, tuningParams, tensors, fallbackDocAPI, rendering, saveOpts, stream, stats)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&save, "save", "", "Save the query parameters under name in the saved query library")
+	cmd.Flags().StringVar(&run, "run", "", "Execute the saved query name, overriding its parameters with any given on the command line")
+	cmd.Flags().BoolVar(&listSaved, "list-saved", false, "List saved queries and their YQL")
+	cmd.Flags().StringVar(&deleteName, "delete", "", "Delete the saved query name")
+	cmd.Flags().BoolVar(&fallbackDocAPI, "fallback-docapi", false, "On a 404 from /search/, automatically retry an id lookup or selection against the Document API")
+	cmd.Flags().BoolVar(&groups, "groups", false, "Render a grouping response as an indented tree of group values and counts")
+	cmd.Flags().BoolVar(&flattenGroups, "flatten-groups", false, "Render a grouping response as one CSV row per leaf group")
+	cmd.Flags().StringArrayVar(&tuning, "tuning", nil, "Set a dispatch or match-phase tuning parameter, as \"key=value\". Repeatable")
+	cmd.Flags().BoolVar(&tuningUnsafe, "tuning-unsafe", false, "Allow --tuning keys outside the known list")
+	cmd.Flags().StringVar(&saveResponse, "save-response", "", "Write the response body to this file instead of printing it")
+	cmd.Flags().BoolVar(&forceSave, "force", false, "Overwrite the --save-response file if it already exists")
+	cmd.Flags().BoolVar(&pretty, "pretty", false, "Indent the response body written by --save-response")
+	cmd.Flags().StringVar(&cli.clusterOverride, "cluster", "", "Container cluster to query, required if the application has more than one")
+	cmd.Flags().BoolVar(&stream, "stream", false, "Force raw streaming of the response body, even if --pretty or --groups/--flatten-groups was also given")
+	cmd.Flags().StringVar(&tensorFormat, "tensor-format", "", "Render tensor fields as short|full|summary instead of raw JSON")
+	cmd.Flags().BoolVar(&stats, "stats", false, "Print a DNS/connect/TLS/time-to-first-byte/total timing breakdown for the request to stderr")
+	cmd.Flags().StringArrayVar(&tensorFiles, "tensor", nil, "Read a tensor literal from path.json and pass it as input.query(name) in a POST body, as \"name=path.json\". Repeatable")
+	cmd.AddCommand(newQueryExplainCmd(cli))
+	return cmd
+}
+
+// queryRendering selects how a query response is printed.
+type queryRendering struct {
+	groups        bool
+	flattenGroups bool
+	format        string
+	tensorFormat  vespa.TensorFormat
+}
+
+// saveResponseOptions controls whether a query response is written to a file via --save-response instead
+// of being printed.
+type saveResponseOptions struct {
+	path   string
+	pretty bool
+	force  bool
+}
+
+func runQuery(cli *CLI, saveQueryName string, args []string, tuningParams vespa.TuningParameters, tensors map[string]json.RawMessage, fallbackDocAPI bool, rendering queryRendering, save saveResponseOptions, stream, stats bool) error {
+	params, err := parseQueryParameters(args)
+	if err != nil {
+		return err
+	}
+	if saveQueryName != "" {
+		if err := saveQuery(cli, saveQueryName, params); err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.Stdout, "Saved query %s\n", saveQueryName)
+	}
+	applyTuningParams(params, tuningParams)
+	return executeQuery(cli, params, tensors, fallbackDocAPI, rendering, save, stream, stats)
+}
+
+func runSavedQuery(cli *CLI, name string, overrideArgs []string, tuningParams vespa.TuningParameters, tensors map[string]json.RawMessage, fallbackDocAPI bool, rendering queryRendering, save saveResponseOptions, stream, stats bool) error {
+	saved, err := readSavedQueries(cli)
+	if err != nil {
+		return err
+	}
+	stored, ok := saved[name]
+	if !ok {
+		return fmt.Errorf("no saved query named %q", name)
+	}
+	params := url.Values{}
+	for k, v := range stored {
+		params.Set(k, v)
+	}
+	overrides, err := parseQueryParameters(overrideArgs)
+	if err != nil {
+		return err
+	}
+	for k := range overrides {
+		params.Set(k, overrides.Get(k))
+	}
+	applyTuningParams(params, tuningParams)
+	return executeQuery(cli, params, tensors, fallbackDocAPI, rendering, save, stream, stats)
+}
+
+// applyTuningParams sets each validated --tuning key=value pair on params, overriding any query parameter
+// of the same name.
+func applyTuningParams(params url.Values, tuningParams vespa.TuningParameters) {
+	for k, v := range tuningParams {
+		params.Set(k, v)
+	}
+}
+
+func executeQuery(cli *CLI, params url.Values, tensors map[string]json.RawMessage, fallbackDocAPI bool, rendering queryRendering, save saveResponseOptions, stream, stats bool) error {
+	service, err := containerService(cli, cli.clusterOverride)
+	if err != nil {
+		return err
+	}
+	if stats {
+		defer printRequestStats(cli, service)
+	}
+	if len(tensors) == 0 && canStreamQuery(rendering, save, stream) {
+		return streamQuery(cli, service, params, fallbackDocAPI, save)
+	}
+	result, err := issueQuery(service, params, tensors, cli.requestTimeout)
+	if err == nil {
+		if save.path != "" {
+			return saveQueryResponse(cli, result.Body, save)
+		}
+		return renderQueryResult(cli, result.Body, rendering)
+	}
+	queryErr, ok := err.(*vespa.QueryError)
+	if !ok || queryErr.Status != 404 {
+		return err
+	}
+	return queryFallback(cli, service, params, fallbackDocAPI)
+}
+
+// issueQuery runs params as a GET query, or as a POST carrying tensors under input.query(name) alongside
+// params when any were given, since a large tensor literal doesn't comfortably fit in a GET query string.
+func issueQuery(service *vespa.Service, params url.Values, tensors map[string]json.RawMessage, timeout time.Duration) (vespa.QueryResult, error) {
+	if len(tensors) == 0 {
+		return vespa.Query(service, params, timeout)
+	}
+	return vespa.PostQuery(service, params, tensors, timeout)
+}
+
+// canStreamQuery reports whether a query response can be copied straight through to its destination
+// without buffering: --pretty, --groups/--flatten-groups and --tensor-format all need the full body in
+// memory to reparse it, so they still buffer unless stream forces raw streaming regardless.
+func canStreamQuery(rendering queryRendering, save saveResponseOptions, stream bool) bool {
+	if stream {
+		return true
+	}
+	if save.path != "" {
+		return !save.pretty
+	}
+	return !rendering.groups && !rendering.flattenGroups && rendering.tensorFormat == ""
+}
+
+// streamQuery issues the query and copies its response body directly to stdout, or to save.path if set,
+// without buffering it in memory first.
+func streamQuery(cli *CLI, service *vespa.Service, params url.Values, fallbackDocAPI bool, save saveResponseOptions) error {
+	var w io.Writer = cli.Stdout
+	if save.path != "" {
+		if !save.force {
+			if _, err := os.Stat(save.path); err == nil {
+				return fmt.Errorf("%s already exists: use --force to overwrite it", save.path)
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+		}
+		f, err := os.Create(save.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	err := vespa.QueryStream(service, params, cli.requestTimeout, w)
+	if err == nil {
+		if save.path != "" {
+			fmt.Fprintf(cli.Stderr, "Wrote response to %s\n", save.path)
+		} else {
+			fmt.Fprintln(cli.Stdout)
+		}
+		return nil
+	}
+	queryErr, ok := err.(*vespa.QueryError)
+	if !ok || queryErr.Status != 404 {
+		return err
+	}
+	return queryFallback(cli, service, params, fallbackDocAPI)
+}
+
+// saveQueryResponse writes body to save.path instead of printing it, indenting it first if save.pretty is
+// set, and prints a short confirmation to stderr. It fails if the file already exists, unless save.force is
+// set.
+func saveQueryResponse(cli *CLI, body []byte, save saveResponseOptions) error {
+	if !save.force {
+		if _, err := os.Stat(save.path); err == nil {
+			return fmt.Errorf("%s already exists: use --force to overwrite it", save.path)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	output := body
+	if save.pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", "  "); err != nil {
+			return fmt.Errorf("response is not valid JSON, cannot pretty-print: %w", err)
+		}
+		output = buf.Bytes()
+	}
+	if err := os.WriteFile(save.path, output, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stderr, "Wrote response to %s\n", save.path)
+	return nil
+}
+
+// renderQueryResult prints body the way rendering asks for: the raw response by default or when
+// --format json is given, otherwise a group tree or flattened CSV rows if --groups or --flatten-groups
+// was requested. --tensor-format, if set, is applied first, regardless of the other options.
+func renderQueryResult(cli *CLI, body []byte, rendering queryRendering) error {
+	if rendering.tensorFormat != "" {
+		rendered, err := vespa.RenderTensors(body, rendering.tensorFormat)
+		if err != nil {
+			return err
+		}
+		body = rendered
+	}
+	if rendering.format == "json" {
+		fmt.Fprintln(cli.Stdout, string(body))
+		return nil
+	}
+	switch {
+	case rendering.flattenGroups:
+		return renderFlatGroups(cli.Stdout, body)
+	case rendering.groups:
+		return renderGroupTree(cli.Stdout, body)
+	default:
+		fmt.Fprintln(cli.Stdout, string(body))
+		return nil
+	}
+}
+
+// queryFallback handles a 404 from /search/, which on a minimal container setup usually means no search
+// chain is configured at all. An id or selection parameter can be served by the Document API instead,
+// since those don't need a search chain; anything else gets a hint pointing at the real cause.
+func queryFallback(cli *CLI, service *vespa.Service, params url.Values, fallbackDocAPI bool) error {
+	id := params.Get("id")
+	selection := params.Get("selection")
+	switch {
+	case id != "":
+		if !fallbackDocAPI {
+			return errCLI(ErrCodeQuerySearchUnavailable, "search is not configured (404 from /search/): this looks like a document id lookup, rerun with --fallback-docapi to fetch it via the Document API instead")
+		}
+		doc, err := vespa.GetDocument(service, id, cli.requestTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cli.Stdout, "(from document API) %s: %s\n", doc.Id, doc.Fields)
+		return nil
+	case selection != "":
+		if !fallbackDocAPI {
+			return errCLI(ErrCodeQuerySearchUnavailable, "search is not configured (404 from /search/): this looks like a document selection, rerun with --fallback-docapi to visit matching documents via the Document API instead")
+		}
+		result, err := vespa.Visit(service, vespa.VisitOptions{Selection: selection}, cli.requestTimeout)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cli.Stdout, "(from document API)")
+		for _, doc := range result.Documents {
+			fmt.Fprintf(cli.Stdout, "%s: %s\n", doc.Id, doc.Fields)
+		}
+		return nil
+	default:
+		return errCLI(ErrCodeQuerySearchUnavailable, "search is not configured (404 from /search/): add a <search> chain to services.xml, or query using an \"id\" or \"selection\" parameter to fall back to the Document API")
+	}
+}
+
+func listSavedQueries(cli *CLI) error {
+	saved, err := readSavedQueries(cli)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(cli.Stdout, "%s: %s\n", name, saved[name]["yql"])
+	}
+	return nil
+}
+
+func deleteSavedQuery(cli *CLI, name string) error {
+	saved, err := readSavedQueries(cli)
+	if err != nil {
+		return err
+	}
+	if _, ok := saved[name]; !ok {
+		return fmt.Errorf("no saved query named %q", name)
+	}
+	delete(saved, name)
+	if err := writeSavedQueries(cli, saved); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.Stdout, "Deleted query %s\n", name)
+	return nil
+}
+
+func saveQuery(cli *CLI, name string, params url.Values) error {
+	saved, err := readSavedQueries(cli)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string, len(params))
+	for k := range params {
+		values[k] = params.Get(k)
+	}
+	saved[name] = values
+	return writeSavedQueries(cli, saved)
+}
+
+// parseQueryParameters parses args as key=value query parameters, as accepted by vespa query and --run
+// overrides alike.
+func parseQueryParameters(args []string) (url.Values, error) {
+	params := url.Values{}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid query parameter %q: must be key=value", arg)
+		}
+		params.Set(parts[0], parts[1])
+	}
+	return params, nil
+}
+
+// parseTensorInputs parses each "name=path" pair in args, as accepted by --tensor, reading and validating
+// path's content as JSON. The returned map is keyed by tensor name, for PostQuery to embed each value under
+// its own input.query(name) key.
+func parseTensorInputs(args []string) (map[string]json.RawMessage, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	tensors := make(map[string]json.RawMessage, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid --tensor %q: must be name=path", arg)
+		}
+		name, path := parts[0], parts[1]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("input.query(%s): %w", name, err)
+		}
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("input.query(%s): %s does not contain valid JSON", name, path)
+		}
+		tensors[name] = json.RawMessage(data)
+	}
+	return tensors, nil
+}
+
+// savedQueriesFile is the saved query library, stored at the top of the CLI home directory, shared by every
+// profile.
+const savedQueriesFile = "queries.yaml"
+
+func savedQueriesPath(cli *CLI) string {
+	return filepath.Join(homeDir(cli), savedQueriesFile)
+}
+
+func readSavedQueries(cli *CLI) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(savedQueriesPath(cli))
+	if os.IsNotExist(err) {
+		return map[string]map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[string]map[string]string)
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%s: %w", savedQueriesPath(cli), err)
+	}
+	return saved, nil
+}
+
+func writeSavedQueries(cli *CLI, saved map[string]map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(savedQueriesPath(cli)), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(savedQueriesPath(cli), data, 0644)
+}
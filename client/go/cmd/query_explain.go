@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newQueryExplainCmd(cli *CLI) *cobra.Command {
+	var traceLevel int
+	cmd := &cobra.Command{
+		Use:   "explain yql [parameter=value ...]",
+		Short: "Run a query and pretty-print its execution trace",
+		Long: `Run a query the same way ` + "`vespa query`" + ` does, with &tracelevel added, then pretty-print the
+"trace" field of the response as an indented tree instead of the raw JSON response, for spotting which part
+of a slow query is slow.
+
+yql may be given bare, as shorthand for yql=<value>, or as a yql=... parameter alongside any other
+parameter=value pairs ` + "`vespa query`" + ` accepts.
+
+--trace-level sets the trace verbosity, 1-9; higher is more detailed.`,
+		Example: `$ vespa query explain "select * from music where true"
+$ vespa query explain "select * from music where true" hits=1 --trace-level 9`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if traceLevel < 1 || traceLevel > 9 {
+				return fmt.Errorf("invalid --trace-level %d: must be between 1 and 9", traceLevel)
+			}
+			params, err := parseQueryParameters(explainArgs(args))
+			if err != nil {
+				return err
+			}
+			params.Set("tracelevel", strconv.Itoa(traceLevel))
+			service, err := containerService(cli, cli.clusterOverride)
+			if err != nil {
+				return err
+			}
+			result, err := vespa.Query(service, params, cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			return printQueryTrace(cli.Stdout, result.Body)
+		},
+	}
+	cmd.Flags().IntVar(&traceLevel, "trace-level", 5, "Trace verbosity, 1-9; higher is more detailed")
+	return cmd
+}
+
+// explainArgs turns a bare first argument without "=" into a "yql=" parameter, so `vespa query explain
+// "select ..."` works the same as `vespa query explain yql="select ..."`.
+func explainArgs(args []string) []string {
+	if strings.Contains(args[0], "=") {
+		return args
+	}
+	rest := make([]string, 0, len(args))
+	rest = append(rest, "yql="+args[0])
+	rest = append(rest, args[1:]...)
+	return rest
+}
+
+// queryTraceNode is one node of the "trace" field of a query response, recursively nested under children.
+// Timestamp is a pointer since a node's first/root timestamp is legitimately 0, which must still print a
+// "[0ms]" prefix, unlike a node that has no timestamp field at all.
+type queryTraceNode struct {
+	Message   string           `json:"message,omitempty"`
+	Tag       string           `json:"tag,omitempty"`
+	Timestamp *int64           `json:"timestamp,omitempty"`
+	Children  []queryTraceNode `json:"children,omitempty"`
+}
+
+// queryTraceResponse is the subset of a query response printQueryTrace needs.
+type queryTraceResponse struct {
+	Trace struct {
+		Children []queryTraceNode `json:"children"`
+	} `json:"trace"`
+}
+
+// printQueryTrace parses body's "trace" field and pretty-prints it to w as an indented tree, one line per
+// node, showing its timestamp and component name or message where set.
+func printQueryTrace(w io.Writer, body []byte) error {
+	var response queryTraceResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	if len(response.Trace.Children) == 0 {
+		return fmt.Errorf("response has no trace: the query may have failed, or the container may be too old to support tracelevel")
+	}
+	for _, child := range response.Trace.Children {
+		printTraceNode(w, child, 0)
+	}
+	return nil
+}
+
+func printTraceNode(w io.Writer, node queryTraceNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	var parts []string
+	if node.Timestamp != nil {
+		parts = append(parts, fmt.Sprintf("[%dms]", *node.Timestamp))
+	}
+	if node.Tag != "" {
+		parts = append(parts, node.Tag)
+	}
+	if node.Message != "" {
+		parts = append(parts, node.Message)
+	}
+	if len(parts) > 0 {
+		fmt.Fprintf(w, "%s%s\n", indent, strings.Join(parts, " "))
+	}
+	for _, child := range node.Children {
+		printTraceNode(w, child, depth+1)
+	}
+}
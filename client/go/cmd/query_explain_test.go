@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const queryExplainTraceResponse = `{
+	"root": {"fields": {"totalCount": 1}},
+	"trace": {
+		"children": [
+			{
+				"message": "Query parsed",
+				"timestamp": 0
+			},
+			{
+				"tag": "match_phase",
+				"timestamp": 3,
+				"children": [
+					{"message": "Matched 10 documents", "timestamp": 4}
+				]
+			}
+		]
+	}
+}`
+
+func TestQueryExplainSetsTraceLevel(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(queryExplainTraceResponse)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "explain", "select * from music where true"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from music where true", httpClient.LastRequest.URL.Query().Get("yql"))
+	assert.Equal(t, "5", httpClient.LastRequest.URL.Query().Get("tracelevel"))
+}
+
+func TestQueryExplainAcceptsTraceLevelFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(queryExplainTraceResponse)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "explain", "select * from music where true", "--trace-level", "9"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "9", httpClient.LastRequest.URL.Query().Get("tracelevel"))
+}
+
+func TestQueryExplainRejectsOutOfRangeTraceLevel(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"query", "explain", "select * from music where true", "--trace-level", "10"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--trace-level")
+	}
+}
+
+func TestQueryExplainPrintsIndentedTraceTree(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(queryExplainTraceResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "explain", "select * from music where true"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `[0ms] Query parsed
+[3ms] match_phase
+  [4ms] Matched 10 documents
+`, stdout.String())
+}
+
+func TestQueryExplainAcceptsYqlParameter(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(queryExplainTraceResponse)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "explain", "yql=select * from music where true", "hits=1"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from music where true", httpClient.LastRequest.URL.Query().Get("yql"))
+	assert.Equal(t, "1", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+func TestQueryExplainErrorsWithoutTrace(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 0}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "explain", "select * from music where true"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "no trace")
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// queryResponse mirrors the subset of a Vespa query response shape that matters for rendering grouping
+// results: a tree of nodes under "root", where a node's kind (group list, group, hit list, or plain hit) is
+// determined by its id prefix rather than a discriminator field.
+type queryResponse struct {
+	Root queryNode `json:"root"`
+}
+
+type queryNode struct {
+	Id        string                     `json:"id"`
+	Relevance float64                    `json:"relevance"`
+	Label     string                     `json:"label"`
+	Value     json.RawMessage            `json:"value"`
+	Fields    map[string]json.RawMessage `json:"fields"`
+	Children  []queryNode                `json:"children"`
+}
+
+func (n queryNode) kind() string {
+	switch {
+	case strings.HasPrefix(n.Id, "grouplist:"):
+		return "grouplist"
+	case strings.HasPrefix(n.Id, "group:"):
+		return "group"
+	case strings.HasPrefix(n.Id, "hitlist:"):
+		return "hitlist"
+	default:
+		return "hit"
+	}
+}
+
+// isSyntheticRoot reports whether n is the top-level "group:root:0" node Vespa always wraps grouping
+// results in, which carries no value or count of its own and shouldn't be printed.
+func (n queryNode) isSyntheticRoot() bool {
+	return n.kind() == "group" && n.Value == nil && len(n.Fields) == 0
+}
+
+// label returns a human-readable name for a grouplist node: its explicit label if set, otherwise the
+// part of its id after "grouplist:".
+func (n queryNode) label() string {
+	if n.Label != "" {
+		return n.Label
+	}
+	return strings.TrimPrefix(n.Id, "grouplist:")
+}
+
+// value returns a group node's bucket value (e.g. the artist name a "group by artist" bucket covers) as a
+// plain string, with any JSON string quoting stripped.
+func (n queryNode) value() string {
+	return strings.Trim(string(n.Value), `"`)
+}
+
+// count returns a group node's count() aggregate, or 0 if it has none.
+func (n queryNode) count() int64 {
+	raw, ok := n.Fields["count()"]
+	if !ok {
+		return 0
+	}
+	var count int64
+	json.Unmarshal(raw, &count)
+	return count
+}
+
+// hasGroupListChild reports whether n has a nested grouplist, i.e. it is not a leaf group.
+func (n queryNode) hasGroupListChild() bool {
+	for _, c := range n.Children {
+		if c.kind() == "grouplist" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryResponse parses a raw query response body into its node tree. An error here means body isn't
+// valid query JSON at all, which renderGroupTree and renderFlatGroups return to their caller.
+func parseQueryResponse(body []byte) (queryNode, error) {
+	var resp queryResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return queryNode{}, fmt.Errorf("invalid query response: %w", err)
+	}
+	return resp.Root, nil
+}
+
+// renderGroupTree writes an indented tree of body's groups and hits to w: one line per group, with its
+// value and count, followed by its nested groups or hits. Mixed responses containing both a grouping
+// section and a plain hit list render both.
+func renderGroupTree(w io.Writer, body []byte) error {
+	root, err := parseQueryResponse(body)
+	if err != nil {
+		return err
+	}
+	for _, c := range root.Children {
+		writeGroupTree(w, c, 0)
+	}
+	return nil
+}
+
+func writeGroupTree(w io.Writer, n queryNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch n.kind() {
+	case "grouplist":
+		fmt.Fprintf(w, "%s%s\n", indent, n.label())
+		for _, c := range n.Children {
+			writeGroupTree(w, c, depth+1)
+		}
+	case "group":
+		if n.isSyntheticRoot() {
+			for _, c := range n.Children {
+				writeGroupTree(w, c, depth)
+			}
+			return
+		}
+		fmt.Fprintf(w, "%s%s (%d)\n", indent, n.value(), n.count())
+		for _, c := range n.Children {
+			writeGroupTree(w, c, depth+1)
+		}
+	case "hitlist":
+		fmt.Fprintf(w, "%sHits:\n", indent)
+		for _, c := range n.Children {
+			writeGroupTree(w, c, depth+1)
+		}
+	default:
+		fmt.Fprintf(w, "%s%s\n", indent, n.Id)
+	}
+}
+
+// renderFlatGroups writes one CSV row per leaf group to w: the group's value at each nesting level,
+// followed by its count. It skips plain hits entirely, since flattening is meant for spreadsheet-style
+// analysis of grouped aggregates, not individual documents.
+func renderFlatGroups(w io.Writer, body []byte) error {
+	root, err := parseQueryResponse(body)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, c := range root.Children {
+		collectGroupRows(c, nil, &rows)
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, ","))
+	}
+	return nil
+}
+
+func collectGroupRows(n queryNode, path []string, rows *[][]string) {
+	switch n.kind() {
+	case "grouplist", "hitlist":
+		for _, c := range n.Children {
+			collectGroupRows(c, path, rows)
+		}
+	case "group":
+		if n.isSyntheticRoot() {
+			for _, c := range n.Children {
+				collectGroupRows(c, path, rows)
+			}
+			return
+		}
+		groupPath := append(append([]string{}, path...), n.value())
+		if n.hasGroupListChild() {
+			for _, c := range n.Children {
+				collectGroupRows(c, groupPath, rows)
+			}
+			return
+		}
+		*rows = append(*rows, append(groupPath, strconv.FormatInt(n.count(), 10)))
+	}
+}
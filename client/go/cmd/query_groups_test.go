@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// twoLevelGroupingResponse groups songs by artist, then by year within each artist: a two-level grouping,
+// as required for the golden tree/flatten tests below.
+const twoLevelGroupingResponse = `{
+  "root": {
+    "id": "toplevel",
+    "children": [
+      {
+        "id": "group:root:0",
+        "children": [
+          {
+            "id": "grouplist:artist",
+            "label": "artist",
+            "children": [
+              {
+                "id": "group:string:coldplay",
+                "value": "coldplay",
+                "fields": {"count()": 3},
+                "children": [
+                  {
+                    "id": "grouplist:year",
+                    "label": "year",
+                    "children": [
+                      {"id": "group:long:2011", "value": "2011", "fields": {"count()": 2}},
+                      {"id": "group:long:2015", "value": "2015", "fields": {"count()": 1}}
+                    ]
+                  }
+                ]
+              },
+              {
+                "id": "group:string:muse",
+                "value": "muse",
+                "fields": {"count()": 1},
+                "children": [
+                  {
+                    "id": "grouplist:year",
+                    "label": "year",
+                    "children": [
+                      {"id": "group:long:2009", "value": "2009", "fields": {"count()": 1}}
+                    ]
+                  }
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    ]
+  }
+}`
+
+func TestQueryGroupsRendersIndentedTree(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(twoLevelGroupingResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--groups"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `artist
+  coldplay (3)
+    year
+      2011 (2)
+      2015 (1)
+  muse (1)
+    year
+      2009 (1)
+`, stdout.String())
+}
+
+func TestQueryFlattenGroupsRendersOneRowPerLeafGroup(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(twoLevelGroupingResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--flatten-groups"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `coldplay,2011,2
+coldplay,2015,1
+muse,2009,1
+`, stdout.String())
+}
+
+func TestQueryGroupsFormatJsonPassesThrough(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(twoLevelGroupingResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--groups", "--format", "json"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"grouplist:artist"`)
+	assert.NotContains(t, stdout.String(), "coldplay (3)")
+}
+
+func TestQueryGroupsRendersMixedHitAndGroupSections(t *testing.T) {
+	mixed := `{
+  "root": {
+    "id": "toplevel",
+    "children": [
+      {
+        "id": "group:root:0",
+        "children": [
+          {
+            "id": "grouplist:artist",
+            "label": "artist",
+            "children": [
+              {"id": "group:string:coldplay", "value": "coldplay", "fields": {"count()": 1}}
+            ]
+          },
+          {
+            "id": "hitlist:default",
+            "children": [
+              {"id": "id:mynamespace:music::a", "relevance": 0.5}
+            ]
+          }
+        ]
+      }
+    ]
+  }
+}`
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(mixed)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--groups"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `artist
+  coldplay (1)
+Hits:
+  id:mynamespace:music::a
+`, stdout.String())
+}
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func TestQuerySendsParameters(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "hits=5"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "totalCount")
+	assert.Equal(t, "select * from music where true", httpClient.LastRequest.URL.Query().Get("yql"))
+	assert.Equal(t, "5", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+// TestQueryStatsPrintsTimingBreakdown verifies that --stats prints the mock client's canned timing to
+// stderr, as a short aligned block after the response, rather than replacing it.
+func TestQueryStatsPrintsTimingBreakdown(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	httpClient.Stats = util.RequestStats{DNSLookup: time.Millisecond, TimeToFirstByte: 5 * time.Millisecond, Total: 6 * time.Millisecond}
+	cli, stdout, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--stats"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "root")
+	assert.Contains(t, stderr.String(), "dns:")
+	assert.Contains(t, stderr.String(), "total:")
+}
+
+// TestQueryStatsUnderJSONFormatPrintsAStatsObject verifies that --format json turns the timing breakdown
+// into a single JSON object instead of the aligned text block, so a script can parse it like anything else
+// this CLI prints under --format json.
+func TestQueryStatsUnderJSONFormatPrintsAStatsObject(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	httpClient.Stats = util.RequestStats{Total: 6 * time.Millisecond}
+	cli, _, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"--format", "json", "query", "yql=select * from music where true", "--stats"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stderr.String(), `"stats"`)
+	assert.Contains(t, stderr.String(), `"total"`)
+}
+
+func TestQueryRejectsMalformedParameter(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"query", "not-a-key-value-pair"})
+
+	assert.NotNil(t, err)
+}
+
+func TestQuerySaveAndRun(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "hits=10", "--save", "top-hits"})
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Saved query top-hits")
+
+	err = cli.Run([]string{"query", "--run", "top-hits"})
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from music where true", httpClient.LastRequest.URL.Query().Get("yql"))
+	assert.Equal(t, "10", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+func TestQueryRunWithOverride(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"query", "yql=select * from music where true", "hits=10", "--save", "top-hits"}))
+
+	err := cli.Run([]string{"query", "--run", "top-hits", "hits=1"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+func TestQueryRunUnknownSavedQuery(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	err := cli.Run([]string{"query", "--run", "missing"})
+
+	assert.NotNil(t, err)
+}
+
+func TestQueryListSaved(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"query", "yql=select * from music where true", "--save", "top-hits"}))
+	stdout.Reset()
+
+	err := cli.Run([]string{"query", "--list-saved"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "top-hits: select * from music where true\n", stdout.String())
+}
+
+func TestQueryFallsBackToDocumentAPIForIdLookup(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		if strings.HasPrefix(request.URL.Path, "/search/") {
+			return &http.Response{StatusCode: 404, Body: http.NoBody, Header: make(http.Header)}, nil
+		}
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`{"id": "id:ns:music::a", "fields": {"title": "x"}}`)),
+			Header:     make(http.Header),
+		}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "id=id:ns:music::a", "--fallback-docapi"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "from document API")
+	assert.Contains(t, stdout.String(), "id:ns:music::a")
+}
+
+func TestQueryIdLookupHintsAtFallbackWithoutFlag(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 404, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "id=id:ns:music::a"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeQuerySearchUnavailable, codeOf(err))
+		assert.Contains(t, err.Error(), "--fallback-docapi")
+	}
+}
+
+func TestQueryWithoutIdOrSelectionHintsAtServicesXml(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 404, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeQuerySearchUnavailable, codeOf(err))
+		assert.Contains(t, err.Error(), "services.xml")
+	}
+}
+
+func TestQueryDeleteSaved(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"query", "yql=select * from music where true", "--save", "top-hits"}))
+
+	err := cli.Run([]string{"query", "--delete", "top-hits"})
+	assert.Nil(t, err)
+
+	err = cli.Run([]string{"query", "--run", "top-hits"})
+	assert.NotNil(t, err)
+}
+
+func TestQueryTuningSetsKnownParameter(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tuning", "dispatch.maxHitsPerNode=50"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "50", httpClient.LastRequest.URL.Query().Get("dispatch.maxHitsPerNode"))
+}
+
+func TestQueryTuningRejectsUnknownKey(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tuning", "dispatch.maxHitsPerNod=50"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `did you mean "dispatch.maxHitsPerNode"`)
+		assert.Contains(t, err.Error(), "--tuning-unsafe")
+	}
+}
+
+func TestQueryTuningRejectsWrongType(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tuning", "dispatch.maxHitsPerNode=notanumber"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "integer")
+	}
+}
+
+func TestQueryTuningUnsafeAllowsUnknownKey(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tuning", "some.experimental.flag=1", "--tuning-unsafe"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", httpClient.LastRequest.URL.Query().Get("some.experimental.flag"))
+}
+
+func TestQueryClusterSelectsEndpointFromVespaCliEndpoints(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[vespa.EndpointsEnv] = `{"default": "https://default.example.com", "feed": "https://feed.example.com"}`
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--cluster", "feed"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "feed.example.com", httpClient.LastRequest.URL.Host)
+}
+
+func TestQueryClusterHintsAtAvailableClustersWhenUnknown(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[vespa.EndpointsEnv] = `{"default": "https://default.example.com", "feed": "https://feed.example.com"}`
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--cluster", "bogus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "default, feed")
+	}
+}
+
+func TestQuerySaveResponseWritesFileAndConfirmsOnStderr(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, stdout, stderr := newTestCLI(httpClient)
+	file := filepath.Join(t.TempDir(), "result.json")
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--save-response", file})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", stdout.String())
+	assert.Contains(t, stderr.String(), file)
+	data, readErr := os.ReadFile(file)
+	assert.Nil(t, readErr)
+	assert.Contains(t, string(data), "totalCount")
+}
+
+func TestQuerySaveResponsePretty(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root":{"fields":{"totalCount":1}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := filepath.Join(t.TempDir(), "result.json")
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--save-response", file, "--pretty"})
+
+	assert.Nil(t, err)
+	data, readErr := os.ReadFile(file)
+	assert.Nil(t, readErr)
+	assert.Contains(t, string(data), "\n  \"root\"")
+}
+
+func TestQuerySaveResponseRefusesExistingFileWithoutForce(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := filepath.Join(t.TempDir(), "result.json")
+	assert.Nil(t, os.WriteFile(file, []byte("existing"), 0644))
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--save-response", file})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--force")
+	}
+	data, readErr := os.ReadFile(file)
+	assert.Nil(t, readErr)
+	assert.Equal(t, "existing", string(data))
+}
+
+func TestQueryStreamsRawResponseToStdout(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "totalCount")
+}
+
+func TestQueryStreamFlagBypassesGroupRendering(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"children": [{"id": "group:root:0", "children": []}]}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--groups", "--stream"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"root"`)
+}
+
+func TestQueryTensorFormatRendersTensorFieldsInHits(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"children": [
+		{"fields": {"embedding": {"type": "tensor(x[2])", "values": [1, 2]}}}
+	]}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tensor-format", "full"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "tensor(x[2]):")
+	assert.NotContains(t, stdout.String(), `"values"`)
+}
+
+func TestQueryTensorFormatRejectsUnknownValue(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tensor-format", "bogus"})
+
+	assert.NotNil(t, err)
+}
+
+func TestQuerySaveResponseOverwritesWithForce(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := filepath.Join(t.TempDir(), "result.json")
+	assert.Nil(t, os.WriteFile(file, []byte("existing"), 0644))
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--save-response", file, "--force"})
+
+	assert.Nil(t, err)
+	data, readErr := os.ReadFile(file)
+	assert.Nil(t, readErr)
+	assert.Contains(t, string(data), "totalCount")
+}
+
+func TestQueryTensorSwitchesToPostWithInputQueryKey(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"cells": [{"address": {"x": "0"}, "value": 1.0}]}`)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tensor", "embedding=" + file})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, httpClient.LastRequest.Method)
+	body, readErr := io.ReadAll(httpClient.LastRequest.Body)
+	assert.Nil(t, readErr)
+	var sent map[string]interface{}
+	assert.Nil(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, "select * from music where true", sent["yql"])
+	assert.NotNil(t, sent["input.query(embedding)"])
+}
+
+func TestQueryTensorFileMustContainValidJSON(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	file := writeTempFile(t, "not json")
+
+	err := cli.Run([]string{"query", "yql=select * from music where true", "--tensor", "embedding=" + file})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "query(embedding)")
+	}
+}
+
+func TestQueryTensorParameterArgumentTakesPrecedenceOverFile(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTempFile(t, `{"cells": [{"address": {"x": "0"}, "value": 1.0}]}`)
+
+	err := cli.Run([]string{
+		"query", "yql=select * from music where true",
+		"--tensor", "embedding=" + file,
+		"input.query(embedding)={\"cells\":[]}",
+	})
+
+	assert.Nil(t, err)
+	body, readErr := io.ReadAll(httpClient.LastRequest.Body)
+	assert.Nil(t, readErr)
+	var sent map[string]interface{}
+	assert.Nil(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, `{"cells":[]}`, sent["input.query(embedding)"])
+}
+
+func TestQueryWithoutTensorFlagUsesGet(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"root": {"fields": {"totalCount": 1}}}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"query", "yql=select * from music where true"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodGet, httpClient.LastRequest.Method)
+}
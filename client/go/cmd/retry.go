@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// pollBackoffMultiplier is the growth factor of the vespa.BackoffPolicy newPollRetrier gives every command
+// that polls a target waiting for some condition (vespa status --watch, vespa rollback --wait, vespa prod
+// status --wait): it starts at the caller's own poll interval and grows from there, capped at 20 times that
+// interval.
+const pollBackoffMultiplier = 2
+
+// pollBreakerThreshold and pollBreakerCooldown configure the vespa.CircuitBreaker newPollRetrier gives every
+// wait loop: after this many consecutive failures (e.g. the target returning 503 during a config server
+// restart), polling pauses for this long before trying again, instead of retrying at the same pace the whole
+// time.
+const (
+	pollBreakerThreshold = 5
+	pollBreakerCooldown  = 30 * time.Second
+)
+
+// funcClock adapts a command's existing injectable sleep hook (cli.sleep, rollbackSleep) to vespa.Clock, so
+// a vespa.Retrier can reuse whichever hook that command's tests already replace to skip real waits, rather
+// than introducing a second, parallel test-injection point for waiting.
+type funcClock struct {
+	sleep func(time.Duration)
+}
+
+func (c funcClock) Now() time.Time        { return time.Now() }
+func (c funcClock) Sleep(d time.Duration) { c.sleep(d) }
+
+// newPollRetrier creates a vespa.Retrier for a command's wait loop: backoff starting at interval, a circuit
+// breaker that opens after repeated failures, bounded by deadline (the zero value for no deadline), sleeping
+// through sleep (typically cli.sleep or another command's own injectable sleep hook) and logging breaker
+// state transitions through cli.debugf under --debug.
+func newPollRetrier(cli *CLI, interval time.Duration, deadline time.Time, sleep func(time.Duration)) *vespa.Retrier {
+	backoff := vespa.BackoffPolicy{Initial: interval, Max: 20 * interval, Multiplier: pollBackoffMultiplier}
+	breaker := &vespa.CircuitBreaker{Threshold: pollBreakerThreshold, Cooldown: pollBreakerCooldown}
+	retrier := vespa.NewRetrier(backoff, breaker, deadline)
+	retrier.Clock = funcClock{sleep: sleep}
+	retrier.Debug = func(msg string) { cli.debugf("%s", msg) }
+	return retrier
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// rollbackSleep is an indirection over time.Sleep, so tests can skip the real wait between --wait polls.
+var rollbackSleep = time.Sleep
+
+// maxRollbackHealthPolls bounds how many times --wait polls the query service before giving up, so a
+// service that never comes back up doesn't hang the command forever.
+const maxRollbackHealthPolls = 30
+
+func newRollbackCmd(cli *CLI) *cobra.Command {
+	var (
+		sessionID string
+		wait      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll back to a previous deployment",
+		Long: `Roll back to a previous deployment.
+
+Without --session, this finds the session that was active immediately before the current one and
+re-activates it, which is useful for quickly undoing a deploy that broke the local system. Pass --session to
+activate a specific earlier session instead (see "vespa session list" for the available session ids).
+Either way, nothing is re-uploaded: the session already exists on the config server, so only activation
+happens.
+
+Pass --wait to block until the query service responds healthy after activation, rather than returning as
+soon as the config server has accepted the new active session.
+
+It only applies to the local target: Vespa Cloud deployments are rolled back by the platform instead.`,
+		Example: `$ vespa rollback
+$ vespa rollback --session 3
+$ vespa rollback --wait`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			target, err := cli.target()
+			if err != nil {
+				return err
+			}
+			if target.Type() != "custom" {
+				return fmt.Errorf("rollback is not supported for %s targets: Vespa Cloud deployments are rolled back by the platform", target.Type())
+			}
+			service, err := deployService(cli)
+			if err != nil {
+				return err
+			}
+			id := sessionID
+			if id == "" {
+				sessions, err := vespa.ListSessions(service, cli.requestTimeout)
+				if err != nil {
+					return err
+				}
+				previous, ok := vespa.PreviousSession(sessions)
+				if !ok {
+					fmt.Fprintln(cli.Stdout, "No previous session to roll back to")
+					return nil
+				}
+				id = previous.Id
+			}
+			if err := vespa.ActivateSession(service, id, cli.requestTimeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Rolled back to session %s\n", id)
+			if wait {
+				if err := waitForQueryService(cli); err != nil {
+					return err
+				}
+				fmt.Fprintln(cli.Stdout, "Query service is ready")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&sessionID, "session", "", "Activate this session id instead of the one active immediately before the current one")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the query service to report healthy after activation")
+	return cmd
+}
+
+// waitForQueryService polls the query service's health endpoint, backing off between attempts (see
+// newPollRetrier) until it reports healthy, or maxRollbackHealthPolls is reached. A query service that keeps
+// failing trips a circuit breaker that pauses polling for a cooldown; --debug logs each state change.
+func waitForQueryService(cli *CLI) error {
+	service, err := containerService(cli, "")
+	if err != nil {
+		return err
+	}
+	retrier := newPollRetrier(cli, time.Second, time.Time{}, rollbackSleep)
+	var lastErr error
+	for i := 0; i < maxRollbackHealthPolls; i++ {
+		if lastErr = vespa.CheckHealth(service, cli.requestTimeout); lastErr == nil {
+			retrier.Success()
+			return nil
+		}
+		retrier.Failure()
+		retrier.Wait()
+	}
+	return fmt.Errorf("query service did not become healthy after %d attempts: %w", maxRollbackHealthPolls, lastErr)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func TestRollbackActivatesPreviousSession(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Method == http.MethodPut {
+			return jsonResponse("{}"), nil
+		}
+		return jsonResponse(`[
+			{"id": "1", "status": "ACTIVATED", "active": false},
+			{"id": "2", "status": "ACTIVATED", "active": false},
+			{"id": "3", "status": "ACTIVATED", "active": true}
+		]`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"rollback"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Rolled back to session 2")
+}
+
+func TestRollbackSaysWhenNoPreviousSession(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[{"id": "1", "status": "ACTIVATED", "active": true}]`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"rollback"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "No previous session to roll back to")
+}
+
+func TestRollbackActivatesSpecifiedSession(t *testing.T) {
+	var activated string
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Method == http.MethodPut {
+			activated = req.URL.Path
+			return jsonResponse("{}"), nil
+		}
+		return jsonResponse(`[]`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"rollback", "--session", "7"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Rolled back to session 7")
+	assert.Contains(t, activated, "7")
+}
+
+func TestRollbackWaitPollsUntilQueryServiceIsHealthy(t *testing.T) {
+	rollbackSleep = func(time.Duration) {}
+	defer func() { rollbackSleep = time.Sleep }()
+	var healthChecks int
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		switch {
+		case req.Method == http.MethodPut:
+			return jsonResponse("{}"), nil
+		case req.URL.Path == "/state/v1/health":
+			healthChecks++
+			if healthChecks < 2 {
+				return &http.Response{StatusCode: 503, Body: noopCloser{strings.NewReader("")}, Header: make(http.Header)}, nil
+			}
+			return jsonResponse("{}"), nil
+		default:
+			return jsonResponse(`[{"id": "1", "status": "ACTIVATED", "active": true}]`), nil
+		}
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"rollback", "--session", "2", "--wait"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, healthChecks)
+	assert.Contains(t, stdout.String(), "Query service is ready")
+}
+
+func TestRollbackRefusesCloudTarget(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[vespa.EndpointsEnv] = `{"default": "https://default.example.com"}`
+
+	err := cli.Run([]string{"rollback"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "rolled back by the platform")
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+func newRootCmd(cli *CLI) (*cobra.Command, error) {
+	rootCmd := &cobra.Command{
+		Use:           "vespa command-name",
+		Short:         "The command-line tool for Vespa.ai",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			autoMigrateConfig(cli, cmd)
+			cli.applyInsecureEnv()
+		},
+	}
+	rootCmd.PersistentFlags().DurationVar(&cli.requestTimeout, "request-timeout", cli.requestTimeout, "Timeout for a single HTTP request against a Vespa endpoint, e.g. \"10s\" or \"2m\"")
+	rootCmd.PersistentFlags().StringVar(&cli.colorMode, "color", cli.colorMode, `Whether to colorize output. Must be "auto", "always" or "never"`)
+	rootCmd.PersistentFlags().StringVar(&cli.outputFormat, "format", cli.outputFormat, `Output format for errors and status. Must be "human" or "json"`)
+	rootCmd.PersistentFlags().StringVar(&cli.applicationOverride, applicationFlag, "", "The application to use, overriding config. May be given as tenant.application if --tenant is omitted")
+	rootCmd.PersistentFlags().StringVar(&cli.tenantOverride, tenantFlag, "", "The tenant to use, overriding config. Must match --application's tenant prefix, if it has one")
+	rootCmd.PersistentFlags().StringVar(&cli.instanceOverride, instanceFlag, "", "The application instance to use, overriding config")
+	rootCmd.PersistentFlags().StringVar(&cli.targetOverride, targetFlag, "", "The target to deploy to, overriding config")
+	rootCmd.PersistentFlags().StringVar(&cli.deployTargetOverride, deployTargetFlag, "", "The config server/deploy target, overriding config. Defaults to --target, for a single-port or reverse-proxied self-hosted setup. Accepts a comma-separated list of URLs for a multi-config-server installation; they are tried in order on a connection failure, sticking to whichever one last succeeded")
+	rootCmd.PersistentFlags().StringVar(&cli.tlsOptions.CertificateFile, "cert", "", "Client certificate to use for data-plane requests against a target")
+	rootCmd.PersistentFlags().StringVar(&cli.tlsOptions.PrivateKeyFile, "private-key", "", "Private key matching --cert")
+	rootCmd.PersistentFlags().StringVar(&cli.caCertFileOverride, "ca-cert", "", "CA certificate bundle (PEM) to additionally trust for data-plane requests against a custom target, equivalent to setting VESPA_CLI_CA_CERT")
+	rootCmd.PersistentFlags().StringVar(&cli.apiOptions.TLSOptions.CertificateFile, "api-cert-file", "", "Client certificate to use for Vespa Cloud control-plane API requests")
+	rootCmd.PersistentFlags().StringVar(&cli.apiOptions.TLSOptions.PrivateKeyFile, "api-private-key-file", "", "Private key matching --api-cert-file")
+	rootCmd.PersistentFlags().StringArrayVar(&cli.headerOverrides, "header", nil, `Extra HTTP header to send with every data-plane request, as "Name: value". Repeatable; overrides the "headers" config option`)
+	rootCmd.PersistentFlags().BoolVar(&cli.insecureOverride, "insecure", false, "Disable TLS certificate verification for data-plane requests against a loopback or private-network target, equivalent to setting VESPA_CLI_TLS_INSECURE=1. Requires --insecure-allow-public for any other target, and is never allowed for a Vespa Cloud target")
+	rootCmd.PersistentFlags().BoolVar(&cli.insecureAllowPublicOverride, "insecure-allow-public", false, "Allow --insecure to also skip certificate verification against a target outside the loopback/private-network range")
+	rootCmd.PersistentFlags().BoolVar(&cli.quietOverride, "quiet", false, "Never ask an interactive question; assume a negative answer instead")
+	rootCmd.PersistentFlags().BoolVar(&cli.debugOverride, "debug", false, "Print circuit breaker state transitions to stderr while a command retries or waits")
+	rootCmd.AddCommand(newDocumentCmd(cli))
+	rootCmd.AddCommand(newQueryCmd(cli))
+	rootCmd.AddCommand(newSearchCmd(cli))
+	rootCmd.AddCommand(newTestCmd(cli))
+	rootCmd.AddCommand(newVersionCmd(cli))
+	rootCmd.AddCommand(newDeployCmd(cli))
+	rootCmd.AddCommand(newSessionCmd(cli))
+	rootCmd.AddCommand(newRollbackCmd(cli))
+	rootCmd.AddCommand(newProdCmd(cli))
+	rootCmd.AddCommand(newApplicationCmd(cli))
+	rootCmd.AddCommand(newTenantCmd(cli))
+	rootCmd.AddCommand(newAuthCmd(cli))
+	rootCmd.AddCommand(newFeedCmd(cli))
+	rootCmd.AddCommand(newConfigCmd(cli))
+	rootCmd.AddCommand(newSupportCmd(cli))
+	rootCmd.AddCommand(newLogCmd(cli))
+	rootCmd.AddCommand(newLogfmtCmd(cli))
+	rootCmd.AddCommand(newCurlCmd(cli))
+	rootCmd.AddCommand(newStatusCmd(cli))
+	rootCmd.AddCommand(newCloneCmd(cli))
+	return rootCmd, nil
+}
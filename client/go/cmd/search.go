@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newSearchCmd(cli *CLI) *cobra.Command {
+	var (
+		hits    int
+		pkgPath string
+	)
+	cmd := &cobra.Command{
+		Use:   "search term ...",
+		Short: "Search for documents matching the given terms",
+		Long: `Search for documents matching the given terms, without having to write YQL.
+
+The terms are combined into a weakAnd query (type=weakAnd) over the default fieldset, matched with
+userQuery() against "select * from sources * where userQuery()" — the same query form Vespa's own
+documentation recommends for free-text search. --hits sets how many results are requested (default 10).
+
+Each hit is printed as one line: its rank, relevance score, document id, and a "title-ish" field picked
+from its summary fields (a field literally named "title" if present, otherwise the first string-valued
+field). --application-package points at a local application package directory; if given, its schema files
+are scanned for a summary string field with a title-like name (title, name, heading or subject) to prefer
+over the per-hit guess. This is a best-effort text scan, not a real schema parser, so an unusual schema may
+not be detected. When colors are on (see --color), the matched terms are highlighted in the printed field.
+
+This command covers the common "just search for some words" case; anything more specific — explicit YQL,
+grouping, tuning, saved queries — needs vespa query.`,
+		Example: `$ vespa search coldplay
+$ vespa search "head full of dreams" --hits 5`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runSearch(cli, args, hits, pkgPath)
+		},
+	}
+	cmd.Flags().IntVar(&hits, "hits", 10, "Number of hits to request")
+	cmd.Flags().StringVar(&cli.clusterOverride, "cluster", "", "Container cluster to query, required if the application has more than one")
+	cmd.Flags().StringVar(&pkgPath, "application-package", "", "Path to a local application package, scanned for a title field to prefer when rendering hits")
+	return cmd
+}
+
+func runSearch(cli *CLI, terms []string, hits int, pkgPath string) error {
+	service, err := containerService(cli, cli.clusterOverride)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("yql", "select * from sources * where userQuery()")
+	params.Set("query", strings.Join(terms, " "))
+	params.Set("type", "weakAnd")
+	params.Set("hits", fmt.Sprintf("%d", hits))
+	result, err := vespa.Query(service, params, cli.requestTimeout)
+	if err != nil {
+		return err
+	}
+	titleField := ""
+	if pkgPath != "" {
+		titleField = detectTitleField(pkgPath)
+	}
+	return renderSearchResult(cli, result.Body, terms, titleField)
+}
+
+// searchResponse is the subset of a /search/ response that vespa search renders: enough to print one line
+// per hit, ignoring groupings, errors and anything else a raw vespa query would need to handle.
+type searchResponse struct {
+	Root struct {
+		Children []struct {
+			ID        string                 `json:"id"`
+			Relevance float64                `json:"relevance"`
+			Fields    map[string]interface{} `json:"fields"`
+		} `json:"children"`
+	} `json:"root"`
+}
+
+func renderSearchResult(cli *CLI, body []byte, terms []string, titleField string) error {
+	var resp searchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid search response: %w", err)
+	}
+	color := useColor(cli, cli.stdoutIsTerminal)
+	for i, hit := range resp.Root.Children {
+		title := hitTitle(hit.Fields, titleField)
+		if color {
+			title = highlightTerms(title, terms)
+		}
+		fmt.Fprintf(cli.Stdout, "%d. %.4f  %s  %s\n", i+1, hit.Relevance, hit.ID, title)
+	}
+	return nil
+}
+
+// hitTitle picks a title-ish field to print for a hit: preferred, if set and present, then a field
+// literally named "title" (case-insensitively), then the first string-valued field in a stable order,
+// falling back to an empty string if none of its fields are strings.
+func hitTitle(fields map[string]interface{}, preferred string) string {
+	if preferred != "" {
+		if v, ok := fields[preferred].(string); ok {
+			return v
+		}
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, "title") {
+			if v, ok := fields[name].(string); ok {
+				return v
+			}
+		}
+	}
+	for _, name := range names {
+		if v, ok := fields[name].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// highlightTerms wraps each case-insensitive occurrence of any of terms in s with bold ANSI codes.
+func highlightTerms(s string, terms []string) string {
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		pattern, err := regexp.Compile("(?i)" + regexp.QuoteMeta(term))
+		if err != nil {
+			continue
+		}
+		s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+			return "\033[1m" + match + "\033[0m"
+		})
+	}
+	return s
+}
+
+// titleLikeFieldNames lists summary field names worth preferring, in priority order, when more than one
+// candidate is found in a schema.
+var titleLikeFieldNames = []string{"title", "name", "heading", "subject"}
+
+var stringFieldPattern = regexp.MustCompile(`(?i)field\s+(\w+)\s+type\s+string\b`)
+
+// detectTitleField does a best-effort scan of every *.sd file under pkgPath for string fields included in
+// the document summary (an "indexing" clause containing "summary"), returning the most title-like of them —
+// see titleLikeFieldNames — or the first one found if none match by name. This is a plain text scan rather
+// than a real schema parser — the application package format has no existing parser in this tree to build
+// on — so an unusually formatted schema may go undetected, in which case hitTitle falls back to its per-hit
+// guess.
+func detectTitleField(pkgPath string) string {
+	var candidates []string
+	_ = filepath.Walk(pkgPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".sd") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		candidates = append(candidates, summaryStringFields(string(data))...)
+		return nil
+	})
+	for _, preferred := range titleLikeFieldNames {
+		for _, name := range candidates {
+			if strings.EqualFold(name, preferred) {
+				return name
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// summaryStringFields returns the names of every string field in schema whose body (up to the next field
+// declaration, or the end of the text) mentions "summary", the usual way a field is marked for inclusion in
+// the document summary returned with a hit.
+func summaryStringFields(schema string) []string {
+	matches := stringFieldPattern.FindAllStringSubmatchIndex(schema, -1)
+	var names []string
+	for i, m := range matches {
+		end := len(schema)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		body := schema[m[1]:end]
+		if strings.Contains(strings.ToLower(body), "summary") {
+			names = append(names, schema[m[2]:m[3]])
+		}
+	}
+	return names
+}
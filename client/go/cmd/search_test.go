@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const searchTestResponse = `{"root": {"children": [
+	{"id": "id:music:music::a", "relevance": 0.9876, "fields": {"title": "A Head Full of Dreams", "artist": "Coldplay"}},
+	{"id": "id:music:music::b", "relevance": 0.5432, "fields": {"artist": "Coldplay", "album": "Parachutes"}}
+]}}`
+
+func TestSearchSendsWeakAndQuery(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(searchTestResponse)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"search", "coldplay", "dreams"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from sources * where userQuery()", httpClient.LastRequest.URL.Query().Get("yql"))
+	assert.Equal(t, "coldplay dreams", httpClient.LastRequest.URL.Query().Get("query"))
+	assert.Equal(t, "weakAnd", httpClient.LastRequest.URL.Query().Get("type"))
+	assert.Equal(t, "10", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+func TestSearchHitsFlagOverridesDefault(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(searchTestResponse)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"search", "--hits", "5", "coldplay"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "5", httpClient.LastRequest.URL.Query().Get("hits"))
+}
+
+func TestSearchPrintsOneLinePerHitWithTitleField(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(searchTestResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"search", "coldplay"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "1. 0.9876  id:music:music::a  A Head Full of Dreams")
+	// The second hit has no "title" field, so it falls back to the first string field in sorted order.
+	assert.Contains(t, stdout.String(), "2. 0.5432  id:music:music::b  Parachutes")
+}
+
+func TestSearchHighlightsMatchedTermsWhenColorIsOn(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(searchTestResponse)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.colorMode = "always"
+
+	err := cli.Run([]string{"search", "dreams"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "\033[1mDreams\033[0m")
+}
+
+func TestSearchApplicationPackageTitleFieldTakesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "music.sd"), []byte(`
+schema music {
+	document music {
+		field heading type string {
+			indexing: summary | index
+		}
+	}
+}`), 0644))
+	// "artist" sorts before "heading" alphabetically, so without the schema hint hitTitle would pick it.
+	response := `{"root": {"children": [
+		{"id": "id:music:music::c", "relevance": 0.1234, "fields": {"artist": "Coldplay", "heading": "Yellow"}}
+	]}}`
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(response)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"search", "--application-package", dir, "coldplay"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "1. 0.1234  id:music:music::c  Yellow")
+}
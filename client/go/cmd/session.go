@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newSessionCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Inspect and manage deployment sessions on a config server",
+	}
+	cmd.AddCommand(newSessionListCmd(cli))
+	cmd.AddCommand(newSessionStatusCmd(cli))
+	cmd.AddCommand(newSessionDeleteCmd(cli))
+	return cmd
+}
+
+func newSessionListCmd(cli *CLI) *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List sessions known to the config server",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			service, err := deployService(cli)
+			if err != nil {
+				return err
+			}
+			sessions, err := vespa.ListSessions(service, cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return json.NewEncoder(cli.Stdout).Encode(sessions)
+			}
+			for _, s := range sessions {
+				fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\n", s.Id, s.Status, s.CreatedAt)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print sessions as JSON")
+	return cmd
+}
+
+func newSessionStatusCmd(cli *CLI) *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "status session-id",
+		Short: "Show the prepare status of a session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			service, err := deployService(cli)
+			if err != nil {
+				return err
+			}
+			session, err := vespa.SessionStatus(service, args[0], cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				return json.NewEncoder(cli.Stdout).Encode(session)
+			}
+			fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\tactive=%t\n", session.Id, session.Status, session.CreatedAt, session.Active)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print session status as JSON")
+	return cmd
+}
+
+func newSessionDeleteCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete session-id",
+		Short: "Delete an unused session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			service, err := deployService(cli)
+			if err != nil {
+				return err
+			}
+			if err := vespa.DeleteSession(service, args[0], cli.requestTimeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Deleted session %s\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
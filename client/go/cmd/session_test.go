@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestSessionDeleteGuardsActiveSession(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"id": "2", "status": "ACTIVATED", "active": true}`)}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"session", "delete", "2"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "active")
+	}
+}
+
+func TestSessionDeleteAllowsInactiveSession(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.Method == http.MethodDelete {
+			return jsonResponse("{}"), nil
+		}
+		return jsonResponse(`{"id": "1", "status": "PREPARED", "active": false}`), nil
+	}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"session", "delete", "1"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "Deleted session 1")
+}
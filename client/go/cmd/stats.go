@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// statLine is a single named timing, printed by printStats.
+type statLine struct {
+	label string
+	value time.Duration
+}
+
+// printRequestStats prints the DNS/connect/TLS/time-to-first-byte/total breakdown of the most recent
+// request service sent, for a command's --stats flag. It is a no-op if service's underlying HTTPClient
+// doesn't capture timing, e.g. the mock client used in tests, unless it was given canned stats to return.
+func printRequestStats(cli *CLI, service *vespa.Service) {
+	stats, ok := service.LastRequestStats()
+	if !ok {
+		return
+	}
+	printStats(cli, []statLine{
+		{"dns", stats.DNSLookup},
+		{"connect", stats.Connect},
+		{"tls", stats.TLSHandshake},
+		{"ttfb", stats.TimeToFirstByte},
+		{"total", stats.Total},
+	})
+}
+
+// printElapsed prints a single round-trip timing, for a command that measures its own elapsed time around
+// an operation instead of reading it off a vespa.Service, e.g. a document operation.
+func printElapsed(cli *CLI, elapsed time.Duration) {
+	printStats(cli, []statLine{{"round-trip", elapsed}})
+}
+
+// printStats writes lines to cli.Stderr as a short aligned block, after the command's normal output, or as
+// a single JSON object when --format json is set, so a script parses timing the same way it parses
+// everything else this CLI prints under --format json.
+func printStats(cli *CLI, lines []statLine) {
+	if cli.outputFormat == "json" {
+		stats := make(map[string]float64, len(lines))
+		for _, l := range lines {
+			stats[l.label] = l.value.Seconds()
+		}
+		_ = json.NewEncoder(cli.Stderr).Encode(map[string]interface{}{"stats": stats})
+		return
+	}
+	width := 0
+	for _, l := range lines {
+		if len(l.label) > width {
+			width = len(l.label)
+		}
+	}
+	for _, l := range lines {
+		fmt.Fprintf(cli.Stderr, "%-*s %s\n", width+1, l.label+":", l.value.Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// defaultWatchInterval is used by --watch when given without an explicit interval.
+const defaultWatchInterval = 5 * time.Second
+
+func newStatusCmd(cli *CLI) *cobra.Command {
+	var (
+		cluster       string
+		allInstances  bool
+		verbose       bool
+		watchInterval time.Duration
+		waitTimeout   time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether a deployed application is ready to serve traffic",
+		Long: `Show whether a deployed application is ready to serve traffic, by polling its container cluster's
+health endpoint. Alongside readiness, this also prints the data-plane endpoint URL(s) resolved for each
+container cluster, and, where it can be determined from locally configured credentials, the authentication
+method they expect: "mTLS" if a data-plane certificate is set up, or "token (<value>)" if the
+request-signer config option is set to <value> instead. Against a Vespa Cloud target (VESPA_CLI_ENDPOINTS set) there may be one
+URL per cluster or several for failover; against a self-hosted target there is always a single URL.
+
+An application with several instances otherwise needs this command run once per instance: --all-instances
+(equivalently, --instance '*') iterates over every instance in the comma-separated instance config option
+instead, printing one line per instance. The command exits non-zero if any checked instance isn't ready.
+
+Pass --verbose to also query the cluster controller's service view and print each node's up/down state and
+config generation, which is more useful than the single summary line when the container health check alone
+doesn't explain why an instance isn't ready. Pass --format json (the global flag) to print one JSON object
+per instance instead of tab-separated lines.
+
+Pass --watch to repeatedly re-check a single instance's health, rather than a one-shot check, until it
+reports ready. The interval defaults to 5s; --watch=2s polls more often (note the "=": a bare --watch takes
+no argument, so --watch 2s would parse "2s" as an unexpected positional argument instead). When stdout is a
+terminal the status line is overwritten in place with \r rather than scrolling; otherwise (e.g. piped to a
+file) each attempt is printed as its own line. Repeated failures back off from the --watch interval instead
+of retrying at a fixed pace, pausing altogether for a while after several in a row (the global --debug flag
+logs this); --wait bounds how long --watch keeps trying in total before giving up and exiting 1, by default
+it keeps trying indefinitely. --watch is not supported together with --all-instances or --verbose.`,
+		Example: `$ vespa status
+$ vespa status --cluster mycluster
+$ vespa status --all-instances
+$ vespa status --instance '*'
+$ vespa status --verbose
+$ vespa status --format json
+$ vespa status --watch
+$ vespa status --watch=2s --wait=1m`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := newConfig(cli)
+			if err != nil {
+				return err
+			}
+			instances := []string{config.get(instanceFlag)}
+			if instances[0] == "" {
+				instances[0] = "default"
+			}
+			if allInstances || cli.instanceOverride == "*" {
+				instances = config.instances()
+			}
+			if watchInterval > 0 {
+				if allInstances || cli.instanceOverride == "*" || verbose {
+					return fmt.Errorf("--watch cannot be combined with --all-instances or --verbose")
+				}
+				return watchStatus(cli, config, instances[0], cluster, watchInterval, waitTimeout)
+			}
+			notReady := 0
+			for _, instance := range instances {
+				if err := printInstanceStatus(cli, config, instance, cluster, verbose); err != nil {
+					notReady++
+				}
+			}
+			if notReady > 0 {
+				return errCLI(ErrCodeStatusNotReady, "%d of %d instances are not ready", notReady, len(instances))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "Check this container cluster instead of the default one")
+	cmd.Flags().BoolVar(&allInstances, "all-instances", false, "Check every instance listed in the instance config option")
+	cmd.Flags().DurationVar(&watchInterval, "watch", 0, "Re-check readiness at this interval until ready, instead of a one-shot check. Defaults to 5s when given without a value")
+	cmd.Flags().Lookup("watch").NoOptDefVal = defaultWatchInterval.String()
+	cmd.Flags().DurationVar(&waitTimeout, "wait", 0, "Give up --watch after this long and exit 1 (default: no timeout)")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Also print per-node health from the cluster controller")
+	cmd.AddCommand(newStatusFeedBlockCmd(cli))
+	cmd.AddCommand(newStatusAuthCmd(cli))
+	return cmd
+}
+
+// endpointInfo describes a single container cluster's data-plane endpoint(s), for both the human-readable
+// and --format json renderings of `vespa status`.
+type endpointInfo struct {
+	Cluster string   `json:"cluster"`
+	URLs    []string `json:"urls"`
+	Auth    string   `json:"auth,omitempty"`
+}
+
+// instanceStatus is the --format json shape of one line of `vespa status` output.
+type instanceStatus struct {
+	Instance  string         `json:"instance"`
+	Ready     bool           `json:"ready"`
+	Error     string         `json:"error,omitempty"`
+	Endpoints []endpointInfo `json:"endpoints,omitempty"`
+}
+
+// printInstanceStatus checks instance's container cluster, resolves its data-plane endpoint(s), and prints
+// either a tab-separated summary or (with --format json) a JSON object, returning the health check's error
+// (also already reported) so the caller can count failures. With verbose, it additionally prints the
+// cluster controller's per-node state, best-effort: a failure fetching it is reported but doesn't itself
+// count instance as not ready, since it's diagnostic information on top of the already-determined
+// readiness.
+func printInstanceStatus(cli *CLI, config *Config, instance, cluster string, verbose bool) error {
+	target, targetErr := resolveStatusTarget(cli, config, instance)
+	var healthErr error
+	var endpoints []endpointInfo
+	var endpointsErr error
+	if targetErr != nil {
+		healthErr = targetErr
+	} else {
+		service, err := target.ContainerService(cluster)
+		if err != nil {
+			healthErr = err
+		} else {
+			_, _, healthErr = checkStatus(service, cli.requestTimeout)
+		}
+		endpoints, endpointsErr = resolveEndpointInfo(cli, config, target, cluster)
+	}
+	if cli.outputFormat == "json" {
+		printInstanceStatusJSON(cli, instance, healthErr, endpoints)
+	} else {
+		printInstanceStatusHuman(cli, instance, healthErr, endpoints, endpointsErr)
+	}
+	if verbose {
+		printClusterNodeStatus(cli, config, instance, cluster)
+	}
+	return healthErr
+}
+
+func printInstanceStatusJSON(cli *CLI, instance string, healthErr error, endpoints []endpointInfo) {
+	status := instanceStatus{Instance: instance, Ready: healthErr == nil, Endpoints: endpoints}
+	if healthErr != nil {
+		status.Error = healthErr.Error()
+	}
+	_ = json.NewEncoder(cli.Stdout).Encode(status)
+}
+
+func printInstanceStatusHuman(cli *CLI, instance string, healthErr error, endpoints []endpointInfo, endpointsErr error) {
+	if healthErr != nil {
+		fmt.Fprintf(cli.Stdout, "%s\tnot ready: %s\n", instance, healthErr)
+	} else {
+		fmt.Fprintf(cli.Stdout, "%s\tready\n", instance)
+	}
+	if endpointsErr != nil {
+		fmt.Fprintf(cli.Stdout, "%s\tcould not resolve endpoints: %s\n", instance, endpointsErr)
+		return
+	}
+	for _, e := range endpoints {
+		line := fmt.Sprintf("%s\tendpoint\t%s\t%s", instance, e.Cluster, strings.Join(e.URLs, ","))
+		if e.Auth != "" {
+			line += "\t" + e.Auth
+		}
+		fmt.Fprintln(cli.Stdout, line)
+	}
+}
+
+// checkStatus checks svc's health, returning whether it's ready, a short human-readable status ("ready" or
+// "not ready: <reason>") suitable for direct printing by watchStatus, and the underlying error, if any, for
+// a caller that wants to report or inspect it further.
+func checkStatus(svc *vespa.Service, timeout time.Duration) (ready bool, msg string, err error) {
+	if err := vespa.CheckHealth(svc, timeout); err != nil {
+		return false, fmt.Sprintf("not ready: %s", err), err
+	}
+	return true, "ready", nil
+}
+
+// watchStatus repeatedly checks instance's container cluster health, starting at interval and backing off
+// from there (see newPollRetrier) until checkStatus reports ready, printing each attempt. A target that
+// keeps failing (e.g. a config server mid-restart returning 503) trips a circuit breaker that pauses polling
+// for a cooldown rather than retrying at the same pace throughout; --debug logs each state change. When
+// stdout is a terminal the line is overwritten in place with \r, the same as a progress bar, rather than
+// scrolling; otherwise each attempt gets its own line, since \r is meaningless once redirected to a file. If
+// timeout is positive and no attempt has succeeded by then, watching stops and a non-nil error is returned
+// so the command exits 1; zero means watch forever.
+func watchStatus(cli *CLI, config *Config, instance, cluster string, interval, timeout time.Duration) error {
+	target, err := resolveStatusTarget(cli, config, instance)
+	if err != nil {
+		return err
+	}
+	service, err := target.ContainerService(cluster)
+	if err != nil {
+		return err
+	}
+	overwrite := isTerminal(cli.Stdout)
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	retrier := newPollRetrier(cli, interval, deadline, cli.sleep)
+	for {
+		ready, msg, _ := checkStatus(service, cli.requestTimeout)
+		line := fmt.Sprintf("%s\t%s", instance, msg)
+		if overwrite {
+			fmt.Fprintf(cli.Stdout, "\r%s", line)
+		} else {
+			fmt.Fprintln(cli.Stdout, line)
+		}
+		if ready {
+			retrier.Success()
+			if overwrite {
+				fmt.Fprintln(cli.Stdout)
+			}
+			return nil
+		}
+		retrier.Failure()
+		if !retrier.Wait() {
+			if overwrite {
+				fmt.Fprintln(cli.Stdout)
+			}
+			return errCLI(ErrCodeStatusNotReady, "%s did not become ready within %s", instance, timeout)
+		}
+	}
+}
+
+// resolveStatusTarget resolves the vespa.Target to check instance against: a Vespa Cloud target if
+// VESPA_CLI_ENDPOINTS is set, otherwise a self-hosted custom target, mirroring CLI.target's cloud/custom
+// choice but for a specific instance rather than the one resolved from config, the same way
+// createCloudTargetForInstance is to createCloudTarget.
+func resolveStatusTarget(cli *CLI, config *Config, instance string) (vespa.Target, error) {
+	endpoints, err := cli.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) > 0 {
+		return cli.createCloudTargetForInstance(config, instance)
+	}
+	return cli.customTarget(config)
+}
+
+// resolveEndpointInfo resolves every cluster endpoint target exposes, optionally narrowed to a single
+// cluster, annotated with the data-plane authentication method inferred from cli's locally configured
+// credentials. A Vespa Cloud target (*vespa.CloudTarget) may have several clusters, each possibly served by
+// more than one URL for failover; a self-hosted custom target always has exactly one cluster and URL.
+func resolveEndpointInfo(cli *CLI, config *Config, target vespa.Target, cluster string) ([]endpointInfo, error) {
+	auth := dataPlaneAuthMethod(cli, config)
+	if cloudTarget, ok := target.(*vespa.CloudTarget); ok {
+		if len(cloudTarget.Endpoints) == 0 {
+			return nil, fmt.Errorf("no data-plane endpoints configured, set %s", vespa.EndpointsEnv)
+		}
+		var infos []endpointInfo
+		for _, name := range cloudTarget.Endpoints.Names() {
+			if cluster != "" && name != cluster {
+				continue
+			}
+			infos = append(infos, endpointInfo{Cluster: name, URLs: cloudTarget.Endpoints[name], Auth: auth})
+		}
+		return infos, nil
+	}
+	service, err := target.ContainerService(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return []endpointInfo{{Cluster: "container", URLs: []string{service.BaseURL}, Auth: auth}}, nil
+}
+
+// dataPlaneAuthMethod gives a best-effort guess at the authentication method a resolved endpoint expects,
+// based on what's configured locally: there is no endpoint-discovery API in reach here that reports this
+// authoritatively. A data-plane certificate being set up is read as "mTLS", the common case for a Vespa
+// Cloud application; otherwise, if the "request-signer" config option is set, "token (<value>)" is
+// reported with that option's value. Neither
+// configured is reported as the empty string, omitted entirely from output, rather than guessed at further.
+func dataPlaneAuthMethod(cli *CLI, config *Config) string {
+	if cli.tlsOptions.CertificateFile != "" {
+		return "mTLS"
+	}
+	if fileExists(dataPlaneKeyPath(cli)) && fileExists(dataPlaneCertPath(cli)) {
+		return "mTLS"
+	}
+	if signer := config.get(requestSignerFlag); signer != "" {
+		return fmt.Sprintf("token (%s)", signer)
+	}
+	return ""
+}
+
+// printClusterNodeStatus prints one line per node reported by instance's cluster controller, naming its
+// up/down state and config generation. Any error fetching it is printed as a single line rather than
+// returned, since it's supplementary to the readiness check printInstanceStatus already reported.
+func printClusterNodeStatus(cli *CLI, config *Config, instance, cluster string) {
+	target, err := cli.createCloudTargetForInstance(config, instance)
+	if err != nil {
+		fmt.Fprintf(cli.Stdout, "%s\tcould not fetch node status: %s\n", instance, err)
+		return
+	}
+	service, err := target.DeployService()
+	if err != nil {
+		fmt.Fprintf(cli.Stdout, "%s\tcould not fetch node status: %s\n", instance, err)
+		return
+	}
+	nodes, err := vespa.FetchClusterStatus(service, cli.requestTimeout)
+	if err != nil {
+		fmt.Fprintf(cli.Stdout, "%s\tcould not fetch node status: %s\n", instance, err)
+		return
+	}
+	for _, n := range nodes {
+		if cluster != "" && n.Cluster != cluster {
+			continue
+		}
+		fmt.Fprintf(cli.Stdout, "%s\t%s\t%s\t%s\tgeneration %d\n", instance, n.Cluster, n.Hostname, n.State, n.Generation)
+	}
+}
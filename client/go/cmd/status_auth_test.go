@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func TestStatusAuthShowPinsPrintsEndpointFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	cli, stdout, _ := newTestCLI(nil)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["` + server.URL + `"]}`
+
+	err := cli.Run([]string{"status", "auth", "--show-pins"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "container\t"+server.URL+"\t"+vespa.SPKIPin(server.Certificate())+"\n")
+}
+
+func TestStatusAuthWithoutShowPinsFails(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"status", "auth"})
+
+	assert.NotNil(t, err)
+}
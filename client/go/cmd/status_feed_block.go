@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// feedBlockConfigHint maps a feed-blocking resource name to the application package config option that
+// raises its limit, so the command can point users at a fix instead of just the symptom.
+var feedBlockConfigHint = map[string]string{
+	"disk":      "content.disk.resource-limit-factor",
+	"memory":    "content.memory.resource-limit-factor",
+	"attribute": "content.memory.resource-limit-factor",
+}
+
+func newStatusFeedBlockCmd(cli *CLI) *cobra.Command {
+	var cluster string
+	cmd := &cobra.Command{
+		Use:   "feed-block",
+		Short: "Show whether a content cluster is blocking writes due to exhausted resources",
+		Long: `Show whether a content cluster is blocking writes due to exhausted resources, by querying its
+cluster controller for the resource usage (memory, disk, attribute address space) of every node and
+comparing it against the configured limits.`,
+		Example: `$ vespa status feed-block
+$ vespa status feed-block --cluster music`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			target, err := cli.createCloudTarget()
+			if err != nil {
+				return err
+			}
+			service, err := target.DeployService()
+			if err != nil {
+				return err
+			}
+			nodes, err := vespa.FetchFeedBlockStatus(service, cli.requestTimeout)
+			if err != nil {
+				return err
+			}
+			blocked := 0
+			for _, n := range nodes {
+				if cluster != "" && n.Cluster != cluster {
+					continue
+				}
+				if !n.Blocked {
+					fmt.Fprintf(cli.Stdout, "%s\t%s\tok\n", n.Cluster, n.Hostname)
+					continue
+				}
+				blocked++
+				for _, r := range n.ResourcesAboveLimit() {
+					fmt.Fprintf(cli.Stdout, "%s\t%s\tblocked: %s usage %.2f exceeds limit %.2f, consider raising %s\n",
+						n.Cluster, n.Hostname, r.Name, r.Usage, r.Limit, feedBlockConfigHintFor(r))
+				}
+			}
+			if blocked > 0 {
+				return errCLI(ErrCodeStatusFeedBlocked, "%d node(s) are blocking feed", blocked)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cluster, "cluster", "", "Only check this content cluster")
+	return cmd
+}
+
+// feedBlockConfigHintFor returns the config option suggested for resolving r, falling back to its own
+// reported config field when the resource name isn't one of the well-known ones.
+func feedBlockConfigHintFor(r vespa.FeedBlockResource) string {
+	if hint, ok := feedBlockConfigHint[r.Name]; ok {
+		return hint
+	}
+	return r.Config
+}
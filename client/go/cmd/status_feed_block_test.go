@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestStatusFeedBlockReportsBlockedClusterAndExitsNonZero(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"nodes": [
+		{"clusterId": "content", "hostname": "node-1", "feedBlocked": true, "resources": [
+			{"name": "disk", "usage": 0.92, "limit": 0.9, "config": "content.disk.resource-limit-factor"}
+		]}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "feed-block"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeStatusFeedBlocked, codeOf(err))
+	}
+	assert.Contains(t, stdout.String(), "blocked: disk usage 0.92 exceeds limit 0.90")
+	assert.Contains(t, stdout.String(), "content.disk.resource-limit-factor")
+}
+
+func TestStatusFeedBlockReportsHealthyCluster(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"nodes": [
+		{"clusterId": "content", "hostname": "node-1", "feedBlocked": false, "resources": []}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "feed-block"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "content\tnode-1\tok")
+}
+
+func TestStatusFeedBlockFiltersByCluster(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"nodes": [
+		{"clusterId": "content", "hostname": "node-1", "feedBlocked": true, "resources": [
+			{"name": "disk", "usage": 0.95, "limit": 0.9, "config": "content.disk.resource-limit-factor"}
+		]},
+		{"clusterId": "other", "hostname": "node-2", "feedBlocked": true, "resources": [
+			{"name": "memory", "usage": 0.95, "limit": 0.9, "config": "content.memory.resource-limit-factor"}
+		]}
+	]}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "feed-block", "--cluster", "other"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeStatusFeedBlocked, codeOf(err))
+	}
+	assert.NotContains(t, stdout.String(), "node-1")
+	assert.Contains(t, stdout.String(), "node-2")
+}
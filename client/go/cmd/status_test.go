@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestStatusPrintsReadyInstance(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+
+	err := cli.Run([]string{"status"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttps://container.example.com\n", stdout.String())
+}
+
+func TestStatusReportsNotReadyAndFails(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 503, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+
+	err := cli.Run([]string{"status"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeStatusNotReady, codeOf(err))
+	}
+	assert.Contains(t, stdout.String(), "default\tnot ready")
+}
+
+func TestStatusAllInstancesChecksEveryConfiguredInstance(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "set", "instance", "default,beta"}))
+	stdout.Reset()
+
+	err := cli.Run([]string{"status", "--all-instances"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttps://container.example.com\n"+
+		"beta\tready\nbeta\tendpoint\tcontainer\thttps://container.example.com\n", stdout.String())
+}
+
+func TestStatusInstanceWildcardIsEquivalentToAllInstances(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.env[authHomeEnv] = t.TempDir()
+	assert.Nil(t, cli.Run([]string{"config", "set", "instance", "default,beta"}))
+	stdout.Reset()
+
+	err := cli.Run([]string{"status", "--instance", "*"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttps://container.example.com\n"+
+		"beta\tready\nbeta\tendpoint\tcontainer\thttps://container.example.com\n", stdout.String())
+}
+
+func TestStatusVerbosePrintsPerNodeState(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.URL.Path == "/cluster/v2/state" {
+			return jsonResponse(`{"nodes": [
+				{"clusterId": "content", "hostname": "node-1", "state": "up", "generation": 3},
+				{"clusterId": "content", "hostname": "node-2", "state": "down", "generation": 3}
+			]}`), nil
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+
+	err := cli.Run([]string{"status", "--verbose"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "default\tready\n")
+	assert.Contains(t, stdout.String(), "default\tcontent\tnode-1\tup\tgeneration 3\n")
+	assert.Contains(t, stdout.String(), "default\tcontent\tnode-2\tdown\tgeneration 3\n")
+}
+
+func TestStatusWithoutVerboseDoesNotFetchClusterStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.URL.Path == "/cluster/v2/state" {
+			t.Fatal("should not fetch cluster status without --verbose")
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+
+	err := cli.Run([]string{"status"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttps://container.example.com\n", stdout.String())
+}
+
+func TestStatusPrintsEndpointsForEveryConfiguredCluster(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"default": ["https://a.example.com", "https://b.example.com"], "other": "https://c.example.com"}`
+
+	err := cli.Run([]string{"status", "--cluster", "default"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tdefault\thttps://a.example.com,https://b.example.com\n", stdout.String())
+}
+
+func TestStatusWithoutEndpointsUsesCustomTarget(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttp://127.0.0.1:8080\n", stdout.String())
+}
+
+func TestStatusFormatJSONPrintsOneObjectPerInstance(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+
+	err := cli.Run([]string{"status", "--format", "json"})
+
+	assert.Nil(t, err)
+	var status instanceStatus
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &status))
+	assert.Equal(t, "default", status.Instance)
+	assert.True(t, status.Ready)
+	if assert.Len(t, status.Endpoints, 1) {
+		assert.Equal(t, "container", status.Endpoints[0].Cluster)
+		assert.Equal(t, []string{"https://container.example.com"}, status.Endpoints[0].URLs)
+	}
+}
+
+func TestStatusAuthMethodReflectsConfiguredCertificate(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://container.example.com"]}`
+	cli.tlsOptions.CertificateFile = "cert.pem"
+	cli.tlsOptions.PrivateKeyFile = "key.pem"
+
+	err := cli.Run([]string{"status"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "default\tready\ndefault\tendpoint\tcontainer\thttps://container.example.com\tmTLS\n", stdout.String())
+}
+
+func TestStatusWatchReturnsAsSoonAsReady(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "--watch=1ms"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "default\tready")
+}
+
+func TestStatusWatchBareFlagUsesDefaultInterval(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "--watch"})
+
+	assert.Nil(t, err)
+}
+
+func TestStatusWatchGivesUpAfterWaitTimeout(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 503, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "--watch=1ms", "--wait=5ms"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeStatusNotReady, codeOf(err))
+	}
+	assert.Contains(t, stdout.String(), "not ready")
+}
+
+func TestStatusWatchDebugLogsCircuitBreakerOpening(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 503, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	cli, _, stderr := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"status", "--debug", "--watch=1ms", "--wait=200ms"})
+
+	if assert.NotNil(t, err) {
+		assert.Equal(t, ErrCodeStatusNotReady, codeOf(err))
+	}
+	assert.Contains(t, stderr.String(), "debug: circuit breaker closed -> open")
+}
+
+func TestStatusWatchRejectsAllInstances(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	err := cli.Run([]string{"status", "--watch", "--all-instances"})
+
+	assert.NotNil(t, err)
+}
+
+func TestCheckStatusReportsReadyAndNotReady(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+	service, err := containerService(cli, "")
+	assert.Nil(t, err)
+
+	ready, msg, err := checkStatus(service, time.Second)
+
+	assert.True(t, ready)
+	assert.Equal(t, "ready", msg)
+	assert.Nil(t, err)
+
+	httpClient.DoFunc = func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 503, Body: http.NoBody, Header: make(http.Header)}, nil
+	}
+
+	ready, msg, err = checkStatus(service, time.Second)
+
+	assert.False(t, ready)
+	assert.Contains(t, msg, "not ready: ")
+	assert.NotNil(t, err)
+}
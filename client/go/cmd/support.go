@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// defaultVespaLogPath is where a self-hosted node's service log is found by default.
+const defaultVespaLogPath = "/opt/vespa/logs/vespa/vespa.log"
+
+func newSupportCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "support",
+		Short: "Gather diagnostic information about a self-hosted node",
+	}
+	cmd.AddCommand(newSupportSnapshotCmd(cli))
+	return cmd
+}
+
+func newSupportSnapshotCmd(cli *CLI) *cobra.Command {
+	var (
+		output   string
+		logPath  string
+		logLines int
+	)
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Collect a diagnostic snapshot of this node into a tar.gz archive",
+		Long: `Collect a diagnostic snapshot of this node: resolved environment, service list and health, a
+tail of vespa.log, the active config generation, and disk/memory stats, into a single tar.gz archive.
+
+Each collector fails independently; a MANIFEST.txt inside the archive records what was collected and what
+failed, so a partial snapshot is still useful. Secrets and document content are never collected.`,
+		Example: `$ vespa support snapshot
+$ vespa support snapshot --output node1.tar.gz`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			target, err := cli.target()
+			if err != nil {
+				return err
+			}
+			collectors := []vespa.SupportCollector{vespa.EnvironmentCollector(cli.env)}
+			if containerService, err := target.ContainerService(""); err == nil {
+				collectors = append(collectors, vespa.ServiceHealthCollector("container", containerService, cli.requestTimeout))
+			}
+			if deployService, err := target.DeployService(); err == nil {
+				collectors = append(collectors,
+					vespa.ServiceHealthCollector("configserver", deployService, cli.requestTimeout),
+					vespa.ConfigGenerationCollector(deployService, cli.requestTimeout),
+				)
+			}
+			collectors = append(collectors, vespa.LogTailCollector(logPath, logLines), diskAndMemoryStatsCollector())
+
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := vespa.CollectSupportSnapshot(f, collectors); err != nil {
+				return err
+			}
+			fmt.Fprintf(cli.Stdout, "Wrote %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", "vespa-support-snapshot.tar.gz", "Path to write the snapshot archive to")
+	cmd.Flags().StringVar(&logPath, "log-file", defaultVespaLogPath, "Path to vespa.log")
+	cmd.Flags().IntVar(&logLines, "log-lines", 1000, "Number of trailing log lines to include")
+	return cmd
+}
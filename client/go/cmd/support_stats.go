@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// diskAndMemoryStatsCollector shells out to df and free, whichever are available, since the exact tool set
+// varies by OS/distribution. It fails only if neither produced any output.
+func diskAndMemoryStatsCollector() vespa.SupportCollector {
+	return vespa.SupportCollector{Name: "disk-memory-stats", Collect: func() (vespa.SupportFile, error) {
+		var b strings.Builder
+		if out, err := exec.Command("df", "-h").CombinedOutput(); err == nil {
+			b.WriteString("## df -h\n")
+			b.Write(out)
+		}
+		if out, err := exec.Command("free", "-m").CombinedOutput(); err == nil {
+			b.WriteString("\n## free -m\n")
+			b.Write(out)
+		}
+		if b.Len() == 0 {
+			return vespa.SupportFile{}, fmt.Errorf("neither df nor free produced output")
+		}
+		return vespa.SupportFile{Name: "disk-memory-stats.txt", Data: []byte(b.String())}, nil
+	}}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestSupportSnapshotWritesArchive(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: okResponse(`{"status": {"code": "up"}}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+	output := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	logFile := writeTempFile(t, "a log line\n")
+
+	err := cli.Run([]string{"support", "snapshot", "--output", output, "--log-file", logFile})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), output)
+	f, err := os.Open(output)
+	assert.Nil(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	tr := tar.NewReader(gz)
+	var sawManifest bool
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if hdr.Name == "MANIFEST.txt" {
+			sawManifest = true
+		}
+	}
+	assert.True(t, sawManifest)
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// defaultTargetURL is the base URL used for document operations when no other target is configured.
+const defaultTargetURL = "http://127.0.0.1:8080"
+
+// Environment variables read when the "request-signer" config option is set to "sigv4". The CLI has no
+// built-in IAM credential resolution (e.g. instance profiles, ~/.aws/credentials); these exist for the
+// common case of credentials already sitting in the environment.
+const (
+	requestSignerAccessKeyIdEnv     = "VESPA_CLI_AWS_ACCESS_KEY_ID"
+	requestSignerSecretAccessKeyEnv = "VESPA_CLI_AWS_SECRET_ACCESS_KEY"
+	requestSignerSessionTokenEnv    = "VESPA_CLI_AWS_SESSION_TOKEN"
+	requestSignerRegionEnv          = "VESPA_CLI_AWS_REGION"
+	requestSignerServiceEnv         = "VESPA_CLI_AWS_SERVICE"
+)
+
+// defaultSigv4Service is used when VESPA_CLI_AWS_SERVICE is unset, the common case of a Vespa Cloud gateway
+// fronted by Amazon API Gateway.
+const defaultSigv4Service = "execute-api"
+
+// target resolves the vespa.Target to use for data-plane operations. If VESPA_CLI_ENDPOINTS names one or
+// more container clusters, a CloudTarget is returned so --cluster can select between them; otherwise the
+// target URL is resolved through Config, so it can come from --target, .vespa/config.yaml, or the user's
+// global config, with ${VAR} references expanded against the CLI's environment, falling back to
+// defaultTargetURL when unset. --deploy-target (or the "deploy-target" config option) additionally sets a
+// separate deploy/config-server URL, for a self-hosted instance whose container and config server aren't
+// reachable on the same port/path; it defaults to the data-plane target URL when unset.
+func (c *CLI) target() (vespa.Target, error) {
+	endpoints, err := c.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) > 0 {
+		return c.createCloudTarget()
+	}
+	config, err := newConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return c.customTarget(config)
+}
+
+// customTarget builds the vespa.CustomTarget for a self-hosted instance from config's target and
+// deploy-target options, applying --insecure's validation to each. Its data-plane client presents a client
+// certificate the same way a cloud target's does: from --cert/--private-key, or from
+// VESPA_CLI_DATA_PLANE_CERT/VESPA_CLI_DATA_PLANE_KEY if those aren't set, for an mTLS-secured self-hosted
+// container whose certificate isn't written to disk. It's factored out of target so a caller that already
+// has a Config and needs to choose between a cloud and a custom target for a specific instance (e.g. `vespa
+// status`, which checks one instance at a time) doesn't have to re-resolve it.
+func (c *CLI) customTarget(config *Config) (*vespa.CustomTarget, error) {
+	targetURL := config.get(targetFlag)
+	if targetURL == "" {
+		targetURL = defaultTargetURL
+	}
+	if err := c.checkInsecureTarget(targetURL); err != nil {
+		return nil, err
+	}
+	var deployTargetURLs []string
+	for _, u := range strings.Split(config.get(deployTargetFlag), ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if err := c.checkInsecureTarget(u); err != nil {
+			return nil, err
+		}
+		deployTargetURLs = append(deployTargetURLs, u)
+	}
+	httpClient, err := c.dataPlaneHttpClient(c.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return vespa.NewCustomTarget(targetURL, deployTargetURLs, httpClient), nil
+}
+
+// checkInsecureTarget validates --insecure, if set, against targetURL's host. Skipping certificate
+// verification is restricted to a loopback or RFC1918 target by default, since this is meant for testing
+// against a self-signed local setup, not as a general bypass; --insecure-allow-public lifts that
+// restriction for a user who understands the risk. A successful check prints a one-time warning, so
+// --insecure left on by accident is still visible in the command's output.
+func (c *CLI) checkInsecureTarget(targetURL string) error {
+	if !c.insecureOverride {
+		return nil
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return err
+	}
+	if !isLoopbackOrPrivateHost(u.Hostname()) && !c.insecureAllowPublicOverride {
+		return fmt.Errorf("--insecure refuses to skip certificate verification for %s: pass --insecure-allow-public to do so anyway", u.Hostname())
+	}
+	c.warnInsecure()
+	return nil
+}
+
+// isLoopbackOrPrivateHost reports whether host is "localhost" or an IP in a loopback or RFC1918/ULA
+// private range. A hostname that isn't "localhost" is treated as public, since resolving it would require
+// a DNS lookup this check is not meant to perform.
+func isLoopbackOrPrivateHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate()
+}
+
+// warnInsecure prints the --insecure warning once per invocation, the first time it is relevant.
+func (c *CLI) warnInsecure() {
+	if c.insecureWarned {
+		return
+	}
+	c.insecureWarned = true
+	c.printWarning("certificate verification is disabled (--insecure); do not use this against an untrusted network")
+}
+
+// containerService resolves cli's target and returns its container service for cluster, a thin convenience
+// wrapper for the common case of a single target-then-service resolution. Custom headers configured via
+// --header or the "headers" config option, and a signer configured via the "request-signer" config option,
+// are applied to every request the returned service sends.
+func containerService(cli *CLI, cluster string) (*vespa.Service, error) {
+	target, err := cli.target()
+	if err != nil {
+		return nil, err
+	}
+	service, err := target.ContainerService(cluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyServiceOptions(cli, service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// applyServiceOptions applies the custom headers and request signer configured for cli to service, shared
+// by every plane (data and control) so a gateway fronting Vespa sees the same headers and signature
+// regardless of which kind of request passes through it.
+func applyServiceOptions(cli *CLI, service *vespa.Service) error {
+	headers, err := cli.headers()
+	if err != nil {
+		return err
+	}
+	service.Headers = headers
+	signer, err := cli.requestSigner()
+	if err != nil {
+		return err
+	}
+	service.Signer = signer
+	return nil
+}
+
+// headers resolves the custom headers to apply to data-plane requests, from --header if given, otherwise
+// the comma-separated "headers" config option.
+func (c *CLI) headers() (http.Header, error) {
+	values := c.headerOverrides
+	if len(values) == 0 {
+		config, err := newConfig(c)
+		if err != nil {
+			return nil, err
+		}
+		if raw := config.get(headersFlag); raw != "" {
+			for _, v := range strings.Split(raw, ",") {
+				values = append(values, v)
+			}
+		}
+	}
+	return vespa.ParseHeaders(values)
+}
+
+// tlsPins resolves the base64 SHA-256 SPKI hashes pinned by the comma-separated "tls.pin-sha256" config
+// option, applied on top of normal certificate verification for every data-plane TLS connection so a
+// presented certificate must both chain to a trusted CA and match one of these, defeating a MITM even with
+// a compromised CA. Returns nil if the option isn't set, so a caller can skip pinning unconditionally.
+func (c *CLI) tlsPins() ([]string, error) {
+	config, err := newConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	raw := config.get(tlsPinSHA256Flag)
+	if raw == "" {
+		return nil, nil
+	}
+	var pins []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pins = append(pins, p)
+		}
+	}
+	return pins, nil
+}
+
+// requestSigner resolves the vespa.RequestSigner to use, from the "request-signer" config option: "sigv4"
+// selects the built-in AWS Signature Version 4 signer, using credentials from the environment, and anything
+// else is taken as an external command to run per request via vespa.HelperRequestSigner. Returns nil, nil
+// if the option isn't set, so a caller can assign the result to Service.Signer unconditionally.
+func (c *CLI) requestSigner() (vespa.RequestSigner, error) {
+	config, err := newConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	value := config.get(requestSignerFlag)
+	if value == "" {
+		return nil, nil
+	}
+	if value != "sigv4" {
+		return vespa.NewHelperRequestSigner(value), nil
+	}
+	accessKeyId := c.env[requestSignerAccessKeyIdEnv]
+	secretAccessKey := c.env[requestSignerSecretAccessKeyEnv]
+	if accessKeyId == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("request-signer sigv4 requires %s and %s to be set", requestSignerAccessKeyIdEnv, requestSignerSecretAccessKeyEnv)
+	}
+	service := c.env[requestSignerServiceEnv]
+	if service == "" {
+		service = defaultSigv4Service
+	}
+	credentials := vespa.AWSCredentials{
+		AccessKeyID:     accessKeyId,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    c.env[requestSignerSessionTokenEnv],
+	}
+	return vespa.NewSigV4Signer(credentials, c.env[requestSignerRegionEnv], service), nil
+}
+
+// deployService resolves cli's target and returns its deploy (config server) service.
+func deployService(cli *CLI) (*vespa.Service, error) {
+	target, err := cli.target()
+	if err != nil {
+		return nil, err
+	}
+	service, err := target.DeployService()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyServiceOptions(cli, service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
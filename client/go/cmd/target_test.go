@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func TestHeadersFromFlagOverride(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.headerOverrides = []string{"X-Auth-Token: secret"}
+
+	headers, err := cli.headers()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", headers.Get("X-Auth-Token"))
+}
+
+func TestHeadersFromConfigOption(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(headersFlag, "X-Auth-Token: secret,X-Custom: value"))
+
+	headers, err := cli.headers()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", headers.Get("X-Auth-Token"))
+	assert.Equal(t, "value", headers.Get("X-Custom"))
+}
+
+func TestHeadersFlagOverridesConfigOption(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(headersFlag, "X-Custom: from-config"))
+	cli.headerOverrides = []string{"X-Custom: from-flag"}
+
+	headers, err := cli.headers()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "from-flag", headers.Get("X-Custom"))
+}
+
+func TestRequestSignerUnsetByDefault(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+
+	signer, err := cli.requestSigner()
+
+	assert.Nil(t, err)
+	assert.Nil(t, signer)
+}
+
+func TestRequestSignerSigv4RequiresCredentials(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(requestSignerFlag, "sigv4"))
+
+	_, err = cli.requestSigner()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), requestSignerAccessKeyIdEnv)
+	}
+}
+
+func TestRequestSignerSigv4FromEnv(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[requestSignerAccessKeyIdEnv] = "AKIDEXAMPLE"
+	cli.env[requestSignerSecretAccessKeyEnv] = "secret"
+	cli.env[requestSignerRegionEnv] = "us-east-1"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(requestSignerFlag, "sigv4"))
+
+	signer, err := cli.requestSigner()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, signer)
+}
+
+func TestRequestSignerHelperCommand(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(requestSignerFlag, "./sign-request.sh"))
+
+	signer, err := cli.requestSigner()
+
+	assert.Nil(t, err)
+	assert.IsType(t, &vespa.HelperRequestSigner{}, signer)
+}
+
+func TestContainerServiceAppliesConfiguredSigner(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env[requestSignerAccessKeyIdEnv] = "AKIDEXAMPLE"
+	cli.env[requestSignerSecretAccessKeyEnv] = "secret"
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(requestSignerFlag, "sigv4"))
+
+	service, err := containerService(cli, "")
+	assert.Nil(t, err)
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.Nil(t, err)
+	assert.Contains(t, httpClient.LastRequest.Header.Get("Authorization"), "AWS4-HMAC-SHA256")
+}
+
+func TestIsLoopbackOrPrivateHost(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "10.0.0.5", "172.16.4.1", "192.168.1.1", "fd00::1"} {
+		assert.True(t, isLoopbackOrPrivateHost(host), host)
+	}
+	for _, host := range []string{"example.com", "8.8.8.8", "2001:4860:4860::8888"} {
+		assert.False(t, isLoopbackOrPrivateHost(host), host)
+	}
+}
+
+func TestCheckInsecureTargetAllowsPrivateTargetByDefault(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.insecureOverride = true
+
+	err := cli.checkInsecureTarget("https://127.0.0.1:8080")
+
+	assert.Nil(t, err)
+}
+
+func TestCheckInsecureTargetRefusesPublicTargetByDefault(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.insecureOverride = true
+
+	err := cli.checkInsecureTarget("https://vespa.example.com")
+
+	assert.NotNil(t, err)
+}
+
+func TestCheckInsecureTargetAllowsPublicTargetWithOverride(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.insecureOverride = true
+	cli.insecureAllowPublicOverride = true
+
+	err := cli.checkInsecureTarget("https://vespa.example.com")
+
+	assert.Nil(t, err)
+}
+
+func TestCheckInsecureTargetWarnsOnlyOnce(t *testing.T) {
+	cli, _, stderr := newTestCLI(nil)
+	cli.insecureOverride = true
+
+	assert.Nil(t, cli.checkInsecureTarget("https://127.0.0.1:8080"))
+	assert.Nil(t, cli.checkInsecureTarget("https://127.0.0.1:8080"))
+
+	assert.Equal(t, 1, strings.Count(stderr.String(), "Warning: certificate verification is disabled"))
+}
+
+func TestTargetDeployServiceDefaultsToDataPlaneTarget(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(targetFlag, "http://localhost:8080"))
+
+	container, err := containerService(cli, "")
+	assert.Nil(t, err)
+	deploy, err := deployService(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "http://localhost:8080", container.BaseURL)
+	assert.Equal(t, "http://localhost:8080", deploy.BaseURL)
+}
+
+func TestTargetDeployTargetOverridesDeployService(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	config, err := newConfig(cli)
+	assert.Nil(t, err)
+	assert.Nil(t, config.set(targetFlag, "http://localhost:8080"))
+	assert.Nil(t, config.set(deployTargetFlag, "http://localhost:19071"))
+
+	container, err := containerService(cli, "")
+	assert.Nil(t, err)
+	deploy, err := deployService(cli)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "http://localhost:8080", container.BaseURL)
+	assert.Equal(t, "http://localhost:19071", deploy.BaseURL)
+}
+
+func TestTargetRejectsInsecureForCloudTarget(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	cli.env["VESPA_CLI_ENDPOINTS"] = `{"container": ["https://a.example.com"]}`
+	cli.insecureOverride = true
+
+	_, err := cli.target()
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Vespa Cloud")
+}
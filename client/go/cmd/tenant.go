@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+func newTenantCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Manage tenants in Vespa Cloud",
+	}
+	cmd.AddCommand(newTenantListCmd(cli))
+	return cmd
+}
+
+// tenantInfo is a single tenant, as returned by the controller's tenant listing API.
+type tenantInfo struct {
+	Name string `json:"tenant"`
+}
+
+func newTenantListCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the tenants this API key or user can access",
+		Long: `List the tenants this API key or user can access, so you don't have to open the console to check the
+exact name of a tenant. Requires a Vespa Cloud target and authenticates the same way as 'vespa prod submit'.`,
+		Example: `$ vespa tenant list
+$ vespa tenant list --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			tenants, err := fetchTenants(cli)
+			if err != nil {
+				return err
+			}
+			if cli.outputFormat == "json" {
+				return json.NewEncoder(cli.Stdout).Encode(tenants)
+			}
+			for _, t := range tenants {
+				fmt.Fprintln(cli.Stdout, t.Name)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func fetchTenants(cli *CLI) ([]tenantInfo, error) {
+	target, err := cli.createCloudTarget()
+	if err != nil {
+		return nil, err
+	}
+	service := target.ControlService()
+	req, err := http.NewRequest(http.MethodGet, "/application/v4/tenant", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to list tenants: status %d: %s", resp.StatusCode, vespa.ControllerErrorMessage(body))
+	}
+	var tenants []tenantInfo
+	if err := json.Unmarshal(body, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestTenantListPrintsTenantNames(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`[{"tenant": "mytenant"}, {"tenant": "other"}]`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"tenant", "list"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mytenant\nother\n", stdout.String())
+	assert.Equal(t, "/application/v4/tenant", httpClient.LastRequest.URL.Path)
+}
+
+func TestTenantListFailsWithReadableMessageOnForbidden(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"error-code": "FORBIDDEN", "message": "Access denied for user"}`)}
+	httpClient.NextResponse.StatusCode = 403
+	cli, _, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"tenant", "list"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Access denied for user")
+		assert.NotContains(t, err.Error(), "error-code")
+	}
+}
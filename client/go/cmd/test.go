@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// testCase is the on-disk representation of a single system test file.
+type testCase struct {
+	Request struct {
+		Method string          `json:"method"`
+		URI    string          `json:"uri"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Body json.RawMessage `json:"body,omitempty"`
+	} `json:"response"`
+	// RecordFields, if set, restricts recording and assertion to these top-level fields of the response body.
+	RecordFields []string `json:"recordFields,omitempty"`
+}
+
+func newTestCmd(cli *CLI) *cobra.Command {
+	var record bool
+	cmd := &cobra.Command{
+		Use:   "test test-file [test-file...]",
+		Short: "Run a Vespa system test suite, or a specific test",
+		Example: `$ vespa test tests/system-test/feed-and-search.json
+$ vespa test --record tests/system-test/feed-and-search.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			service, err := containerService(cli, "")
+			if err != nil {
+				return err
+			}
+			for _, file := range args {
+				if record {
+					if err := recordTest(cli, service, file); err != nil {
+						return fmt.Errorf("%s: %w", file, err)
+					}
+					fmt.Fprintf(cli.Stdout, "%s: recorded\n", file)
+				} else {
+					if err := runTest(cli, service, file); err != nil {
+						return fmt.Errorf("%s: %w", file, err)
+					}
+					fmt.Fprintf(cli.Stdout, "%s: OK\n", file)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&record, "record", false, "Execute each test's request and overwrite its expected response with the actual one, instead of asserting")
+	return cmd
+}
+
+func loadTestCase(file string) (testCase, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return testCase{}, err
+	}
+	var tc testCase
+	if err := json.Unmarshal(data, &tc); err != nil {
+		return testCase{}, fmt.Errorf("invalid test file: %w", err)
+	}
+	return tc, nil
+}
+
+// executeTest issues the request described by tc against service, and returns the (optionally
+// field-filtered) response body.
+func executeTest(cli *CLI, service *vespa.Service, tc testCase) ([]byte, error) {
+	method := tc.Request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, tc.Request.URI, bytes.NewReader(tc.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+	if len(tc.Request.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := service.Do(req, cli.requestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, err
+	}
+	return filterFields(buf.Bytes(), tc.RecordFields)
+}
+
+// filterFields restricts a JSON object to the given top-level fields. An empty fields list is a no-op.
+func filterFields(data []byte, fields []string) ([]byte, error) {
+	if len(fields) == 0 || len(data) == 0 {
+		return data, nil
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("cannot filter non-object response: %w", err)
+	}
+	filtered := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if v, ok := parsed[field]; ok {
+			filtered[field] = v
+		}
+	}
+	return json.Marshal(filtered)
+}
+
+// prettyJSON re-encodes data with indentation and (since encoding/json always marshals map keys in sorted
+// order) deterministically sorted object keys, so recorded fixtures diff cleanly.
+func prettyJSON(data []byte) ([]byte, error) {
+	var parsed interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(parsed, "", "  ")
+}
+
+func runTest(cli *CLI, service *vespa.Service, file string) error {
+	tc, err := loadTestCase(file)
+	if err != nil {
+		return err
+	}
+	actual, err := executeTest(cli, service, tc)
+	if err != nil {
+		return err
+	}
+	want, err := filterFields(tc.Response.Body, tc.RecordFields)
+	if err != nil {
+		return err
+	}
+	actualPretty, err := prettyJSON(actual)
+	if err != nil {
+		return err
+	}
+	wantPretty, err := prettyJSON(want)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(actualPretty, wantPretty) {
+		return fmt.Errorf("response does not match expected:\n--- expected\n%s\n--- actual\n%s", wantPretty, actualPretty)
+	}
+	return nil
+}
+
+func recordTest(cli *CLI, service *vespa.Service, file string) error {
+	tc, err := loadTestCase(file)
+	if err != nil {
+		return err
+	}
+	actual, err := executeTest(cli, service, tc)
+	if err != nil {
+		return err
+	}
+	pretty, err := prettyJSON(actual)
+	if err != nil {
+		return err
+	}
+	tc.Response.Body = pretty
+	out, err := prettyJSON(mustMarshal(tc))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(out, '\n'), 0644)
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
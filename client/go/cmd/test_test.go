@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func writeTestFile(t *testing.T, contents string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestRecordThenAssert(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"hits": [{"id": 1}], "totalCount": 1, "trace": "noisy"}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTestFile(t, `{"request": {"method": "GET", "uri": "/search/?query=foo"}, "recordFields": ["hits", "totalCount"]}`)
+
+	err := cli.Run([]string{"test", "--record", file})
+	assert.Nil(t, err)
+
+	recorded, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(recorded), "trace")
+	assert.Contains(t, string(recorded), `"hits"`)
+
+	httpClient.NextResponse = jsonResponse(`{"hits": [{"id": 1}], "totalCount": 1, "trace": "different-this-time"}`)
+	err = cli.Run([]string{"test", file})
+	assert.Nil(t, err)
+}
+
+func TestTestMismatch(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"hits": []}`)}
+	cli, _, _ := newTestCLI(httpClient)
+	file := writeTestFile(t, `{"request": {"method": "GET", "uri": "/search/?query=foo"}, "response": {"body": {"hits": [{"id": 1}]}}}`)
+
+	err := cli.Run([]string{"test", file})
+
+	assert.NotNil(t, err)
+}
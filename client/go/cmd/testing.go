@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"io"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// NewForTesting creates a CLI like New, but with its environment and HTTP client injectable instead of
+// reading the OS environment and dialing a real network. It exists to back test harnesses — the vespatest
+// package, and this package's own tests — so neither duplicates CLI construction and drifts from the other.
+//
+// A nil env falls back to the OS environment, exactly like New. A nil httpClient falls back to a real one.
+func NewForTesting(stdin io.Reader, stdout, stderr io.Writer, env map[string]string, httpClient func(timeout time.Duration) util.HTTPClient) (*CLI, error) {
+	cli, err := New(stdin, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	if env != nil {
+		cli.env = env
+	}
+	if httpClient != nil {
+		cli.httpClient = httpClient
+	}
+	return cli, nil
+}
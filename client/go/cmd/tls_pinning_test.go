@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/vespa"
+)
+
+// trustServerCert writes server's certificate to a PEM file under t.TempDir and points cli.caCertFileOverride
+// at it, so dataPlaneHttpClient's normal chain verification succeeds against an httptest TLS server's
+// self-signed certificate, isolating these tests to the tls.pin-sha256 check itself.
+func trustServerCert(t *testing.T, cli *CLI, server *httptest.Server) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, os.WriteFile(file, pemBytes, 0600))
+	cli.caCertFileOverride = file
+}
+
+// TestDataPlaneHttpClientAcceptsMatchingPin verifies a tls.pin-sha256 value matching the server's actual
+// certificate lets a request through, on top of normal (here, --ca-cert-trusted) chain verification.
+func TestDataPlaneHttpClientAcceptsMatchingPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	trustServerCert(t, cli, server)
+	assert.Nil(t, cli.Run([]string{"config", "set", "tls.pin-sha256", vespa.SPKIPin(server.Certificate())}))
+
+	client, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestDataPlaneHttpClientRejectsMismatchingPin verifies a tls.pin-sha256 value that matches no certificate in
+// the presented chain fails the request, naming the presented fingerprint in the error.
+func TestDataPlaneHttpClientRejectsMismatchingPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cli, _, _ := newTestCLI(nil)
+	cli.env[authHomeEnv] = t.TempDir()
+	trustServerCert(t, cli, server)
+	assert.Nil(t, cli.Run([]string{"config", "set", "tls.pin-sha256", "bm90LWEtcmVhbC1waW4tc2hhMjU2LWhhc2g="}))
+
+	client, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	_, err = client.Do(req, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "certificate pin mismatch")
+		assert.Contains(t, err.Error(), vespa.SPKIPin(server.Certificate()))
+	}
+}
+
+// TestDataPlaneHttpClientWithoutPinsIgnoresCertificate verifies tls.pin-sha256 being unset doesn't change
+// existing --ca-cert behavior: the request succeeds purely on chain verification.
+func TestDataPlaneHttpClientWithoutPinsIgnoresCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cli, _, _ := newTestCLI(nil)
+	trustServerCert(t, cli, server)
+
+	client, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	resp, err := client.Do(req, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
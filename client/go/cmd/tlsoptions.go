@@ -0,0 +1,27 @@
+package cmd
+
+import "crypto/tls"
+
+// TLSOptions holds a client certificate and private key used to authenticate mTLS requests against an
+// endpoint that requires one.
+type TLSOptions struct {
+	CertificateFile string
+	PrivateKeyFile  string
+}
+
+// Certificate loads o's certificate/key pair. ok is false if neither file is set, which is not an error:
+// most targets don't require a client certificate.
+func (o TLSOptions) Certificate() (cert tls.Certificate, ok bool, err error) {
+	if o.CertificateFile == "" && o.PrivateKeyFile == "" {
+		return tls.Certificate{}, false, nil
+	}
+	cert, err = tls.LoadX509KeyPair(o.CertificateFile, o.PrivateKeyFile)
+	return cert, err == nil, err
+}
+
+// APIOptions holds options specific to calling the Vespa Cloud control-plane API, kept separate from the
+// TLSOptions used for data-plane requests against a self-hosted or cloud target, since the two can require
+// different certificates.
+type APIOptions struct {
+	TLSOptions TLSOptions
+}
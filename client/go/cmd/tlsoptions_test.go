@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// generateTestCertificate writes a self-signed certificate/key pair to dir, returning TLSOptions pointing
+// at the two files.
+func generateTestCertificate(t *testing.T, dir string) TLSOptions {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vespa-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.Nil(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	return TLSOptions{CertificateFile: certFile, PrivateKeyFile: keyFile}
+}
+
+func TestTLSOptionsCertificateUnsetIsNotAnError(t *testing.T) {
+	_, ok, err := TLSOptions{}.Certificate()
+
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestTLSOptionsCertificateLoadsKeyPair(t *testing.T) {
+	opts := generateTestCertificate(t, t.TempDir())
+
+	cert, ok, err := opts.Certificate()
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.NotEmpty(t, cert.Certificate)
+}
+
+// TestDataPlaneAndAPIClientsAreIndependentlyConfigured verifies that apiHttpClient and dataPlaneHttpClient
+// fall back to the shared mock client when no certificate is configured, and build a distinct real client
+// as soon as one is, so --api-cert-file can never silently reuse --cert's certificate or vice versa.
+func TestDataPlaneAndAPIClientsAreIndependentlyConfigured(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+
+	apiClient, err := cli.apiHttpClient(time.Second)
+	assert.Nil(t, err)
+	dataPlaneClient, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	mockClient := cli.httpClient(time.Second)
+	assert.Equal(t, reflect.TypeOf(mockClient), reflect.TypeOf(apiClient))
+	assert.Equal(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient))
+
+	cli.apiOptions = APIOptions{TLSOptions: generateTestCertificate(t, filepath.Join(t.TempDir(), "api"))}
+
+	apiClient, err = cli.apiHttpClient(time.Second)
+	assert.Nil(t, err)
+	dataPlaneClient, err = cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.TypeOf(mockClient), reflect.TypeOf(apiClient))
+	assert.Equal(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient))
+}
+
+// generateTestCertificatePEM is generateTestCertificate, returning the certificate and key as PEM bytes
+// instead of writing them to files, for exercising the VESPA_CLI_DATA_PLANE_CERT/KEY env var path.
+func generateTestCertificatePEM(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	dir := t.TempDir()
+	opts := generateTestCertificate(t, dir)
+	certBytes, err := os.ReadFile(opts.CertificateFile)
+	assert.Nil(t, err)
+	keyBytes, err := os.ReadFile(opts.PrivateKeyFile)
+	assert.Nil(t, err)
+	return string(certBytes), string(keyBytes)
+}
+
+// TestDataPlaneHttpClientFallsBackToCertificateEnvVars verifies that dataPlaneHttpClient presents a
+// certificate read directly from VESPA_CLI_DATA_PLANE_CERT/VESPA_CLI_DATA_PLANE_KEY when --cert/--private-key
+// aren't set, the path a custom URL target (one with no config-driven certificate file) relies on.
+func TestDataPlaneHttpClientFallsBackToCertificateEnvVars(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	mockClient := cli.httpClient(time.Second)
+	certPEM, keyPEM := generateTestCertificatePEM(t)
+	cli.env[dataPlaneCertEnv] = certPEM
+	cli.env[dataPlaneKeyEnv] = keyPEM
+
+	dataPlaneClient, err := cli.dataPlaneHttpClient(time.Second)
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient))
+}
+
+// TestDataPlaneHttpClientPrefersCertificateFileOverEnvVars verifies --cert/--private-key take precedence over
+// the env vars when both are set, rather than one silently overriding the other.
+func TestDataPlaneHttpClientPrefersCertificateFileOverEnvVars(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.tlsOptions = generateTestCertificate(t, t.TempDir())
+	cli.env[dataPlaneCertEnv] = "not a valid certificate"
+	cli.env[dataPlaneKeyEnv] = "not a valid key"
+
+	_, err := cli.dataPlaneHttpClient(time.Second)
+
+	assert.Nil(t, err)
+}
+
+// TestDataPlaneHttpClientRequiresBothCertificateEnvVars verifies a lone VESPA_CLI_DATA_PLANE_CERT or
+// VESPA_CLI_DATA_PLANE_KEY is rejected rather than silently building an unauthenticated client.
+func TestDataPlaneHttpClientRequiresBothCertificateEnvVars(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.env[dataPlaneCertEnv] = "some certificate"
+
+	_, err := cli.dataPlaneHttpClient(time.Second)
+
+	assert.NotNil(t, err)
+}
+
+// TestDataPlaneHttpClientTrustsConfiguredCACert verifies that --ca-cert builds a real client (RootCAs
+// populated from the given bundle) instead of falling back to the shared mock, and that VESPA_CLI_CA_CERT is
+// honored the same way when the flag isn't set.
+func TestDataPlaneHttpClientTrustsConfiguredCACert(t *testing.T) {
+	certPEM, _ := generateTestCertificatePEM(t)
+	mockClient := func() util.HTTPClient {
+		cli, _, _ := newTestCLI(nil)
+		return cli.httpClient(time.Second)
+	}()
+
+	cli, _, _ := newTestCLI(nil)
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(file, []byte(certPEM), 0600))
+	cli.caCertFileOverride = file
+
+	dataPlaneClient, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient))
+
+	cli2, _, _ := newTestCLI(nil)
+	cli2.env[caCertEnv] = file
+	dataPlaneClient2, err := cli2.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient2))
+}
+
+// TestDataPlaneHttpClientRejectsInvalidCACert verifies a --ca-cert bundle containing no certificates is
+// rejected rather than silently falling back to the system trust store.
+func TestDataPlaneHttpClientRejectsInvalidCACert(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(file, []byte("not a certificate"), 0600))
+	cli.caCertFileOverride = file
+
+	_, err := cli.dataPlaneHttpClient(time.Second)
+
+	assert.NotNil(t, err)
+}
+
+// TestInsecureOverrideOnlyAffectsDataPlaneClient verifies that --insecure builds a real client (instead of
+// falling back to the shared mock) for dataPlaneHttpClient, but leaves apiHttpClient untouched, since
+// --insecure is rejected outright for Vespa Cloud before an API client is ever built for it.
+func TestInsecureOverrideOnlyAffectsDataPlaneClient(t *testing.T) {
+	cli, _, _ := newTestCLI(nil)
+	cli.insecureOverride = true
+	mockClient := cli.httpClient(time.Second)
+
+	dataPlaneClient, err := cli.dataPlaneHttpClient(time.Second)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.TypeOf(mockClient), reflect.TypeOf(dataPlaneClient))
+
+	apiClient, err := cli.apiHttpClient(time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, reflect.TypeOf(mockClient), reflect.TypeOf(apiClient))
+}
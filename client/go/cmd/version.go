@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vespa-engine/vespa/client/go/build"
+)
+
+// noUpdateCheckEnv, when set to any non-empty value, suppresses the network call vespa version otherwise
+// makes to check for a newer release. This matters most in air-gapped environments, where that call would
+// otherwise just slow down every invocation of the command until it times out.
+const noUpdateCheckEnv = "VESPA_CLI_NO_UPDATE_CHECK"
+
+// latestVersionURL is queried for the latest released version of this tool.
+const latestVersionURL = "https://api.github.com/repos/vespa-engine/vespa/releases/latest"
+
+// versionCheckTTLEnv overrides how long a cached latest-version lookup is considered valid, as a
+// time.ParseDuration string. Defaults to 24h.
+const versionCheckTTLEnv = "VESPA_CLI_VERSION_CHECK_TTL"
+
+// cacheDirEnv overrides the directory the version check cache (and any other CLI-managed cache file) is
+// stored in. Defaults to the OS temp directory.
+const cacheDirEnv = "VESPA_CLI_CACHE_DIR"
+
+type versionCheckCache struct {
+	Version   string    `json:"version"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+func versionCheckTTL() time.Duration {
+	if v := os.Getenv(versionCheckTTLEnv); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return 24 * time.Hour
+}
+
+func versionCachePath(cli *CLI) string {
+	dir := cli.env[cacheDirEnv]
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "vespa-cli-version-check.json")
+}
+
+func newVersionCmd(cli *CLI) *cobra.Command {
+	var (
+		format        string
+		noUpdateCheck bool
+	)
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the version of this command-line tool, and check for a newer release",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			latest := ""
+			if !noUpdateCheck && os.Getenv(noUpdateCheckEnv) == "" {
+				if v, err := latestVersion(cli); err == nil {
+					latest = v
+				}
+			}
+			upToDate := latest == "" || latest == build.Version
+			if format == "json" {
+				return json.NewEncoder(cli.Stdout).Encode(map[string]interface{}{
+					"client":   build.Version,
+					"latest":   latest,
+					"upToDate": upToDate,
+				})
+			}
+			fmt.Fprintf(cli.Stdout, "vespa version %s\n", build.Version)
+			if latest != "" && !upToDate {
+				fmt.Fprintf(cli.Stdout, "A newer version is available: %s\n", latest)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "human", `Output format. Must be "human" or "json"`)
+	cmd.Flags().BoolVar(&noUpdateCheck, "no-update-check", false, "Do not check whether a newer release is available")
+	return cmd
+}
+
+// latestVersion returns the latest published tag of this tool, consulting (and refreshing) an on-disk
+// cache first so that most invocations avoid the network round-trip entirely.
+func latestVersion(cli *CLI) (string, error) {
+	cachePath := versionCachePath(cli)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cached versionCheckCache
+		if json.Unmarshal(data, &cached) == nil && time.Since(cached.CheckedAt) < versionCheckTTL() {
+			return cached.Version, nil
+		}
+	}
+	version, err := fetchLatestVersion(cli)
+	if err != nil {
+		return "", err
+	}
+	cached, err := json.Marshal(versionCheckCache{Version: version, CheckedAt: time.Now()})
+	if err == nil {
+		_ = os.WriteFile(cachePath, cached, 0644)
+	}
+	return version, nil
+}
+
+// fetchLatestVersion queries the release API directly, bypassing the cache.
+func fetchLatestVersion(cli *CLI) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, latestVersionURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := cli.httpClient(cli.requestTimeout).Do(req, cli.requestTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("release check failed with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
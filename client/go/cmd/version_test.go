@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/build"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestVersionJSON(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: jsonResponse(`{"tag_name": "8.99.0"}`)}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"version", "--format", "json"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), `"client":"`+build.Version+`"`)
+	assert.Contains(t, stdout.String(), `"latest":"8.99.0"`)
+	assert.Contains(t, stdout.String(), `"upToDate":false`)
+}
+
+func TestVersionCachesLatestLookup(t *testing.T) {
+	calls := 0
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(*http.Request, time.Duration) (*http.Response, error) {
+		calls++
+		return jsonResponse(`{"tag_name": "8.99.0"}`), nil
+	}
+	cli, _, _ := newTestCLI(httpClient)
+	cli.env["VESPA_CLI_CACHE_DIR"] = t.TempDir()
+
+	assert.Nil(t, cli.Run([]string{"version"}))
+	assert.Nil(t, cli.Run([]string{"version"}))
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestVersionNoUpdateCheck(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(*http.Request, time.Duration) (*http.Response, error) {
+		t.Fatal("should not perform a network call when --no-update-check is set")
+		return nil, nil
+	}}
+	cli, stdout, _ := newTestCLI(httpClient)
+
+	err := cli.Run([]string{"version", "--no-update-check"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "vespa version "+build.Version)
+}
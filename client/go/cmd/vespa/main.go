@@ -0,0 +1,22 @@
+// Command vespa is the command-line client for Vespa.ai.
+package main
+
+import (
+	"os"
+
+	"github.com/vespa-engine/vespa/client/go/cmd"
+)
+
+func main() {
+	cli, err := cmd.New(os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		os.Exit(1)
+	}
+	if err := cli.Run(os.Args[1:]); err != nil {
+		status := 1
+		if cliErr, ok := err.(*cmd.ErrCLI); ok && cliErr.Status != 0 {
+			status = cliErr.Status
+		}
+		os.Exit(status)
+	}
+}
@@ -0,0 +1,58 @@
+// Package mock provides test doubles for the interfaces in util and vespa.
+package mock
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// HTTPClient is a util.HTTPClient that returns canned responses instead of performing real requests.
+type HTTPClient struct {
+	mu sync.Mutex
+
+	// LastRequest holds the most recent request passed to Do.
+	LastRequest *http.Request
+	// NextResponse, if set, is returned (and then cleared) by the next call to Do.
+	NextResponse *http.Response
+	// NextError, if set, is returned (and then cleared) by the next call to Do.
+	NextError error
+	// DoFunc, if set, is called instead of the above fields, for tests that need per-request behaviour
+	// (e.g. returning a different response for each page of a paginated request).
+	DoFunc func(request *http.Request, timeout time.Duration) (*http.Response, error)
+	// Stats is returned verbatim by LastRequestStats, letting a test exercise a command's --stats flag
+	// with deterministic timing instead of a real network call.
+	Stats util.RequestStats
+}
+
+func (c *HTTPClient) Do(request *http.Request, timeout time.Duration) (*http.Response, error) {
+	c.mu.Lock()
+	c.LastRequest = request
+	doFunc := c.DoFunc
+	c.mu.Unlock()
+	if doFunc != nil {
+		return doFunc(request, timeout)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.NextError != nil {
+		err := c.NextError
+		c.NextError = nil
+		return nil, err
+	}
+	resp := c.NextResponse
+	c.NextResponse = nil
+	if resp == nil {
+		resp = &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}
+	}
+	return resp, nil
+}
+
+// LastRequestStats returns c.Stats, satisfying util.TimedHTTPClient.
+func (c *HTTPClient) LastRequestStats() util.RequestStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Stats
+}
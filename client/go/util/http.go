@@ -0,0 +1,91 @@
+// Package util contains small helpers shared by the vespa and cmd packages.
+package util
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HTTPClient is the interface through which all outgoing HTTP requests are made. It exists so tests can
+// substitute a mock implementation.
+type HTTPClient interface {
+	Do(request *http.Request, timeout time.Duration) (*http.Response, error)
+}
+
+// RequestStats is a timing breakdown for a single HTTP request, captured with net/http/httptrace. A phase
+// that a request never reached (e.g. TLSHandshake for a plain HTTP endpoint) is left at its zero value.
+type RequestStats struct {
+	DNSLookup       time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+}
+
+// TimedHTTPClient is an HTTPClient that also records the timing of the request it most recently sent, for
+// a command's --stats flag. mock.HTTPClient implements this too, via a Stats field a test can set directly,
+// so --stats has something deterministic to print without a real network call.
+type TimedHTTPClient interface {
+	HTTPClient
+	// LastRequestStats returns the timing breakdown of the most recent call to Do.
+	LastRequestStats() RequestStats
+}
+
+type defaultHTTPClient struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	stats RequestStats
+}
+
+// CreateClient returns the default HTTPClient, configured with timeout as the maximum time to wait for a
+// single request to complete.
+func CreateClient(timeout time.Duration) HTTPClient {
+	return &defaultHTTPClient{client: &http.Client{Timeout: timeout}}
+}
+
+// CreateClientWithCertificate returns an HTTPClient that presents cert for mutual TLS, for use against
+// endpoints that require client certificate authentication.
+func CreateClientWithCertificate(timeout time.Duration, cert tls.Certificate) HTTPClient {
+	return CreateClientWithTLSConfig(timeout, &tls.Config{Certificates: []tls.Certificate{cert}})
+}
+
+// CreateClientWithTLSConfig returns an HTTPClient using tlsConfig verbatim, for callers that need more
+// control than CreateClientWithCertificate, e.g. combining a client certificate with a relaxed
+// InsecureSkipVerify for a development target.
+func CreateClientWithTLSConfig(timeout time.Duration, tlsConfig *tls.Config) HTTPClient {
+	return &defaultHTTPClient{client: &http.Client{Timeout: timeout, Transport: &http.Transport{TLSClientConfig: tlsConfig}}}
+}
+
+func (c *defaultHTTPClient) Do(request *http.Request, timeout time.Duration) (*http.Response, error) {
+	c.client.Timeout = timeout
+	var stats RequestStats
+	var dnsStart, connectStart, tlsStart time.Time
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { stats.DNSLookup = time.Since(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { stats.Connect = time.Since(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { stats.TLSHandshake = time.Since(tlsStart) },
+		GotFirstResponseByte: func() { stats.TimeToFirstByte = time.Since(start) },
+	}
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace))
+	resp, err := c.client.Do(request)
+	stats.Total = time.Since(start)
+	c.mu.Lock()
+	c.stats = stats
+	c.mu.Unlock()
+	return resp, err
+}
+
+// LastRequestStats returns the timing breakdown of the most recent call to Do.
+func (c *defaultHTTPClient) LastRequestStats() RequestStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
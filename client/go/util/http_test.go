@@ -0,0 +1,124 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateCert(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vespa-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, leaf
+}
+
+// TestCreateClientWithCertificateAuthenticatesMTLS proves CreateClientWithCertificate actually presents
+// the configured certificate: a server that only trusts one of two client certificates accepts a request
+// from the matching client and rejects the other, demonstrating that distinct TLSOptions produce distinct,
+// independently-authenticated clients (as used for the API vs. data-plane clients in cmd.CLI).
+func TestCreateClientWithCertificateAuthenticatesMTLS(t *testing.T) {
+	serverCert, serverLeaf := generateCert(t)
+	trustedClientCert, trustedLeaf := generateCert(t)
+	untrustedClientCert, _ := generateCert(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(trustedLeaf)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAs := x509.NewCertPool()
+	serverCAs.AddCert(serverLeaf)
+
+	trustedClient := CreateClientWithTLSConfig(time.Second, &tls.Config{
+		Certificates: []tls.Certificate{trustedClientCert},
+		RootCAs:      serverCAs,
+	})
+	untrustedClient := CreateClientWithTLSConfig(time.Second, &tls.Config{
+		Certificates: []tls.Certificate{untrustedClientCert},
+		RootCAs:      serverCAs,
+	})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	resp, err := trustedClient.Do(req, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	_, err = untrustedClient.Do(req, time.Second)
+	assert.NotNil(t, err)
+}
+
+// TestCreateClientCapturesRequestStats verifies that a defaultHTTPClient (the one CreateClient and friends
+// return) satisfies TimedHTTPClient, and that a real round trip against an httptest server produces a
+// non-zero total and time-to-first-byte, for a command's --stats flag.
+func TestCreateClientCapturesRequestStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := CreateClient(time.Second)
+	timed, ok := client.(TimedHTTPClient)
+	assert.True(t, ok)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	resp, err := timed.Do(req, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stats := timed.LastRequestStats()
+	assert.True(t, stats.Total > 0)
+	assert.True(t, stats.TimeToFirstByte > 0)
+}
+
+// TestCreateClientWithTLSConfigSetsInsecureSkipVerify verifies that CreateClientWithTLSConfig's tlsConfig
+// (in particular InsecureSkipVerify, as cmd.CLI.httpClientWithCertificate sets for --insecure) ends up on
+// the transport the returned HTTPClient actually sends requests through, rather than being dropped.
+func TestCreateClientWithTLSConfigSetsInsecureSkipVerify(t *testing.T) {
+	client := CreateClientWithTLSConfig(time.Second, &tls.Config{InsecureSkipVerify: true})
+
+	c, ok := client.(*defaultHTTPClient)
+	if assert.True(t, ok) {
+		transport, ok := c.client.Transport.(*http.Transport)
+		if assert.True(t, ok) {
+			assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressRateSmoothing weights the most recent rate sample against the running average when computing
+// ProgressBar's ETA: closer to 1 reacts faster to a changing rate (e.g. a visit slowing down against a
+// larger cluster), closer to 0 rides out a single unusually slow or fast page.
+const progressRateSmoothing = 0.3
+
+// ProgressBar renders a progress indicator for an operation whose total size may or may not be known up
+// front. With a known total (bulk feeds, multi-file deploys, a visit whose document count was estimated
+// first) it renders a percentage and an ETA based on an exponentially smoothed rate. Without one, it falls
+// back to printing a running count. On a non-terminal writer it degrades to periodic line prints, the same
+// way the spinner does when running under Screwdriver.
+type ProgressBar struct {
+	writer     io.Writer
+	total      int64
+	done       int64
+	rate       float64 // exponentially smoothed items/sec, 0 until two updates have been observed
+	lastUpdate time.Time
+	lastPrint  time.Time
+	isTerminal bool
+	now        func() time.Time
+}
+
+// NewProgressBar creates a ProgressBar that reports progress against writer. total is the estimated number
+// of items the operation will process; pass 0 (or a negative number) if no estimate is available, in which
+// case the bar reports a running count instead of a percentage and ETA. isTerminal should reflect whether
+// writer is an interactive terminal; when false, the bar prints a new line per update instead of
+// overwriting the current one.
+func NewProgressBar(writer io.Writer, total int64, isTerminal bool) *ProgressBar {
+	return &ProgressBar{writer: writer, total: total, isTerminal: isTerminal, now: time.Now}
+}
+
+// Add advances the progress by n and re-renders the bar.
+func (p *ProgressBar) Add(n int64) {
+	p.sampleRate(n)
+	p.done += n
+	p.render(false)
+}
+
+// Finish renders the bar at its final state and terminates it (with a trailing newline for terminal
+// output).
+func (p *ProgressBar) Finish() {
+	if p.total > 0 {
+		p.done = p.total
+	}
+	p.render(true)
+}
+
+// sampleRate folds n items completed since the last update into the smoothed rate estimate. The first call
+// only establishes a starting point; a rate needs two updates to mean anything.
+func (p *ProgressBar) sampleRate(n int64) {
+	now := p.now()
+	if !p.lastUpdate.IsZero() {
+		if elapsed := now.Sub(p.lastUpdate).Seconds(); elapsed > 0 {
+			sample := float64(n) / elapsed
+			if p.rate == 0 {
+				p.rate = sample
+			} else {
+				p.rate = progressRateSmoothing*sample + (1-progressRateSmoothing)*p.rate
+			}
+		}
+	}
+	p.lastUpdate = now
+}
+
+func (p *ProgressBar) render(final bool) {
+	if !p.isTerminal {
+		// Avoid spamming non-interactive logs: only print every second, plus always on completion.
+		if !final && time.Since(p.lastPrint) < time.Second {
+			return
+		}
+		p.lastPrint = time.Now()
+		fmt.Fprintf(p.writer, "%s\n", p.status())
+		return
+	}
+	fmt.Fprintf(p.writer, "\r%s", p.status())
+	if final {
+		fmt.Fprint(p.writer, "\n")
+	}
+}
+
+// status renders the current done/total count, with a percentage and smoothed ETA appended once total is
+// known; otherwise it falls back to a plain count, since a percentage and ETA need a total to mean anything.
+func (p *ProgressBar) status() string {
+	if p.total <= 0 {
+		return fmt.Sprintf("%d done", p.done)
+	}
+	return fmt.Sprintf("%d/%d done (%s)", p.done, p.total, p.percentAndETA())
+}
+
+// percentAndETA formats the current percentage, plus the ETA at the current smoothed rate once one has
+// been established (the first update never has one) and the operation isn't already done.
+func (p *ProgressBar) percentAndETA() string {
+	percent := 100 * float64(p.done) / float64(p.total)
+	remaining := p.total - p.done
+	if p.rate <= 0 || remaining <= 0 {
+		return fmt.Sprintf("%.0f%%", percent)
+	}
+	eta := time.Duration(float64(remaining) / p.rate * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("%.0f%%, ETA %s", percent, eta)
+}
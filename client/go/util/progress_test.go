@@ -0,0 +1,64 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBarTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, 2, true)
+
+	bar.Add(1)
+	bar.Finish()
+
+	assert.Contains(t, buf.String(), "1/2 done")
+	assert.Contains(t, buf.String(), "2/2 done")
+	assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+}
+
+func TestProgressBarNonTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, 2, false)
+
+	bar.Finish()
+
+	assert.Contains(t, buf.String(), "2/2 done")
+}
+
+func TestProgressBarFallsBackToCountOnlyWithoutEstimate(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, 0, false)
+
+	bar.Add(3)
+
+	assert.Contains(t, buf.String(), "3 done")
+	assert.NotContains(t, buf.String(), "%")
+}
+
+func TestProgressBarShowsPercentBeforeRateIsKnown(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, 4, true)
+
+	bar.Add(1)
+
+	assert.Contains(t, buf.String(), "25%")
+	assert.NotContains(t, buf.String(), "ETA")
+}
+
+func TestProgressBarSmoothsRateForETA(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewProgressBar(&buf, 10, true)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	bar.now = func() time.Time { return now }
+
+	bar.Add(1)
+	now = now.Add(time.Second)
+	bar.Add(1)
+
+	assert.Contains(t, buf.String(), "ETA")
+}
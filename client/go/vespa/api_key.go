@@ -0,0 +1,80 @@
+package vespa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CreateAPIKeyPair generates a new ECDSA P-256 key pair for Vespa Cloud control-plane API authentication,
+// returning the private key and its matching public key, both PEM-encoded.
+func CreateAPIKeyPair() (keyPEM, publicKeyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	publicKeyPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return keyPEM, publicKeyPEM, nil
+}
+
+// VerifyAPIKey makes a lightweight call against the Vespa Cloud control plane to check that it's reachable
+// and responding before a rotated key replaces the one already on disk.
+//
+// This fork does not implement API-key request signing anywhere: control-plane calls are authenticated with
+// mTLS (see CLI.apiHttpClient), and service's http.Client is whatever was already configured for that, not
+// the newly generated key. So this cannot prove the new key itself is accepted the way a real
+// "does this credential work" check would; it only guards against registering a key against a control plane
+// that's unreachable or erroring, which is the most common way a rotation goes wrong in practice.
+func VerifyAPIKey(service *Service, timeout time.Duration) error {
+	req, err := http.NewRequest(http.MethodGet, "/user/v1/user", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("control plane returned status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// RegisterAPIKey registers publicKeyPEM as a valid API key for tenant with the Vespa Cloud control plane
+// behind service, which must be a client for CloudAPIURL.
+func RegisterAPIKey(service *Service, tenant string, publicKeyPEM []byte, timeout time.Duration) error {
+	path := fmt.Sprintf("/user/v1/user?key=%s", url.QueryEscape(string(publicKeyPEM)))
+	req, err := http.NewRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Tenant", tenant)
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to register API key for %s: status %d: %s", tenant, resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package vespa
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestCreateAPIKeyPair(t *testing.T) {
+	keyPEM, publicKeyPEM, err := CreateAPIKeyPair()
+	assert.Nil(t, err)
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if assert.NotNil(t, keyBlock) {
+		_, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+		assert.Nil(t, err)
+	}
+	publicBlock, _ := pem.Decode(publicKeyPEM)
+	if assert.NotNil(t, publicBlock) {
+		_, err := x509.ParsePKIXPublicKey(publicBlock.Bytes)
+		assert.Nil(t, err)
+	}
+}
+
+func TestRegisterAPIKey(t *testing.T) {
+	var req *http.Request
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(r *http.Request, _ time.Duration) (*http.Response, error) {
+		req = r
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}
+	service := testService(httpClient)
+	_, publicKeyPEM, err := CreateAPIKeyPair()
+	assert.Nil(t, err)
+
+	err = RegisterAPIKey(service, "mytenant", publicKeyPEM, time.Second)
+
+	assert.Nil(t, err)
+	if assert.NotNil(t, req) {
+		assert.Equal(t, "mytenant", req.Header.Get("X-Tenant"))
+		key, err := url.QueryUnescape(req.URL.RawQuery[len("key="):])
+		assert.Nil(t, err)
+		assert.Equal(t, string(publicKeyPEM), key)
+	}
+}
+
+func TestRegisterAPIKeyFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 403, Body: closer{strings.NewReader("forbidden")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+	_, publicKeyPEM, err := CreateAPIKeyPair()
+	assert.Nil(t, err)
+
+	err = RegisterAPIKey(service, "mytenant", publicKeyPEM, time.Second)
+
+	assert.NotNil(t, err)
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	assert.Nil(t, VerifyAPIKey(service, time.Second))
+}
+
+func TestVerifyAPIKeyFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader("boom")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	assert.NotNil(t, VerifyAPIKey(service, time.Second))
+}
@@ -0,0 +1,305 @@
+package vespa
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApplicationPackage is a Vespa application package, either as a directory tree or a zip file, ready to be
+// deployed to a Target.
+type ApplicationPackage struct {
+	Path string
+}
+
+// FileManifest maps each file in the package, relative to its root, to the hex-encoded SHA-256 of its content.
+type FileManifest map[string]string
+
+// Files returns the manifest of this package: every regular file it contains, and a hash of its content.
+func (ap ApplicationPackage) Files() (FileManifest, error) {
+	if strings.HasSuffix(ap.Path, ".zip") {
+		return zipManifest(ap.Path)
+	}
+	return dirManifest(ap.Path)
+}
+
+func dirManifest(root string) (FileManifest, error) {
+	manifest := make(FileManifest)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sum, err := hashContent(f)
+		if err != nil {
+			return err
+		}
+		manifest[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	return manifest, err
+}
+
+func zipManifest(path string) (FileManifest, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	manifest := make(FileManifest)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		sum, err := hashContent(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		manifest[f.Name] = sum
+	}
+	return manifest, nil
+}
+
+func hashContent(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readFile returns the content of name (a path relative to the package root, using forward slashes), from
+// either a directory or zip package.
+func (ap ApplicationPackage) readFile(name string) ([]byte, error) {
+	if strings.HasSuffix(ap.Path, ".zip") {
+		r, err := zip.OpenReader(ap.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if f.Name != name {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s: not found in package", name)
+	}
+	return os.ReadFile(filepath.Join(ap.Path, filepath.FromSlash(name)))
+}
+
+// ValidationError is a single problem found by ApplicationPackage.Validate, identifying the file (and, if
+// known, the line) it was found in.
+type ValidationError struct {
+	File    string
+	Line    int // 0 if unknown
+	Message string
+}
+
+func (e ValidationError) String() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// ValidationErrors is returned by ApplicationPackage.Validate when one or more problems are found. Every
+// problem is collected and reported together, rather than failing on the first one.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate performs client-side checks of the package that don't require contacting a config server:
+// services.xml and hosts.xml (if present) are well-formed XML, schema files superficially look like
+// schemas, and files referenced by a "path" attribute (e.g. on <model> or <query-profile> elements) exist
+// in the package. It returns ValidationErrors listing every problem found, or nil if the package looks OK.
+func (ap ApplicationPackage) Validate() error {
+	manifest, err := ap.Files()
+	if err != nil {
+		return err
+	}
+	var errs ValidationErrors
+	if _, ok := manifest["services.xml"]; !ok {
+		errs = append(errs, ValidationError{File: "services.xml", Message: "required file is missing"})
+	} else {
+		data, err := ap.readFile("services.xml")
+		if err != nil {
+			return err
+		}
+		if verr := validateXML(data); verr != nil {
+			errs = append(errs, ValidationError{File: "services.xml", Line: verr.line, Message: verr.message})
+		}
+		errs = append(errs, validateReferencedPaths(data, manifest, "services.xml")...)
+	}
+	if _, ok := manifest["hosts.xml"]; ok {
+		data, err := ap.readFile("hosts.xml")
+		if err != nil {
+			return err
+		}
+		if verr := validateXML(data); verr != nil {
+			errs = append(errs, ValidationError{File: "hosts.xml", Line: verr.line, Message: verr.message})
+		}
+	}
+	for path := range manifest {
+		if strings.HasPrefix(path, "schemas/") && strings.HasSuffix(path, ".sd") {
+			data, err := ap.readFile(path)
+			if err != nil {
+				return err
+			}
+			if message := validateSchema(data); message != "" {
+				errs = append(errs, ValidationError{File: path, Message: message})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// xmlError is a well-formedness problem found by validateXML, with the line it occurred on if known.
+type xmlError struct {
+	line    int
+	message string
+}
+
+// validateXML reports whether data is well-formed XML, returning nil if so.
+func validateXML(data []byte) *xmlError {
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			line := 1 + strings.Count(string(data[:min(len(data), int(decoder.InputOffset()))]), "\n")
+			return &xmlError{line: line, message: err.Error()}
+		}
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// validateSchema performs a superficial sanity check of a .sd file: that its braces balance, and that it
+// contains a schema or search declaration. It does not parse the schema language.
+func validateSchema(data []byte) string {
+	depth := 0
+	for _, r := range string(data) {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return "unbalanced braces: unexpected '}'"
+			}
+		}
+	}
+	if depth != 0 {
+		return "unbalanced braces: missing closing '}'"
+	}
+	content := string(data)
+	if !strings.Contains(content, "schema ") && !strings.Contains(content, "search ") {
+		return "does not look like a schema: expected a \"schema\" or \"search\" declaration"
+	}
+	return ""
+}
+
+// validateReferencedPaths scans data for "path" attributes (e.g. on <model> or <query-profile> elements in
+// services.xml) and checks that each referenced file exists in manifest.
+func validateReferencedPaths(data []byte, manifest FileManifest, file string) ValidationErrors {
+	var errs ValidationErrors
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local != "path" {
+				continue
+			}
+			ref := strings.TrimPrefix(attr.Value, "/")
+			if _, ok := manifest[ref]; !ok {
+				errs = append(errs, ValidationError{
+					File:    file,
+					Message: fmt.Sprintf("<%s path=%q> references a file that does not exist in the package", start.Name.Local, attr.Value),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// PackageDiff describes the differences between two file manifests.
+type PackageDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// IsEmpty reports whether the diff contains no changes.
+func (d PackageDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffManifests compares the active package's manifest against the local one.
+func DiffManifests(active, local FileManifest) PackageDiff {
+	var diff PackageDiff
+	for path, sum := range local {
+		activeSum, ok := active[path]
+		if !ok {
+			diff.Added = append(diff.Added, path)
+		} else if activeSum != sum {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for path := range active {
+		if _, ok := local[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,149 @@
+package vespa
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := ApplicationPackage{Path: dir}
+
+	manifest, err := pkg.Files()
+
+	assert.Nil(t, err)
+	assert.Contains(t, manifest, "services.xml")
+}
+
+func TestZipManifest(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "app.zip")
+	f, err := os.Create(zipPath)
+	assert.Nil(t, err)
+	w := zip.NewWriter(f)
+	fw, err := w.Create("services.xml")
+	assert.Nil(t, err)
+	_, err = fw.Write([]byte("<services/>"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+	pkg := ApplicationPackage{Path: zipPath}
+
+	manifest, err := pkg.Files()
+
+	assert.Nil(t, err)
+	assert.Contains(t, manifest, "services.xml")
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestValidateAcceptsWellFormedPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "services.xml", "<services><container id=\"default\"/></services>")
+	writeFile(t, dir, "schemas/music.sd", "schema music {\n  document music {\n  }\n}")
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	assert.Nil(t, err)
+}
+
+func TestValidateRequiresServicesXml(t *testing.T) {
+	dir := t.TempDir()
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "services.xml")
+	}
+}
+
+func TestValidateRejectsMalformedXml(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "services.xml", "<services><container></services>")
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "services.xml")
+	}
+}
+
+func TestValidateRejectsUnbalancedSchema(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "services.xml", "<services/>")
+	writeFile(t, dir, "schemas/music.sd", "schema music {\n  document music {\n")
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "schemas/music.sd")
+		assert.Contains(t, err.Error(), "unbalanced braces")
+	}
+}
+
+func TestValidateRejectsMissingReferencedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "services.xml", `<services><model path="models/missing.onnx"/></services>`)
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "models/missing.onnx")
+	}
+}
+
+func TestValidateAcceptsExistingReferencedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "services.xml", `<services><model path="models/present.onnx"/></services>`)
+	writeFile(t, dir, "models/present.onnx", "binary-ish-content")
+	pkg := ApplicationPackage{Path: dir}
+
+	err := pkg.Validate()
+
+	assert.Nil(t, err)
+}
+
+func TestValidateOnZipPackage(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "app.zip")
+	f, err := os.Create(zipPath)
+	assert.Nil(t, err)
+	w := zip.NewWriter(f)
+	fw, err := w.Create("services.xml")
+	assert.Nil(t, err)
+	_, err = fw.Write([]byte("<services><container></services>"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+	pkg := ApplicationPackage{Path: zipPath}
+
+	err = pkg.Validate()
+
+	assert.NotNil(t, err)
+}
+
+func TestDiffManifests(t *testing.T) {
+	active := FileManifest{"services.xml": "a", "schemas/music.sd": "b"}
+	local := FileManifest{"services.xml": "a2", "schemas/new.sd": "c"}
+
+	diff := DiffManifests(active, local)
+
+	assert.Equal(t, []string{"schemas/new.sd"}, diff.Added)
+	assert.Equal(t, []string{"schemas/music.sd"}, diff.Removed)
+	assert.Equal(t, []string{"services.xml"}, diff.Changed)
+}
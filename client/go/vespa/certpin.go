@@ -0,0 +1,46 @@
+package vespa
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SPKIPin returns the base64-encoded SHA-256 hash of cert's Subject Public Key Info, the value printed by
+// `vespa status auth --show-pins` and accepted (one or more, comma-separated) by the tls.pin-sha256 config
+// option.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPins returns a tls.Config.VerifyPeerCertificate callback that fails unless at least one of the
+// presented certificates' SPKI hashes matches one of pins, defeating a MITM even if it holds a certificate
+// signed by a CA the client would otherwise trust. It's meant to be set alongside normal certificate
+// verification (i.e. without InsecureSkipVerify), adding this check on top rather than replacing it.
+func VerifyPins(pins []string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	wanted := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		wanted[p] = true
+	}
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		presented := make([]string, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				continue
+			}
+			pin := SPKIPin(cert)
+			if wanted[pin] {
+				return nil
+			}
+			presented = append(presented, pin)
+		}
+		sort.Strings(presented)
+		return fmt.Errorf("certificate pin mismatch: presented fingerprint(s) %s match none of the pinned tls.pin-sha256 value(s)",
+			strings.Join(presented, ", "))
+	}
+}
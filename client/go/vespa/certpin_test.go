@@ -0,0 +1,59 @@
+package vespa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateTestCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vespa-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return cert
+}
+
+func TestSPKIPinIsStableForTheSameKey(t *testing.T) {
+	cert := generateTestCert(t)
+
+	assert.Equal(t, SPKIPin(cert), SPKIPin(cert))
+	assert.NotEmpty(t, SPKIPin(cert))
+}
+
+func TestVerifyPinsAcceptsAMatchingPin(t *testing.T) {
+	cert := generateTestCert(t)
+	verify := VerifyPins([]string{SPKIPin(cert)})
+
+	err := verify([][]byte{cert.Raw}, nil)
+
+	assert.Nil(t, err)
+}
+
+func TestVerifyPinsRejectsAndNamesPresentedFingerprints(t *testing.T) {
+	cert := generateTestCert(t)
+	verify := VerifyPins([]string{"bm90LWEtcmVhbC1waW4tc2hhMjU2LWhhc2g="})
+
+	err := verify([][]byte{cert.Raw}, nil)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "certificate pin mismatch")
+		assert.Contains(t, err.Error(), SPKIPin(cert))
+	}
+}
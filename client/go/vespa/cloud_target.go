@@ -0,0 +1,82 @@
+package vespa
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// CloudAPIURL is the base URL of the Vespa Cloud control plane API, exported so a command that needs to
+// call it without a full CloudTarget (e.g. tenant-level API key management) doesn't have to construct one.
+const CloudAPIURL = "https://api-ctl.vespa-cloud.com"
+
+// Deployment identifies a single instance of an application deployed to Vespa Cloud.
+type Deployment struct {
+	Tenant      string
+	Application string
+	Instance    string
+	Environment string
+	Region      string
+}
+
+// CloudTarget is a Target backed by the Vespa Cloud control plane. Its requests are authenticated only by
+// whatever httpClient is configured with; the API key it holds is not used to sign them.
+type CloudTarget struct {
+	apiKey     string
+	httpClient util.HTTPClient
+	Deployment Deployment
+	// Endpoints holds the data-plane URL(s) known for each container cluster, typically resolved from the
+	// VESPA_CLI_ENDPOINTS environment variable.
+	Endpoints ClusterEndpoints
+}
+
+// NewCloudTarget creates a Target for Vespa Cloud. apiKey is stored on the target but not sent with any
+// request.
+func NewCloudTarget(apiKey string, httpClient util.HTTPClient, deployment Deployment, endpoints ClusterEndpoints) *CloudTarget {
+	return &CloudTarget{apiKey: apiKey, httpClient: httpClient, Deployment: deployment, Endpoints: endpoints}
+}
+
+func (t *CloudTarget) Type() string { return "cloud" }
+
+// ControlService returns the service to use for calls against the Vespa Cloud control plane API, e.g.
+// zone listing, tenant/application management, and deployment orchestration.
+func (t *CloudTarget) ControlService() *Service {
+	return &Service{BaseURL: CloudAPIURL, Name: "controller", httpClient: t.httpClient}
+}
+
+// ContainerService returns the data-plane service for cluster, resolved from Endpoints. If cluster is
+// empty, the single configured cluster is used; with more than one cluster configured, the caller must
+// name one explicitly. A cluster with several URLs is served round-robin with failover, see
+// NewServiceWithFailover.
+func (t *CloudTarget) ContainerService(cluster string) (*Service, error) {
+	if len(t.Endpoints) == 0 {
+		return nil, fmt.Errorf("cloud target: no data-plane endpoints configured, set %s", EndpointsEnv)
+	}
+	name := cluster
+	if name == "" {
+		if len(t.Endpoints) > 1 {
+			return nil, fmt.Errorf("cloud target: application has more than one cluster, specify which one with --cluster")
+		}
+		for only := range t.Endpoints {
+			name = only
+		}
+	}
+	urls, ok := t.Endpoints[name]
+	if !ok {
+		return nil, fmt.Errorf("cloud target: no data-plane endpoint configured for cluster %q, available clusters are: %s", name, strings.Join(t.Endpoints.Names(), ", "))
+	}
+	return NewServiceWithFailover(urls, "container", t.httpClient), nil
+}
+
+func (t *CloudTarget) DeployService() (*Service, error) {
+	return t.ControlService(), nil
+}
+
+func (t *CloudTarget) ActivePackage() (FileManifest, error) {
+	return nil, fmt.Errorf("cloud target: active package listing is not supported")
+}
+
+func (t *CloudTarget) ActivePackageFile(path string) ([]byte, error) {
+	return nil, fmt.Errorf("cloud target: active package download is not supported")
+}
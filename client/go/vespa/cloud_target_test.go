@@ -0,0 +1,49 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudTargetContainerServiceResolvesSingleCluster(t *testing.T) {
+	target := NewCloudTarget("key", nil, Deployment{}, ClusterEndpoints{"container": {"https://a.example.com"}})
+
+	service, err := target.ContainerService("")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://a.example.com", service.BaseURL)
+}
+
+func TestCloudTargetContainerServiceRequiresClusterNameWhenAmbiguous(t *testing.T) {
+	target := NewCloudTarget("key", nil, Deployment{}, ClusterEndpoints{
+		"container": {"https://a.example.com"},
+		"feed":      {"https://b.example.com"},
+	})
+
+	_, err := target.ContainerService("")
+
+	assert.NotNil(t, err)
+}
+
+func TestCloudTargetContainerServiceFailsWithoutEndpoints(t *testing.T) {
+	target := NewCloudTarget("key", nil, Deployment{}, nil)
+
+	_, err := target.ContainerService("container")
+
+	assert.NotNil(t, err)
+}
+
+func TestCloudTargetContainerServiceFailsOnUnknownCluster(t *testing.T) {
+	target := NewCloudTarget("key", nil, Deployment{}, ClusterEndpoints{
+		"container": {"https://a.example.com"},
+		"feed":      {"https://b.example.com"},
+	})
+
+	_, err := target.ContainerService("other")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `"other"`)
+		assert.Contains(t, err.Error(), "container, feed")
+	}
+}
@@ -0,0 +1,46 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// NodeStatus is the up/down state and config generation of a single node, as reported by its cluster
+// controller's service view.
+type NodeStatus struct {
+	Cluster    string `json:"clusterId"`
+	Hostname   string `json:"hostname"`
+	State      string `json:"state"`
+	Generation int64  `json:"generation"`
+}
+
+// FetchClusterStatus returns the per-node state and generation of every node behind service, as reported
+// by the cluster controller's service view API.
+func FetchClusterStatus(service *Service, timeout time.Duration) ([]NodeStatus, error) {
+	req, err := http.NewRequest(http.MethodGet, "/cluster/v2/state", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to get cluster status: status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Nodes []NodeStatus `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Nodes, nil
+}
@@ -0,0 +1,43 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const clusterStatusJSON = `{
+	"nodes": [
+		{"clusterId": "content", "hostname": "node-1", "state": "up", "generation": 3},
+		{"clusterId": "content", "hostname": "node-2", "state": "down", "generation": 2}
+	]
+}`
+
+func TestFetchClusterStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(clusterStatusJSON)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	nodes, err := FetchClusterStatus(service, time.Second)
+
+	assert.Nil(t, err)
+	if assert.Len(t, nodes, 2) {
+		assert.Equal(t, "up", nodes[0].State)
+		assert.EqualValues(t, 3, nodes[0].Generation)
+		assert.Equal(t, "down", nodes[1].State)
+	}
+}
+
+func TestFetchClusterStatusFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader("boom")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := FetchClusterStatus(service, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "status 500")
+	}
+}
@@ -0,0 +1,20 @@
+package vespa
+
+import "encoding/json"
+
+// controllerErrorBody is the JSON error shape returned by the Vespa Cloud controller API for a non-2xx
+// response, e.g. {"error-code": "FORBIDDEN", "message": "Access denied"}.
+type controllerErrorBody struct {
+	Message string `json:"message"`
+}
+
+// ControllerErrorMessage extracts the human-readable "message" field from a controller API error response
+// body, so a command can report e.g. "Access denied" instead of the raw JSON blob. If body isn't JSON, or
+// has no message, body itself is returned unchanged.
+func ControllerErrorMessage(body []byte) string {
+	var e controllerErrorBody
+	if err := json.Unmarshal(body, &e); err == nil && e.Message != "" {
+		return e.Message
+	}
+	return string(body)
+}
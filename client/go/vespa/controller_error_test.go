@@ -0,0 +1,19 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerErrorMessageExtractsMessageField(t *testing.T) {
+	assert.Equal(t, "Access denied", ControllerErrorMessage([]byte(`{"error-code": "FORBIDDEN", "message": "Access denied"}`)))
+}
+
+func TestControllerErrorMessageFallsBackToRawBodyWhenNotJSON(t *testing.T) {
+	assert.Equal(t, "not json", ControllerErrorMessage([]byte("not json")))
+}
+
+func TestControllerErrorMessageFallsBackToRawBodyWhenMessageIsMissing(t *testing.T) {
+	assert.Equal(t, `{"error-code": "FORBIDDEN"}`, ControllerErrorMessage([]byte(`{"error-code": "FORBIDDEN"}`)))
+}
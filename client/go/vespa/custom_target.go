@@ -0,0 +1,107 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// CustomTarget is a Target pointing at user-supplied URL(s), typically a self-hosted Vespa instance.
+type CustomTarget struct {
+	url        string
+	deployURLs []string
+	httpClient util.HTTPClient
+}
+
+// NewCustomTarget creates a Target for a self-hosted Vespa instance: url serves data-plane (query,
+// document) requests and deployURLs serve deploy/config-server requests. Passing nil or an empty slice for
+// deployURLs makes it default to []string{url}, the common case of a single port or a reverse proxy
+// unifying both behind one path; the two differ for the traditional two-port self-hosted setup (the
+// container on :8080, the config server on :19071). Passing more than one deployURL is for a multi-config-
+// server installation: DeployService fails over between them in order, and sticks to whichever one last
+// succeeded, since a deployment session (prepare, then activate) must stay on the same config server.
+func NewCustomTarget(url string, deployURLs []string, httpClient util.HTTPClient) *CustomTarget {
+	if len(deployURLs) == 0 {
+		deployURLs = []string{url}
+	}
+	return &CustomTarget{url: url, deployURLs: deployURLs, httpClient: httpClient}
+}
+
+func (t *CustomTarget) Type() string { return "custom" }
+
+// ContainerService returns the data-plane service at the target's single configured URL. A custom target
+// has no notion of multiple named clusters, so cluster is accepted but otherwise ignored, the same way
+// --cluster is a no-op against a self-hosted instance with a single container cluster.
+func (t *CustomTarget) ContainerService(cluster string) (*Service, error) {
+	return &Service{BaseURL: t.url, Name: "container", httpClient: t.httpClient}, nil
+}
+
+// DeployService returns the deploy/config-server service. When more than one config server URL is
+// configured, it fails over between them in order, sticking to whichever one last succeeded: a deployment
+// session (prepare, then activate) must land on the same config server every time, not round-robin.
+func (t *CustomTarget) DeployService() (*Service, error) {
+	if len(t.deployURLs) == 1 {
+		return &Service{BaseURL: t.deployURLs[0], Name: "deploy", httpClient: t.httpClient}, nil
+	}
+	return NewServiceWithStickyFailover(t.deployURLs, "deploy", t.httpClient), nil
+}
+
+// contentEntry is a single file as reported by the config server's application content API.
+type contentEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+func (t *CustomTarget) ActivePackage() (FileManifest, error) {
+	service, err := t.DeployService()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, "/application/v2/tenant/default/application/default/content/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var entries []contentEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+	manifest := make(FileManifest, len(entries))
+	for _, e := range entries {
+		manifest[e.Path] = e.Hash
+	}
+	return manifest, nil
+}
+
+func (t *CustomTarget) ActivePackageFile(path string) ([]byte, error) {
+	service, err := t.DeployService()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, "/application/v2/tenant/default/application/default/content/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,114 @@
+package vespa
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestCustomTargetRoutesContainerAndDeployToDistinctURLs(t *testing.T) {
+	target := NewCustomTarget("http://container:8080", []string{"http://configserver:19071"}, &mock.HTTPClient{})
+
+	containerService, err := target.ContainerService("")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://container:8080", containerService.BaseURL)
+
+	deployService, err := target.DeployService()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://configserver:19071", deployService.BaseURL)
+}
+
+func TestCustomTargetDeployURLDefaultsToURL(t *testing.T) {
+	target := NewCustomTarget("http://localhost:8080", nil, &mock.HTTPClient{})
+
+	containerService, err := target.ContainerService("")
+	assert.Nil(t, err)
+	deployService, err := target.DeployService()
+	assert.Nil(t, err)
+
+	assert.Equal(t, "http://localhost:8080", containerService.BaseURL)
+	assert.Equal(t, "http://localhost:8080", deployService.BaseURL)
+}
+
+// TestCustomTargetDeployFailsOverToSecondConfigServer verifies that DeployService, when given more than one
+// config server URL, retries the next one when the first refuses the connection.
+func TestCustomTargetDeployFailsOverToSecondConfigServer(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.URL.Host == "configserver1:19071" {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}
+	target := NewCustomTarget("http://container:8080", []string{"http://configserver1:19071", "http://configserver2:19071"}, httpClient)
+	service, err := target.DeployService()
+	assert.Nil(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.Nil(t, err)
+	resp, err := service.Do(req, time.Second)
+	assert.Nil(t, err)
+	resp.Body.Close()
+}
+
+// TestCustomTargetDeploySticksToConfigServerThatSucceeded verifies that once a config server has answered
+// successfully, DeployService's service tries it first on a later call instead of round-robining away from
+// it, the session affinity a deploy's prepare-then-activate sequence depends on.
+func TestCustomTargetDeploySticksToConfigServerThatSucceeded(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	var hitConfigserver1, triedConfigserver1 bool
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if req.URL.Host == "configserver1:19071" {
+			triedConfigserver1 = true
+			return nil, errors.New("connection refused")
+		}
+		hitConfigserver1 = req.URL.Host == "configserver2:19071"
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}
+	target := NewCustomTarget("http://container:8080", []string{"http://configserver1:19071", "http://configserver2:19071"}, httpClient)
+	service, err := target.DeployService()
+	assert.Nil(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/prepare", nil)
+	assert.Nil(t, err)
+	resp, err := service.Do(req, time.Second)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.True(t, hitConfigserver1)
+
+	hitConfigserver1 = false
+	triedConfigserver1 = false
+	req, err = http.NewRequest(http.MethodGet, "/activate", nil)
+	assert.Nil(t, err)
+	resp, err = service.Do(req, time.Second)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.True(t, hitConfigserver1, "second call should stick to configserver2 without retrying configserver1")
+	assert.False(t, triedConfigserver1, "second call should not retry configserver1")
+}
+
+// TestCustomTargetDeployErrorListsEveryAttemptedConfigServer verifies that the error returned when every
+// config server fails names each one, not just the last.
+func TestCustomTargetDeployErrorListsEveryAttemptedConfigServer(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	httpClient.DoFunc = func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		return nil, fmt.Errorf("connection refused to %s", req.URL.Host)
+	}
+	target := NewCustomTarget("http://container:8080", []string{"http://configserver1:19071", "http://configserver2:19071"}, httpClient)
+	service, err := target.DeployService()
+	assert.Nil(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "configserver1:19071")
+		assert.Contains(t, err.Error(), "configserver2:19071")
+	}
+}
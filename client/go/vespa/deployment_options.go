@@ -0,0 +1,45 @@
+package vespa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeploymentOptions holds options that affect how a deployment job runs, as opposed to ApplicationPackage,
+// which describes what is deployed. It's kept separate so a future upload/prepare/activate pipeline can grow
+// more run-time options (e.g. feature flags passed to the job) without overloading the package type.
+type DeploymentOptions struct {
+	// EnvVars are made available as environment variables to the deployment job, e.g. for secrets a
+	// deployment needs at runtime that shouldn't be committed to the application package.
+	EnvVars map[string]string
+}
+
+// ParseEnvVars parses args, each a "key=value" pair (as produced by a repeatable --env-var flag), into a
+// DeploymentOptions. fallback is merged in for any key not already set by args, keyed by stripping the given
+// prefix from each of fallback's keys, so VESPA_CLI_DEPLOY_ENV_FOO=bar can set FOO=bar without a flag.
+func ParseEnvVars(args []string, fallback map[string]string, fallbackPrefix string) (DeploymentOptions, error) {
+	envVars := make(map[string]string)
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return DeploymentOptions{}, fmt.Errorf("invalid --env-var %q: must be key=value", arg)
+		}
+		envVars[key] = value
+	}
+	for name, value := range fallback {
+		if !strings.HasPrefix(name, fallbackPrefix) {
+			continue
+		}
+		key := name[len(fallbackPrefix):]
+		if key == "" {
+			continue
+		}
+		if _, set := envVars[key]; !set {
+			envVars[key] = value
+		}
+	}
+	if len(envVars) == 0 {
+		return DeploymentOptions{}, nil
+	}
+	return DeploymentOptions{EnvVars: envVars}, nil
+}
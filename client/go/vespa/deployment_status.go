@@ -0,0 +1,85 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DeploymentJob is the most recent run of a single job (e.g. "system-test", "staging-test", or
+// "production-us-east-3") in an application's deployment pipeline.
+type DeploymentJob struct {
+	Name   string    `json:"jobName"`
+	Status string    `json:"status"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Build  int64     `json:"build"`
+}
+
+// Failed reports whether the job's most recent run did not succeed.
+func (j DeploymentJob) Failed() bool {
+	return j.Status != "" && j.Status != "success" && j.Status != "running"
+}
+
+// RegionDeployment is the build currently deployed to a single production region.
+type RegionDeployment struct {
+	Region string `json:"region"`
+	Build  int64  `json:"build"`
+}
+
+// DeploymentStatus is an application's full deployment pipeline status, as returned by the controller.
+type DeploymentStatus struct {
+	Jobs    []DeploymentJob
+	Regions []RegionDeployment
+}
+
+// FailingJob returns the first job whose most recent run failed, if any.
+func (s DeploymentStatus) FailingJob() (DeploymentJob, bool) {
+	for _, j := range s.Jobs {
+		if j.Failed() {
+			return j, true
+		}
+	}
+	return DeploymentJob{}, false
+}
+
+// RolledOutEverywhere reports whether build is the deployed build in every production region.
+func (s DeploymentStatus) RolledOutEverywhere(build int64) bool {
+	if len(s.Regions) == 0 {
+		return false
+	}
+	for _, r := range s.Regions {
+		if r.Build != build {
+			return false
+		}
+	}
+	return true
+}
+
+// FetchDeploymentStatus fetches the deployment pipeline status for d's application from the controller.
+func FetchDeploymentStatus(service *Service, d Deployment, timeout time.Duration) (DeploymentStatus, error) {
+	path := fmt.Sprintf("/application/v4/tenant/%s/application/%s/instance/%s/deployment", d.Tenant, d.Application, d.Instance)
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return DeploymentStatus{}, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return DeploymentStatus{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DeploymentStatus{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return DeploymentStatus{}, fmt.Errorf("failed to fetch deployment status: status %d: %s", resp.StatusCode, body)
+	}
+	var status DeploymentStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return DeploymentStatus{}, err
+	}
+	return status, nil
+}
@@ -0,0 +1,44 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const deploymentStatusJSON = `{
+	"jobs": [
+		{"jobName": "system-test", "status": "success", "build": 42},
+		{"jobName": "production-us-east-3", "status": "failure", "build": 41}
+	],
+	"regions": [
+		{"region": "us-east-3", "build": 41},
+		{"region": "us-west-1", "build": 42}
+	]
+}`
+
+func TestFetchDeploymentStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(deploymentStatusJSON)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	status, err := FetchDeploymentStatus(service, Deployment{Tenant: "t", Application: "a", Instance: "default"}, time.Second)
+
+	assert.Nil(t, err)
+	assert.Len(t, status.Jobs, 2)
+	failing, ok := status.FailingJob()
+	assert.True(t, ok)
+	assert.Equal(t, "production-us-east-3", failing.Name)
+	assert.False(t, status.RolledOutEverywhere(42))
+	assert.False(t, status.RolledOutEverywhere(41))
+}
+
+func TestDeploymentStatusRolledOutEverywhere(t *testing.T) {
+	status := DeploymentStatus{Regions: []RegionDeployment{{Region: "us-east-3", Build: 42}, {Region: "us-west-1", Build: 42}}}
+
+	assert.True(t, status.RolledOutEverywhere(42))
+	assert.False(t, status.RolledOutEverywhere(41))
+}
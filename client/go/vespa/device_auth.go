@@ -0,0 +1,94 @@
+package vespa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DeviceCode is the response to an OAuth 2.0 device authorization request (RFC 8628): the code to poll
+// token endpoint with, and the code and URL to present to the user for out-of-band verification.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri_complete"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestDeviceCode starts a device authorization flow against service for clientID.
+func RequestDeviceCode(service *Service, clientID string, timeout time.Duration) (DeviceCode, error) {
+	form := url.Values{"client_id": {clientID}, "scope": {"openid profile email offline_access"}}
+	req, err := http.NewRequest(http.MethodPost, "/oauth/device/code", strings.NewReader(form.Encode()))
+	if err != nil {
+		return DeviceCode{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return DeviceCode{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DeviceCode{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return DeviceCode{}, fmt.Errorf("failed to start login: status %d: %s", resp.StatusCode, body)
+	}
+	var code DeviceCode
+	if err := json.Unmarshal(body, &code); err != nil {
+		return DeviceCode{}, err
+	}
+	return code, nil
+}
+
+// ErrAuthorizationPending is returned by PollDeviceToken while the user has not yet completed
+// verification. Callers should wait Interval seconds and poll again.
+var ErrAuthorizationPending = errors.New("authorization pending")
+
+// PollDeviceToken makes a single attempt to exchange deviceCode for a refresh token.
+func PollDeviceToken(service *Service, clientID, deviceCode string, timeout time.Duration) (string, error) {
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {clientID},
+	}
+	req, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		RefreshToken string `json:"refresh_token"`
+		Error        string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+	switch result.Error {
+	case "":
+	case "authorization_pending", "slow_down":
+		return "", ErrAuthorizationPending
+	default:
+		return "", fmt.Errorf("login failed: %s", result.Error)
+	}
+	if result.RefreshToken == "" {
+		return "", fmt.Errorf("login failed: token response contained no refresh token")
+	}
+	return result.RefreshToken, nil
+}
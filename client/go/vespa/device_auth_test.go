@@ -0,0 +1,52 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestRequestDeviceCode(t *testing.T) {
+	body := `{"device_code": "d", "user_code": "ABCD-EFGH", "verification_uri_complete": "https://example.com/activate?user_code=ABCD-EFGH", "expires_in": 900, "interval": 5}`
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(body)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	code, err := RequestDeviceCode(service, "client-id", time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+	assert.Equal(t, 5, code.Interval)
+}
+
+func TestPollDeviceTokenPending(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(`{"error": "authorization_pending"}`)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := PollDeviceToken(service, "client-id", "device-code", time.Second)
+
+	assert.Equal(t, ErrAuthorizationPending, err)
+}
+
+func TestPollDeviceTokenSuccess(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(`{"refresh_token": "rt-123"}`)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	token, err := PollDeviceToken(service, "client-id", "device-code", time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "rt-123", token)
+}
+
+func TestPollDeviceTokenDenied(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(`{"error": "access_denied"}`)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := PollDeviceToken(service, "client-id", "device-code", time.Second)
+
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ErrAuthorizationPending, err)
+}
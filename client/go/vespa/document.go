@@ -0,0 +1,373 @@
+package vespa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation is a document operation type, as understood by the /document/v1 API.
+type Operation int
+
+const (
+	OperationPut Operation = iota
+	OperationUpdate
+	OperationRemove
+)
+
+func (op Operation) String() string {
+	switch op {
+	case OperationPut:
+		return "put"
+	case OperationUpdate:
+		return "update"
+	case OperationRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+func (op Operation) method() string {
+	if op == OperationRemove {
+		return http.MethodDelete
+	}
+	return http.MethodPost
+}
+
+// OperationOptions configures how a document operation is sent, replacing the long, repeated parameter
+// lists the put/update/remove/send functions would otherwise each need.
+type OperationOptions struct {
+	// Compress gzip-compresses the request body.
+	Compress bool
+	// RetryOnThrottle automatically retries, honoring Retry-After, when the server responds with 429 or a
+	// throttling 503.
+	RetryOnThrottle bool
+	// RetryUnsafe acknowledges that retrying a non-idempotent update on throttle may double-apply it. It is
+	// ignored for a put or remove, since either is always safe to retry regardless of content.
+	RetryUnsafe bool
+	// Condition is a document selection expression used for test-and-set: the operation is only applied if
+	// the document currently stored at the target ID matches it. Sent as the "condition" query parameter.
+	// Ignored if empty.
+	Condition string
+	// ConditionType selects how Condition is enforced. Must be "", "test-and-set" (the default, used when
+	// empty), or "all", which additionally allows the operation to apply when no document exists yet.
+	ConditionType string
+}
+
+// OperationResult holds the outcome of a single document operation.
+type OperationResult struct {
+	Success bool
+	Status  int
+	Message string
+	// Detail holds extra context that doesn't belong in Message: how many attempts a throttled operation
+	// took before giving up, or that a gzip-compressed request was retried uncompressed after a 415.
+	Detail string
+}
+
+// feedDocument is the subset of a /document/v1 feed file entry that we care about.
+type feedDocument struct {
+	Id     string          `json:"id"`
+	Fields json.RawMessage `json:"fields"`
+}
+
+// documentIdFromData extracts the document ID from a feed document body, e.g. {"id": "id:ns:type::name", ...}.
+func documentIdFromData(data []byte) (string, error) {
+	var doc feedDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("invalid document JSON: %w", err)
+	}
+	return doc.Id, nil
+}
+
+// readDocumentFile reads fileName, transparently gunzipping its contents if the name ends in ".gz". This
+// lets a feed file be compressed at rest without the caller having to know or care.
+func readDocumentFile(fileName string) ([]byte, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(fileName), ".gz") {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: not a valid gzip file: %w", fileName, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// PutOperation sends the document in fileName as a put operation, using documentId if non-empty, otherwise
+// the ID found inside the file.
+func PutOperation(fileName, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	data, err := readDocumentFile(fileName)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	return PutOperationWithData(data, documentId, service, timeout, opts)
+}
+
+// PutOperationWithData is equivalent to PutOperation, but reads the operation body from data instead of a file.
+func PutOperationWithData(data []byte, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	return sendOperation(OperationPut, data, documentId, service, timeout, opts)
+}
+
+// UpdateOperation sends the document update in fileName. If opts.RetryOnThrottle is set and the update
+// contains a non-idempotent field update (e.g. increment or add), opts.RetryUnsafe must also be set,
+// acknowledging that retrying may double-apply the update.
+func UpdateOperation(fileName, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	data, err := readDocumentFile(fileName)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	return UpdateOperationWithData(data, documentId, service, timeout, opts)
+}
+
+// UpdateOperationWithData is equivalent to UpdateOperation, but reads the operation body from data instead of a file.
+func UpdateOperationWithData(data []byte, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	return sendOperation(OperationUpdate, data, documentId, service, timeout, opts)
+}
+
+// RemoveOperation removes the document identified by documentId, or by the ID found in fileName if
+// documentId is empty.
+func RemoveOperation(fileName, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	var data []byte
+	if fileName != "" {
+		d, err := readDocumentFile(fileName)
+		if err != nil {
+			return OperationResult{}, err
+		}
+		data = d
+	}
+	return RemoveOperationWithData(data, documentId, service, timeout, opts)
+}
+
+// RemoveOperationWithData is equivalent to RemoveOperation, but reads the operation body (if any) from data
+// instead of a file.
+func RemoveOperationWithData(data []byte, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	return sendOperation(OperationRemove, data, documentId, service, timeout, opts)
+}
+
+// SendOperation sends the document in fileName as operation. The operation is used as given; it is not
+// inferred from the document's contents.
+func SendOperation(fileName, documentId string, operation Operation, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	data, err := readDocumentFile(fileName)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	return SendOperationWithData(data, documentId, operation, service, timeout, opts)
+}
+
+// SendOperationWithData is equivalent to SendOperation, but reads the operation body from data instead of a file.
+func SendOperationWithData(data []byte, documentId string, operation Operation, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	return sendOperation(operation, data, documentId, service, timeout, opts)
+}
+
+// maxThrottleRetries bounds the number of additional attempts sendOperation makes when retryOnThrottle is
+// set and the server keeps responding with a throttling status, so a persistently overloaded endpoint
+// doesn't retry forever.
+const maxThrottleRetries = 5
+
+// sleep is an indirection over time.Sleep, so tests can skip the real wait between throttled retries.
+var sleep = time.Sleep
+
+// idempotencyKeyHeader carries a deterministic key identifying a document operation, so a server-side dedup
+// check can recognize a retried request as a repeat of the same operation rather than a new one.
+const idempotencyKeyHeader = "X-Vespa-Idempotency-Key"
+
+// nonIdempotentUpdateOperators are the /document/v1 field update operators that compound when applied more
+// than once, e.g. retrying a timed-out "increment" can double-apply it if the first attempt actually
+// succeeded server-side.
+var nonIdempotentUpdateOperators = map[string]bool{
+	"increment": true,
+	"decrement": true,
+	"multiply":  true,
+	"divide":    true,
+	"add":       true,
+}
+
+// IsIdempotent reports whether retrying operation with data is always safe. A put or remove is idempotent
+// regardless of content, since repeating either one leaves the document in the same state. An update is
+// idempotent only if every field update it contains uses "assign" rather than an operator from
+// nonIdempotentUpdateOperators.
+func IsIdempotent(operation Operation, data []byte) bool {
+	if operation != OperationUpdate {
+		return true
+	}
+	var doc struct {
+		Fields map[string]map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return true
+	}
+	for _, fieldUpdate := range doc.Fields {
+		for operator := range fieldUpdate {
+			if nonIdempotentUpdateOperators[operator] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// idempotencyKey deterministically derives a key for operation against documentId with body data, so the
+// same logical operation, retried later with identical content, produces the same key.
+func idempotencyKey(operation Operation, documentId string, data []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", operation, documentId)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sendOperation(operation Operation, data []byte, documentId string, service *Service, timeout time.Duration, opts OperationOptions) (OperationResult, error) {
+	id := documentId
+	if len(data) > 0 {
+		dataId, err := documentIdFromData(data)
+		if err != nil {
+			return OperationResult{}, err
+		}
+		if id == "" {
+			id = dataId
+		}
+	}
+	if id == "" {
+		return OperationResult{}, fmt.Errorf("no document id given, and none found in document body")
+	}
+	if opts.RetryOnThrottle && !opts.RetryUnsafe && !IsIdempotent(operation, data) {
+		return OperationResult{}, fmt.Errorf("refusing to retry a non-idempotent update on throttle: pass retryUnsafe (--retry-unsafe) to acknowledge that retrying may double-apply it")
+	}
+	parsedId, err := ParseDocumentId(id)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	path, err := documentPathWithCondition(parsedId.DocumentPath(), opts)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	key := idempotencyKey(operation, id, data)
+	compressed := opts.Compress && len(data) > 0
+	detail := ""
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		body := data
+		if compressed {
+			gzipped, err := gzipCompress(data)
+			if err != nil {
+				return OperationResult{}, err
+			}
+			body = gzipped
+		}
+		req, err := http.NewRequest(operation.method(), path, bytes.NewReader(body))
+		if err != nil {
+			return OperationResult{}, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyKeyHeader, key)
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		resp, err := service.Do(req, timeout)
+		if err != nil {
+			return OperationResult{}, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return OperationResult{}, err
+		}
+		if compressed && resp.StatusCode == http.StatusUnsupportedMediaType {
+			compressed = false
+			detail = "server rejected the gzip-compressed request with status 415, retried uncompressed"
+			continue
+		}
+		result := OperationResult{
+			Success: resp.StatusCode/100 == 2,
+			Status:  resp.StatusCode,
+			Message: string(respBody),
+			Detail:  detail,
+		}
+		throttled, retryAfter, hasRetryAfter := throttleStatus(resp)
+		if !throttled {
+			return result, nil
+		}
+		if !opts.RetryOnThrottle || attempt > maxThrottleRetries {
+			result.Detail = fmt.Sprintf("throttled (status %d) after %d attempt(s)", resp.StatusCode, attempt)
+			return result, nil
+		}
+		wait := backoff
+		if hasRetryAfter {
+			wait = retryAfter
+		} else {
+			backoff *= 2
+		}
+		sleep(wait)
+	}
+}
+
+// throttleStatus reports whether resp indicates the server is throttling the client (429, or 503 with a
+// Retry-After header), and the duration it asked the client to wait, if any.
+func throttleStatus(resp *http.Response) (throttled bool, retryAfter time.Duration, hasRetryAfter bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter, hasRetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return true, retryAfter, hasRetryAfter
+	case resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "":
+		retryAfter, hasRetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return true, retryAfter, hasRetryAfter
+	default:
+		return false, 0, false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds, e.g. "Retry-After: 2". It does not support
+// the less common HTTP-date form; ok is false if header is empty or not a valid non-negative integer, in
+// which case the caller should fall back to its own backoff.
+func parseRetryAfter(header string) (d time.Duration, ok bool) {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// documentPathWithCondition appends opts.Condition and opts.ConditionType, if set, to path as query
+// parameters. ConditionType, if non-empty, must be "test-and-set" or "all".
+func documentPathWithCondition(path string, opts OperationOptions) (string, error) {
+	if opts.ConditionType != "" && opts.ConditionType != "test-and-set" && opts.ConditionType != "all" {
+		return "", fmt.Errorf(`invalid condition type %q: must be "test-and-set" or "all"`, opts.ConditionType)
+	}
+	if opts.Condition == "" {
+		return path, nil
+	}
+	query := url.Values{}
+	query.Set("condition", opts.Condition)
+	if opts.ConditionType != "" {
+		query.Set("conditionType", opts.ConditionType)
+	}
+	return path + "?" + query.Encode(), nil
+}
+
+// gzipCompress returns data compressed as a gzip member.
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
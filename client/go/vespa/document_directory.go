@@ -0,0 +1,105 @@
+package vespa
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirectoryPutResult is the outcome of feeding a single file during a PutDirectory call.
+type DirectoryPutResult struct {
+	File   string
+	Result OperationResult
+	Err    error
+}
+
+// DirectoryPutSummary is the overall outcome of a PutDirectory call.
+type DirectoryPutSummary struct {
+	FileCount int
+	Success   int
+	Failed    int
+}
+
+// PutDirectory feeds every *.json file directly inside dir, or, if recursive, inside dir and every
+// subdirectory, as a put operation, discovering each document's ID from the "id" field in its own content,
+// the same way PutOperation does for a single file given no explicit ID. This is for a dataset exported as
+// one file per document, which would otherwise need a shell loop around single-document puts. Up to
+// maxInflight files are sent at once, the same bounded-concurrency approach Feeder uses for a JSON-lines
+// feed file, just with one operation per file instead of one per line. onResult, if set, is called once per
+// file as soon as its result is known, in no particular order, so a caller can report progress without
+// waiting for the whole directory to finish.
+func PutDirectory(dir string, recursive bool, service *Service, timeout time.Duration, opts OperationOptions, maxInflight int, onResult func(DirectoryPutResult)) (DirectoryPutSummary, error) {
+	files, err := jsonFilesIn(dir, recursive)
+	if err != nil {
+		return DirectoryPutSummary{}, err
+	}
+	if len(files) == 0 {
+		return DirectoryPutSummary{}, fmt.Errorf("%s: no *.json files found", dir)
+	}
+
+	sem := make(chan struct{}, maxInflight)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var success, failed int
+	for _, file := range files {
+		file := file
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result, err := PutOperation(file, "", service, timeout, opts)
+			mu.Lock()
+			if err != nil || !result.Success {
+				failed++
+			} else {
+				success++
+			}
+			mu.Unlock()
+			if onResult != nil {
+				onResult(DirectoryPutResult{File: file, Result: result, Err: err})
+			}
+		}()
+	}
+	wg.Wait()
+	return DirectoryPutSummary{FileCount: len(files), Success: success, Failed: failed}, nil
+}
+
+// jsonFilesIn returns every *.json file directly inside dir, or, if recursive, inside dir and every
+// subdirectory, sorted for deterministic output.
+func jsonFilesIn(dir string, recursive bool) ([]string, error) {
+	var files []string
+	if !recursive {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+				continue
+			}
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,119 @@
+package vespa
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func writeDocFile(t *testing.T, path, id string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := fmt.Sprintf(`{"id": %q, "fields": {"title": "x"}}`, id)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutDirectoryFeedsEveryJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	writeDocFile(t, filepath.Join(dir, "a.json"), "id:ns:type::a")
+	writeDocFile(t, filepath.Join(dir, "b.json"), "id:ns:type::b")
+	writeDocFile(t, filepath.Join(dir, "c.txt"), "id:ns:type::c") // not *.json, should be skipped
+
+	var mu sync.Mutex
+	var paths []string
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		mu.Lock()
+		paths = append(paths, req.URL.Path)
+		mu.Unlock()
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	summary, err := PutDirectory(dir, false, service, time.Second, OperationOptions{}, 8, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.FileCount)
+	assert.Equal(t, 2, summary.Success)
+	assert.Equal(t, 0, summary.Failed)
+	assert.ElementsMatch(t, []string{"/document/v1/ns/type/docid/a", "/document/v1/ns/type/docid/b"}, paths)
+}
+
+func TestPutDirectoryNonRecursiveSkipsSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeDocFile(t, filepath.Join(dir, "a.json"), "id:ns:type::a")
+	writeDocFile(t, filepath.Join(dir, "nested", "b.json"), "id:ns:type::b")
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	summary, err := PutDirectory(dir, false, service, time.Second, OperationOptions{}, 8, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.FileCount)
+}
+
+func TestPutDirectoryRecursiveDescendsIntoSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeDocFile(t, filepath.Join(dir, "a.json"), "id:ns:type::a")
+	writeDocFile(t, filepath.Join(dir, "nested", "b.json"), "id:ns:type::b")
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	summary, err := PutDirectory(dir, true, service, time.Second, OperationOptions{}, 8, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.FileCount)
+}
+
+func TestPutDirectoryReportsPerFileFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeDocFile(t, filepath.Join(dir, "a.json"), "id:ns:type::a")
+	writeDocFile(t, filepath.Join(dir, "b.json"), "id:ns:type::b")
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/a") {
+			return &http.Response{StatusCode: 500, Body: closer{strings.NewReader("error")}, Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+	var mu sync.Mutex
+	var failedFiles []string
+	summary, err := PutDirectory(dir, false, service, time.Second, OperationOptions{}, 8, func(r DirectoryPutResult) {
+		if !r.Result.Success {
+			mu.Lock()
+			failedFiles = append(failedFiles, r.File)
+			mu.Unlock()
+		}
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 1, summary.Success)
+	assert.Equal(t, []string{filepath.Join(dir, "a.json")}, failedFiles)
+}
+
+func TestPutDirectoryErrorsWhenEmpty(t *testing.T) {
+	dir := t.TempDir()
+	service := testService(&mock.HTTPClient{})
+
+	_, err := PutDirectory(dir, false, service, time.Second, OperationOptions{}, 8, nil)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "no *.json files found")
+	}
+}
@@ -0,0 +1,110 @@
+package vespa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DocumentId is a parsed Vespa document identifier, e.g. "id:mynamespace:music::a-head-full-of-dreams" or
+// "id:mynamespace:music:n=123:a-head-full-of-dreams".
+type DocumentId struct {
+	Namespace    string
+	Type         string
+	Number       *int64
+	Group        string
+	UserSpecific string
+}
+
+// ParseDocumentId parses raw as a document ID of the form "id:namespace:type:[n=number|g=group]:user-specific".
+// The modifier segment (n=number or g=group) is optional, but the segment itself must still be present, even
+// if empty, so a valid plain ID has at least four colons: "id:namespace:type::user-specific". Any colon
+// after the fourth belongs to the user-specific part.
+func ParseDocumentId(raw string) (DocumentId, error) {
+	if !strings.HasPrefix(raw, "id:") {
+		return DocumentId{}, fmt.Errorf("invalid document id %q: must start with \"id:\"", raw)
+	}
+	parts := strings.SplitN(raw[len("id:"):], ":", 4)
+	if len(parts) != 4 {
+		return DocumentId{}, fmt.Errorf("invalid document id %q: must have the form id:namespace:type:[n=number|g=group]:user-specific", raw)
+	}
+	namespace, docType, modifier, userSpecific := parts[0], parts[1], parts[2], parts[3]
+	if namespace == "" {
+		return DocumentId{}, fmt.Errorf("invalid document id %q: namespace must not be empty", raw)
+	}
+	if docType == "" {
+		return DocumentId{}, fmt.Errorf("invalid document id %q: document type must not be empty", raw)
+	}
+	if userSpecific == "" {
+		return DocumentId{}, fmt.Errorf("invalid document id %q: user-specific part must not be empty", raw)
+	}
+	id := DocumentId{Namespace: namespace, Type: docType, UserSpecific: userSpecific}
+	switch {
+	case modifier == "":
+		// Plain ID, no group or number modifier.
+	case strings.HasPrefix(modifier, "n="):
+		number, err := strconv.ParseInt(strings.TrimPrefix(modifier, "n="), 10, 64)
+		if err != nil {
+			return DocumentId{}, fmt.Errorf("invalid document id %q: n= modifier must be an integer", raw)
+		}
+		id.Number = &number
+	case strings.HasPrefix(modifier, "g="):
+		group := strings.TrimPrefix(modifier, "g=")
+		if group == "" {
+			return DocumentId{}, fmt.Errorf("invalid document id %q: g= modifier must not be empty", raw)
+		}
+		id.Group = group
+	default:
+		return DocumentId{}, fmt.Errorf("invalid document id %q: modifier must be empty, n=<number> or g=<group>", raw)
+	}
+	return id, nil
+}
+
+// String returns id in its canonical "id:namespace:type:modifier:user-specific" form.
+func (id DocumentId) String() string {
+	return fmt.Sprintf("id:%s:%s:%s:%s", id.Namespace, id.Type, id.modifier(), id.UserSpecific)
+}
+
+func (id DocumentId) modifier() string {
+	switch {
+	case id.Number != nil:
+		return fmt.Sprintf("n=%d", *id.Number)
+	case id.Group != "":
+		return "g=" + id.Group
+	default:
+		return ""
+	}
+}
+
+// DocumentPath returns the path /document/v1 expects id to be addressed at, which requires the number or
+// group modifier as its own path segment rather than embedded in an opaque ID string.
+func (id DocumentId) DocumentPath() string {
+	switch {
+	case id.Number != nil:
+		return fmt.Sprintf("/document/v1/%s/%s/number/%d/%s", id.Namespace, id.Type, *id.Number, id.UserSpecific)
+	case id.Group != "":
+		return fmt.Sprintf("/document/v1/%s/%s/group/%s/%s", id.Namespace, id.Type, id.Group, id.UserSpecific)
+	default:
+		return fmt.Sprintf("/document/v1/%s/%s/docid/%s", id.Namespace, id.Type, id.UserSpecific)
+	}
+}
+
+// ExpandShorthandId expands a shorthand document ID missing its modifier segment entirely, e.g.
+// "id:mynamespace:music:a-head-full-of-dreams" (3 segments after "id:", rather than the full 4), into a
+// group ID using defaultGroup, or a plain ID if defaultGroup is empty. An ID that already has all four
+// segments, and any string that isn't a well-formed ID at all, is returned unchanged: only the specific
+// missing-modifier shorthand is expanded here, everything else is left for ParseDocumentId to validate.
+func ExpandShorthandId(raw, defaultGroup string) string {
+	if !strings.HasPrefix(raw, "id:") {
+		return raw
+	}
+	parts := strings.SplitN(raw[len("id:"):], ":", 4)
+	if len(parts) != 3 {
+		return raw
+	}
+	namespace, docType, userSpecific := parts[0], parts[1], parts[2]
+	if defaultGroup == "" {
+		return fmt.Sprintf("id:%s:%s::%s", namespace, docType, userSpecific)
+	}
+	return fmt.Sprintf("id:%s:%s:g=%s:%s", namespace, docType, defaultGroup, userSpecific)
+}
@@ -0,0 +1,155 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDocumentIdPlain(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music::a-head-full-of-dreams")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "mynamespace", id.Namespace)
+	assert.Equal(t, "music", id.Type)
+	assert.Nil(t, id.Number)
+	assert.Equal(t, "", id.Group)
+	assert.Equal(t, "a-head-full-of-dreams", id.UserSpecific)
+}
+
+func TestParseDocumentIdWithNumberModifier(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music:n=123:a-head-full-of-dreams")
+
+	assert.Nil(t, err)
+	if assert.NotNil(t, id.Number) {
+		assert.Equal(t, int64(123), *id.Number)
+	}
+	assert.Equal(t, "", id.Group)
+}
+
+func TestParseDocumentIdWithGroupModifier(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music:g=user1:a-head-full-of-dreams")
+
+	assert.Nil(t, err)
+	assert.Nil(t, id.Number)
+	assert.Equal(t, "user1", id.Group)
+}
+
+func TestParseDocumentIdRejectsMissingPrefix(t *testing.T) {
+	_, err := ParseDocumentId("mynamespace:music::a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "must start with")
+	}
+}
+
+func TestParseDocumentIdRejectsTooFewSegments(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace:music:a-head-full-of-dreams")
+
+	assert.NotNil(t, err)
+}
+
+func TestParseDocumentIdRejectsEmptyNamespace(t *testing.T) {
+	_, err := ParseDocumentId("id::music::a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "namespace")
+	}
+}
+
+func TestParseDocumentIdRejectsEmptyType(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace::::a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "document type")
+	}
+}
+
+func TestParseDocumentIdRejectsEmptyUserSpecific(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace:music::")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "user-specific")
+	}
+}
+
+func TestParseDocumentIdRejectsNonIntegerNumber(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace:music:n=abc:a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "n=")
+	}
+}
+
+func TestParseDocumentIdRejectsEmptyGroup(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace:music:g=:a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "g=")
+	}
+}
+
+func TestParseDocumentIdRejectsUnknownModifier(t *testing.T) {
+	_, err := ParseDocumentId("id:mynamespace:music:x=1:a-head-full-of-dreams")
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "modifier")
+	}
+}
+
+func TestDocumentIdStringRoundTrips(t *testing.T) {
+	for _, raw := range []string{
+		"id:mynamespace:music::a-head-full-of-dreams",
+		"id:mynamespace:music:n=123:a-head-full-of-dreams",
+		"id:mynamespace:music:g=user1:a-head-full-of-dreams",
+	} {
+		id, err := ParseDocumentId(raw)
+		assert.Nil(t, err)
+		assert.Equal(t, raw, id.String())
+	}
+}
+
+func TestDocumentPathPlain(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music::a-head-full-of-dreams")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/document/v1/mynamespace/music/docid/a-head-full-of-dreams", id.DocumentPath())
+}
+
+func TestDocumentPathWithNumberModifier(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music:n=123:a-head-full-of-dreams")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/document/v1/mynamespace/music/number/123/a-head-full-of-dreams", id.DocumentPath())
+}
+
+func TestDocumentPathWithGroupModifier(t *testing.T) {
+	id, err := ParseDocumentId("id:mynamespace:music:g=user1:a-head-full-of-dreams")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/document/v1/mynamespace/music/group/user1/a-head-full-of-dreams", id.DocumentPath())
+}
+
+func TestExpandShorthandIdWithDefaultGroup(t *testing.T) {
+	expanded := ExpandShorthandId("id:mynamespace:music:a-head-full-of-dreams", "user1")
+
+	assert.Equal(t, "id:mynamespace:music:g=user1:a-head-full-of-dreams", expanded)
+}
+
+func TestExpandShorthandIdWithoutDefaultGroup(t *testing.T) {
+	expanded := ExpandShorthandId("id:mynamespace:music:a-head-full-of-dreams", "")
+
+	assert.Equal(t, "id:mynamespace:music::a-head-full-of-dreams", expanded)
+}
+
+func TestExpandShorthandIdLeavesCompleteIdUnchanged(t *testing.T) {
+	expanded := ExpandShorthandId("id:mynamespace:music::a-head-full-of-dreams", "user1")
+
+	assert.Equal(t, "id:mynamespace:music::a-head-full-of-dreams", expanded)
+}
+
+func TestExpandShorthandIdLeavesNonIdStringUnchanged(t *testing.T) {
+	expanded := ExpandShorthandId("not-an-id", "user1")
+
+	assert.Equal(t, "not-an-id", expanded)
+}
@@ -0,0 +1,128 @@
+package vespa
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// maxStreamRetries bounds how many attempts, the first included, PutOperationStreamed makes when a
+// connection-level failure happened before any part of the body reached the wire, so a single flaky connection attempt doesn't
+// become a hard failure. A failure after the body started sending is never retried here: /document/v1 has
+// no way to resume a put at a byte offset, so the only safe retry is one that starts over from a state the
+// server never saw any of.
+const maxStreamRetries = 3
+
+// PutOperationStreamed sends the document in fileName as a put, reading its body directly from disk with
+// Content-Length set instead of buffering the whole file in memory first, for a document large enough that
+// buffering it could exhaust memory or blow a timeout budgeted for a small one. Unlike PutOperation, it
+// can't discover a document ID embedded in the file without reading it into memory, so documentId is
+// required. bandwidthFloorMBps, if positive, extends baseTimeout by however long fileName's size would take
+// to transfer at that many megabytes/second, on top of the flat per-request overhead baseTimeout already
+// covers.
+func PutOperationStreamed(fileName, documentId string, service *Service, baseTimeout time.Duration, opts OperationOptions, bandwidthFloorMBps float64) (OperationResult, error) {
+	if documentId == "" {
+		return OperationResult{}, fmt.Errorf("--stream requires an explicit document ID: it sends the file's contents directly from disk without reading it into memory first, so it can't discover an id embedded in the file")
+	}
+	if opts.Compress {
+		return OperationResult{}, fmt.Errorf("--stream cannot be combined with --compress: compressing the body first would mean buffering the whole file in memory")
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	parsedId, err := ParseDocumentId(documentId)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	path, err := documentPathWithCondition(parsedId.DocumentPath(), opts)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	timeout := streamTimeout(baseTimeout, info.Size(), bandwidthFloorMBps)
+	var lastErr error
+	for attempt := 1; attempt <= maxStreamRetries; attempt++ {
+		result, sent, err := putStream(fileName, info.Size(), path, service, timeout)
+		if err == nil {
+			return result, nil
+		}
+		if sent {
+			return OperationResult{}, fmt.Errorf("put stream failed after part of the body had already been sent, cannot safely retry: %w", err)
+		}
+		lastErr = err
+	}
+	return OperationResult{}, fmt.Errorf("put stream failed after %d attempt(s), none of which sent any of the body: %w", maxStreamRetries, lastErr)
+}
+
+// streamTimeout extends base by however long size bytes would take to transfer at bandwidthFloorMBps
+// megabytes/second, so a large upload isn't held to the same timeout as a small document. A non-positive
+// bandwidthFloorMBps or size leaves base unchanged.
+func streamTimeout(base time.Duration, size int64, bandwidthFloorMBps float64) time.Duration {
+	if bandwidthFloorMBps <= 0 || size <= 0 {
+		return base
+	}
+	seconds := float64(size) / (bandwidthFloorMBps * 1_000_000)
+	return base + time.Duration(seconds*float64(time.Second))
+}
+
+// putStream sends a single put attempt, streaming fileName's contents from disk. sent reports whether any
+// byte of the body was read (and therefore possibly written to the connection) before err occurred, so the
+// caller can decide whether a retry is safe. req.GetBody opens fileName afresh rather than reusing a single
+// open file, since Service.Do's failover re-fetches the body (via GetBody) for every candidate URL it
+// tries; read is a pointer shared by every reader GetBody produces, so it still reflects how much of
+// whichever one was actually used made it onto the wire.
+func putStream(fileName string, size int64, path string, service *Service, timeout time.Duration) (result OperationResult, sent bool, err error) {
+	var read int64
+	open := func() (io.ReadCloser, error) {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		return &countingReadCloser{file: f, read: &read}, nil
+	}
+	body, err := open()
+	if err != nil {
+		return OperationResult{}, false, err
+	}
+	req, err := http.NewRequest(http.MethodPost, path, body)
+	if err != nil {
+		body.Close()
+		return OperationResult{}, false, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/json")
+	req.GetBody = open
+	resp, doErr := service.Do(req, timeout)
+	if doErr != nil {
+		return OperationResult{}, read > 0, doErr
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return OperationResult{}, true, err
+	}
+	return OperationResult{
+		Success: resp.StatusCode/100 == 2,
+		Status:  resp.StatusCode,
+		Message: string(respBody),
+	}, true, nil
+}
+
+// countingReadCloser wraps a file, adding the bytes read from it to the shared counter read points at, so a
+// caller can tell, after a connection-level failure, whether any of the body reached the wire yet.
+type countingReadCloser struct {
+	file *os.File
+	read *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.file.Read(p)
+	*c.read += int64(n)
+	return n, err
+}
+
+func (c *countingReadCloser) Close() error {
+	return c.file.Close()
+}
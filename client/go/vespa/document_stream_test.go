@@ -0,0 +1,157 @@
+package vespa
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+// sparseTempFile creates a file of exactly size bytes without writing any real content, so a test can
+// exercise a "very large" upload without actually allocating or writing that much data.
+func sparseTempFile(t *testing.T, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "huge.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPutOperationStreamedSetsContentLengthAndStreamsFile(t *testing.T) {
+	const size = 256 << 20 // 256MiB, sparse
+	file := sparseTempFile(t, size)
+	var contentLength int64
+	var bytesRead int64
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		contentLength = req.ContentLength
+		n, err := io.Copy(io.Discard, req.Body)
+		bytesRead = n
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationStreamed(file, "id:ns:type::1", service, time.Second, OperationOptions{}, 1)
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, int64(size), contentLength)
+	assert.Equal(t, int64(size), bytesRead)
+}
+
+func TestPutOperationStreamedUsesFlatMemoryRegardlessOfFileSize(t *testing.T) {
+	const size = 512 << 20 // 512MiB, sparse
+	file := sparseTempFile(t, size)
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		if _, err := io.Copy(io.Discard, req.Body); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	_, err := PutOperationStreamed(file, "id:ns:type::1", service, time.Second, OperationOptions{}, 1)
+	runtime.ReadMemStats(&after)
+
+	assert.Nil(t, err)
+	// A buggy implementation that reads the whole 512MiB file into memory would allocate at least that much;
+	// a streaming one only ever holds a small fixed-size buffer at a time.
+	allocated := after.TotalAlloc - before.TotalAlloc
+	assert.Less(t, allocated, uint64(32<<20), "expected well under 32MiB of total allocation while streaming a 512MiB file, got %d bytes", allocated)
+}
+
+func TestPutOperationStreamedRequiresDocumentId(t *testing.T) {
+	file := sparseTempFile(t, 10)
+	service := testService(&mock.HTTPClient{})
+
+	_, err := PutOperationStreamed(file, "", service, time.Second, OperationOptions{}, 1)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "requires an explicit document ID")
+	}
+}
+
+func TestPutOperationStreamedRejectsCompress(t *testing.T) {
+	file := sparseTempFile(t, 10)
+	service := testService(&mock.HTTPClient{})
+
+	_, err := PutOperationStreamed(file, "id:ns:type::1", service, time.Second, OperationOptions{Compress: true}, 1)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "--stream cannot be combined with --compress")
+	}
+}
+
+func TestPutOperationStreamedRetriesWhenNoBytesWereSent(t *testing.T) {
+	file := sparseTempFile(t, 10)
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, fmt.Errorf("connection refused")
+		}
+		if _, err := io.Copy(io.Discard, req.Body); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationStreamed(file, "id:ns:type::1", service, time.Second, OperationOptions{}, 1)
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestPutOperationStreamedDoesNotRetryAfterPartialSend(t *testing.T) {
+	file := sparseTempFile(t, 10)
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		buf := make([]byte, 1)
+		if _, err := req.Body.Read(buf); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("connection reset by peer")
+	}}
+	service := testService(httpClient)
+
+	_, err := PutOperationStreamed(file, "id:ns:type::1", service, time.Second, OperationOptions{}, 1)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "cannot safely retry")
+	}
+	assert.Equal(t, 1, attempts)
+}
+
+func TestStreamTimeoutExtendsBaseByAssumedTransferTime(t *testing.T) {
+	// 100MB at a 1MB/s floor should take roughly 100s on top of the base timeout.
+	timeout := streamTimeout(5*time.Second, 100_000_000, 1)
+	assert.True(t, timeout > 100*time.Second && timeout < 110*time.Second, "got %s", timeout)
+}
+
+func TestStreamTimeoutLeavesBaseUnchangedWithoutABandwidthFloor(t *testing.T) {
+	assert.Equal(t, 5*time.Second, streamTimeout(5*time.Second, 100_000_000, 0))
+}
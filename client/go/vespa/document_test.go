@@ -0,0 +1,335 @@
+package vespa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func init() {
+	sleep = func(time.Duration) {}
+}
+
+func testService(httpClient *mock.HTTPClient) *Service {
+	return &Service{BaseURL: "http://127.0.0.1:8080", Name: "container", httpClient: httpClient}
+}
+
+type closer struct{ *strings.Reader }
+
+func (closer) Close() error { return nil }
+
+func TestPutOperationWithData(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "/document/v1/ns/type/docid/1", httpClient.LastRequest.URL.Path)
+}
+
+func TestPutOperationWithDataMissingId(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {}}`), "", service, time.Second, OperationOptions{})
+
+	assert.NotNil(t, err)
+}
+
+func TestPutOperationCompressesBody(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{Compress: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "gzip", httpClient.LastRequest.Header.Get("Content-Encoding"))
+}
+
+// TestPutOperationCompressesMediumPayload verifies a body large enough to matter in practice (> 100 KB) is
+// actually gzip-compressed, not just marked as such: the request carries Content-Encoding: gzip, and
+// gunzipping its body round-trips back to the original JSON.
+func TestPutOperationCompressesMediumPayload(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+	data := []byte(fmt.Sprintf(`{"fields": {"title": "x", "body": %q}}`, strings.Repeat("a", 150*1024)))
+
+	result, err := PutOperationWithData(data, "id:ns:type::1", service, time.Second, OperationOptions{Compress: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "gzip", httpClient.LastRequest.Header.Get("Content-Encoding"))
+	compressed, err := io.ReadAll(httpClient.LastRequest.Body)
+	assert.Nil(t, err)
+	assert.True(t, len(compressed) < len(data), "compressed body should be smaller than the original")
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.Nil(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, data, decompressed)
+}
+
+func TestUpdateOperationCompressesBody(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+	data := []byte(`{"fields": {"title": {"assign": "x"}}}`)
+
+	result, err := UpdateOperationWithData(data, "id:ns:type::1", service, time.Second, OperationOptions{Compress: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "gzip", httpClient.LastRequest.Header.Get("Content-Encoding"))
+	compressed, err := io.ReadAll(httpClient.LastRequest.Body)
+	assert.Nil(t, err)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.Nil(t, err)
+	decompressed, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, data, decompressed)
+}
+
+func TestPutOperationFallsBackToUncompressedOn415(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			return &http.Response{StatusCode: 415, Body: closer{strings.NewReader("unsupported")}, Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{Compress: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, attempts)
+	assert.Empty(t, httpClient.LastRequest.Header.Get("Content-Encoding"))
+	assert.Contains(t, result.Detail, "retried uncompressed")
+}
+
+func TestPutOperationReadsGzippedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.json.gz")
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(`{"id": "id:ns:type::1", "fields": {"title": "x"}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	result, err := PutOperation(path, "", service, time.Second, OperationOptions{})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "/document/v1/ns/type/docid/1", httpClient.LastRequest.URL.Path)
+}
+
+func TestPutOperationRetriesOnThrottleHonoringRetryAfter(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			header := make(http.Header)
+			header.Set("Retry-After", "1")
+			return &http.Response{StatusCode: 429, Body: closer{strings.NewReader("throttled")}, Header: header}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestPutOperationGivesUpAfterMaxThrottleRetries(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: 429, Body: closer{strings.NewReader("throttled")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	assert.Nil(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, maxThrottleRetries+1, attempts)
+	assert.Contains(t, result.Detail, "after 6 attempt(s)")
+}
+
+func TestPutOperationDoesNotRetryWithoutFlag(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: 429, Body: closer{strings.NewReader("throttled")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{})
+
+	assert.Nil(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPutOperationRetriesOn503WithRetryAfter(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			header := make(http.Header)
+			header.Set("Retry-After", "1")
+			return &http.Response{StatusCode: 503, Body: closer{strings.NewReader("unavailable")}, Header: header}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestPutOperationDoesNotRetry503WithoutRetryAfter(t *testing.T) {
+	attempts := 0
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: 503, Body: closer{strings.NewReader("unavailable")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	assert.Nil(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPutOperationSendsIdempotencyKeyHeader(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{})
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, httpClient.LastRequest.Header.Get(idempotencyKeyHeader))
+}
+
+func TestPutOperationReusesIdempotencyKeyAcrossRetries(t *testing.T) {
+	var keys []string
+	httpClient := &mock.HTTPClient{DoFunc: func(req *http.Request, _ time.Duration) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(idempotencyKeyHeader))
+		if len(keys) == 1 {
+			header := make(http.Header)
+			header.Set("Retry-After", "0")
+			return &http.Response{StatusCode: 429, Body: closer{strings.NewReader("throttled")}, Header: header}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, keys[0], keys[1])
+}
+
+func TestIsIdempotentRejectsIncrementUpdate(t *testing.T) {
+	assert.False(t, IsIdempotent(OperationUpdate, []byte(`{"fields": {"count": {"increment": 1}}}`)))
+}
+
+func TestIsIdempotentAcceptsAssignUpdate(t *testing.T) {
+	assert.True(t, IsIdempotent(OperationUpdate, []byte(`{"fields": {"year": {"assign": 2015}}}`)))
+}
+
+func TestIsIdempotentAlwaysTrueForPutAndRemove(t *testing.T) {
+	assert.True(t, IsIdempotent(OperationPut, []byte(`{"fields": {"count": {"increment": 1}}}`)))
+	assert.True(t, IsIdempotent(OperationRemove, nil))
+}
+
+func TestUpdateOperationRefusesUnsafeRetryOfIncrementUpdate(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	service := testService(httpClient)
+
+	_, err := UpdateOperationWithData([]byte(`{"fields": {"count": {"increment": 1}}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "retryUnsafe")
+	}
+}
+
+func TestUpdateOperationAllowsUnsafeRetryWithAcknowledgment(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	result, err := UpdateOperationWithData([]byte(`{"fields": {"count": {"increment": 1}}}`), "id:ns:type::1", service, time.Second, OperationOptions{RetryOnThrottle: true, RetryUnsafe: true})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+}
+
+func TestPutOperationSendsConditionAsQueryParameter(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	result, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{Condition: "music.year < 2000"})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "/document/v1/ns/type/docid/1", httpClient.LastRequest.URL.Path)
+	assert.Equal(t, "music.year < 2000", httpClient.LastRequest.URL.Query().Get("condition"))
+}
+
+func TestPutOperationSendsConditionType(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{Condition: "music.year < 2000", ConditionType: "all"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "all", httpClient.LastRequest.URL.Query().Get("conditionType"))
+}
+
+func TestPutOperationOmitsConditionWhenNotGiven(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, httpClient.LastRequest.URL.RawQuery)
+}
+
+func TestPutOperationRejectsInvalidConditionType(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	service := testService(httpClient)
+
+	_, err := PutOperationWithData([]byte(`{"fields": {"title": "x"}}`), "id:ns:type::1", service, time.Second, OperationOptions{Condition: "true", ConditionType: "bogus"})
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `"bogus"`)
+	}
+}
@@ -0,0 +1,55 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// EndpointsEnv is the environment variable holding a JSON map of cluster name to data-plane URL(s), used to
+// resolve CloudTarget.ContainerService without querying the controller for endpoint discovery.
+const EndpointsEnv = "VESPA_CLI_ENDPOINTS"
+
+// ClusterEndpoints maps a container cluster name to the data-plane URLs that serve it, in priority order.
+// A cluster with more than one URL is served round-robin, with automatic failover to the next URL on a
+// connection error.
+type ClusterEndpoints map[string][]string
+
+// ParseEndpoints parses data, as found in the VESPA_CLI_ENDPOINTS environment variable, into
+// ClusterEndpoints. Each cluster's value may be a single URL string or an array of URLs, so a
+// single-endpoint configuration can be written without wrapping it in an array.
+func ParseEndpoints(data []byte) (ClusterEndpoints, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid endpoints: %w", err)
+	}
+	endpoints := make(ClusterEndpoints, len(raw))
+	for cluster, value := range raw {
+		var urls []string
+		if err := json.Unmarshal(value, &urls); err == nil {
+			if len(urls) == 0 {
+				return nil, fmt.Errorf("invalid endpoints for cluster %q: must have at least one URL", cluster)
+			}
+			endpoints[cluster] = urls
+			continue
+		}
+		var url string
+		if err := json.Unmarshal(value, &url); err != nil {
+			return nil, fmt.Errorf("invalid endpoints for cluster %q: must be a URL string or array of URLs", cluster)
+		}
+		endpoints[cluster] = []string{url}
+	}
+	return endpoints, nil
+}
+
+// Names returns every cluster name in e, sorted, for a helpful error message when a caller names an
+// unknown one, and for a caller (e.g. `vespa status`) that wants to enumerate every configured cluster in a
+// deterministic order.
+func (e ClusterEndpoints) Names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,33 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEndpointsSingleURL(t *testing.T) {
+	endpoints, err := ParseEndpoints([]byte(`{"container": "https://a.example.com"}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, ClusterEndpoints{"container": {"https://a.example.com"}}, endpoints)
+}
+
+func TestParseEndpointsMultipleURLs(t *testing.T) {
+	endpoints, err := ParseEndpoints([]byte(`{"container": ["https://a.example.com", "https://b.example.com"]}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, ClusterEndpoints{"container": {"https://a.example.com", "https://b.example.com"}}, endpoints)
+}
+
+func TestParseEndpointsRejectsEmptyArray(t *testing.T) {
+	_, err := ParseEndpoints([]byte(`{"container": []}`))
+
+	assert.NotNil(t, err)
+}
+
+func TestParseEndpointsRejectsMalformed(t *testing.T) {
+	_, err := ParseEndpoints([]byte(`{"container": 42}`))
+
+	assert.NotNil(t, err)
+}
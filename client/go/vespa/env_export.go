@@ -0,0 +1,54 @@
+package vespa
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// EnvExportFormat selects the shell syntax FormatEnvExport renders an environment variable assignment in.
+type EnvExportFormat string
+
+const (
+	EnvExportSh         EnvExportFormat = "sh"
+	EnvExportCmd        EnvExportFormat = "cmd"
+	EnvExportPowershell EnvExportFormat = "powershell"
+)
+
+// DefaultEnvExportFormat returns the export syntax native to the current platform: cmd on Windows, sh
+// elsewhere. It's a starting point for a caller that wants to guess before asking the user to override it
+// with e.g. a --format flag.
+func DefaultEnvExportFormat() EnvExportFormat {
+	if runtime.GOOS == "windows" {
+		return EnvExportCmd
+	}
+	return EnvExportSh
+}
+
+// ParseEnvExportFormat parses the --format values accepted for EnvExportFormat.
+func ParseEnvExportFormat(value string) (EnvExportFormat, error) {
+	switch EnvExportFormat(value) {
+	case EnvExportSh, EnvExportCmd, EnvExportPowershell:
+		return EnvExportFormat(value), nil
+	}
+	return "", fmt.Errorf("invalid format: %s", value)
+}
+
+// FormatEnvExport renders a single environment variable assignment for format, suitable for a script to
+// source or for a user to eval in their shell.
+func FormatEnvExport(format EnvExportFormat, name, value string) string {
+	switch format {
+	case EnvExportCmd:
+		return fmt.Sprintf("set %s=%s", name, value)
+	case EnvExportPowershell:
+		return fmt.Sprintf("$env:%s = \"%s\"", name, strings.ReplaceAll(value, "\"", "`\""))
+	default:
+		return fmt.Sprintf("export %s=%s", name, shQuote(value))
+	}
+}
+
+// shQuote single-quotes value for a POSIX shell, escaping any embedded single quote by closing the quoted
+// string, emitting an escaped quote, and re-opening it.
+func shQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,36 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatEnvExport(t *testing.T) {
+	assert.Equal(t, "export FOO='bar'", FormatEnvExport(EnvExportSh, "FOO", "bar"))
+	assert.Equal(t, "set FOO=bar", FormatEnvExport(EnvExportCmd, "FOO", "bar"))
+	assert.Equal(t, `$env:FOO = "bar"`, FormatEnvExport(EnvExportPowershell, "FOO", "bar"))
+}
+
+func TestFormatEnvExportShEscapesSingleQuote(t *testing.T) {
+	assert.Equal(t, `export FOO='it'\''s'`, FormatEnvExport(EnvExportSh, "FOO", "it's"))
+}
+
+func TestFormatEnvExportPowershellEscapesDoubleQuote(t *testing.T) {
+	assert.Equal(t, "$env:FOO = \"say `\"hi`\"\"", FormatEnvExport(EnvExportPowershell, "FOO", `say "hi"`))
+}
+
+func TestParseEnvExportFormat(t *testing.T) {
+	format, err := ParseEnvExportFormat("cmd")
+	assert.Nil(t, err)
+	assert.Equal(t, EnvExportCmd, format)
+
+	_, err = ParseEnvExportFormat("bogus")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid format")
+	}
+}
+
+func TestDefaultEnvExportFormat(t *testing.T) {
+	assert.NotEmpty(t, DefaultEnvExportFormat())
+}
@@ -0,0 +1,69 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// FeedBlockResource is a single resource (e.g. memory, disk, or an attribute address space) that a content
+// node tracks against a configured limit, blocking feed once usage exceeds it.
+type FeedBlockResource struct {
+	Name   string  `json:"name"`
+	Usage  float64 `json:"usage"`
+	Limit  float64 `json:"limit"`
+	Config string  `json:"config"`
+}
+
+// AboveLimit reports whether r's usage has exceeded its configured limit.
+func (r FeedBlockResource) AboveLimit() bool { return r.Usage > r.Limit }
+
+// NodeFeedBlock is the feed-blocking resource state of a single content node, as reported by its cluster
+// controller.
+type NodeFeedBlock struct {
+	Cluster   string              `json:"clusterId"`
+	Hostname  string              `json:"hostname"`
+	Blocked   bool                `json:"feedBlocked"`
+	Resources []FeedBlockResource `json:"resources"`
+}
+
+// ResourcesAboveLimit returns the subset of n's resources that have exceeded their configured limit.
+func (n NodeFeedBlock) ResourcesAboveLimit() []FeedBlockResource {
+	var above []FeedBlockResource
+	for _, r := range n.Resources {
+		if r.AboveLimit() {
+			above = append(above, r)
+		}
+	}
+	return above
+}
+
+// FetchFeedBlockStatus returns the feed-blocking resource state of every content node behind service, as
+// reported by the cluster controller's status API.
+func FetchFeedBlockStatus(service *Service, timeout time.Duration) ([]NodeFeedBlock, error) {
+	req, err := http.NewRequest(http.MethodGet, "/cluster/v2/feed-block", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to get feed-block status: status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Nodes []NodeFeedBlock `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Nodes, nil
+}
@@ -0,0 +1,52 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const feedBlockJSON = `{
+	"nodes": [
+		{"clusterId": "content", "hostname": "node-1", "feedBlocked": true, "resources": [
+			{"name": "disk", "usage": 0.92, "limit": 0.9, "config": "content.disk.resource-limit-factor"},
+			{"name": "memory", "usage": 0.5, "limit": 0.9, "config": "content.memory.resource-limit-factor"}
+		]},
+		{"clusterId": "content", "hostname": "node-2", "feedBlocked": false, "resources": [
+			{"name": "disk", "usage": 0.4, "limit": 0.9, "config": "content.disk.resource-limit-factor"}
+		]}
+	]
+}`
+
+func TestFetchFeedBlockStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(feedBlockJSON)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	nodes, err := FetchFeedBlockStatus(service, time.Second)
+
+	assert.Nil(t, err)
+	if assert.Len(t, nodes, 2) {
+		assert.True(t, nodes[0].Blocked)
+		above := nodes[0].ResourcesAboveLimit()
+		if assert.Len(t, above, 1) {
+			assert.Equal(t, "disk", above[0].Name)
+		}
+		assert.False(t, nodes[1].Blocked)
+		assert.Empty(t, nodes[1].ResourcesAboveLimit())
+	}
+}
+
+func TestFetchFeedBlockStatusFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader("boom")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := FetchFeedBlockStatus(service, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "status 500")
+	}
+}
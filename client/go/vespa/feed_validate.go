@@ -0,0 +1,119 @@
+package vespa
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ViolationKind categorizes a single schema violation found by ValidateFeedFile.
+type ViolationKind string
+
+const (
+	UnknownField        ViolationKind = "unknown_field"
+	FieldTypeMismatch   ViolationKind = "type_mismatch"
+	MissingIdComponent  ViolationKind = "missing_id_component"
+	InvalidDocumentJSON ViolationKind = "invalid_json"
+)
+
+// Violation is a single schema violation found in a feed file, identified by its line number so it can be
+// fixed at the source.
+type Violation struct {
+	Line    int
+	Kind    ViolationKind
+	Message string
+}
+
+// feedLine is the subset of a feed file line needed to validate it against a Schema.
+type feedLine struct {
+	Id     string                     `json:"id"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// ValidateFeedFile checks every document operation in the JSON-lines file at path against schema: unknown
+// fields, obvious type mismatches (string vs number vs array, etc.), and document IDs missing required
+// components. It runs entirely offline, never contacting a Vespa endpoint.
+func ValidateFeedFile(path string, schema *Schema) ([]Violation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var violations []Violation
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var doc feedLine
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			violations = append(violations, Violation{Line: lineNo, Kind: InvalidDocumentJSON, Message: err.Error()})
+			continue
+		}
+		violations = append(violations, validateId(lineNo, doc.Id)...)
+		violations = append(violations, validateFields(lineNo, doc.Fields, schema)...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// validateId checks that id has the minimum number of colon-separated components of a document ID:
+// "id:namespace:document-type:[key/value]:user-specified".
+func validateId(line int, id string) []Violation {
+	if id == "" {
+		return []Violation{{Line: line, Kind: MissingIdComponent, Message: "document has no id"}}
+	}
+	if strings.Count(id, ":") < 4 {
+		return []Violation{{Line: line, Kind: MissingIdComponent,
+			Message: fmt.Sprintf("id %q is missing one or more components (expected id:namespace:type:[key/value]:name)", id)}}
+	}
+	return nil
+}
+
+func validateFields(line int, fields map[string]json.RawMessage, schema *Schema) []Violation {
+	var violations []Violation
+	for name, raw := range fields {
+		kind, known := schema.Fields[name]
+		if !known {
+			violations = append(violations, Violation{Line: line, Kind: UnknownField,
+				Message: fmt.Sprintf("field %q is not declared in document type %q", name, schema.DocumentType)})
+			continue
+		}
+		if actual := jsonKind(raw); actual != KindUnknown && actual != kind {
+			violations = append(violations, Violation{Line: line, Kind: FieldTypeMismatch,
+				Message: fmt.Sprintf("field %q: expected %s, got %s", name, kind, actual)})
+		}
+	}
+	return violations
+}
+
+// jsonKind classifies the top-level JSON value in raw into the coarse FieldKind used to compare against a
+// schema field's declared type. Field update operations (e.g. {"assign": ...}) are not unwrapped, and are
+// reported as KindObject, since validating their inner value would require knowing the update's semantics.
+func jsonKind(raw json.RawMessage) FieldKind {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" {
+		return KindUnknown
+	}
+	switch trimmed[0] {
+	case '"':
+		return KindString
+	case '[':
+		return KindArray
+	case '{':
+		return KindObject
+	case 't', 'f':
+		return KindBool
+	}
+	if trimmed == "null" {
+		return KindUnknown
+	}
+	return KindNumber
+}
@@ -0,0 +1,63 @@
+package vespa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `
+schema music {
+    document music {
+        field title type string {
+            indexing: summary | index
+        }
+        field year type int {
+            indexing: summary | attribute
+        }
+        field tags type array<string> {
+            indexing: summary | attribute
+        }
+    }
+}
+`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseSchema(t *testing.T) {
+	path := writeTestFile(t, "music.sd", testSchema)
+
+	schema, err := ParseSchema(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "music", schema.DocumentType)
+	assert.Equal(t, KindString, schema.Fields["title"])
+	assert.Equal(t, KindNumber, schema.Fields["year"])
+	assert.Equal(t, KindArray, schema.Fields["tags"])
+}
+
+func TestValidateFeedFileFindsViolations(t *testing.T) {
+	schemaPath := writeTestFile(t, "music.sd", testSchema)
+	schema, err := ParseSchema(schemaPath)
+	assert.Nil(t, err)
+
+	feedPath := writeTestFile(t, "docs.jsonl", `{"id": "id:mynamespace:music::a-head-full-of-dreams", "fields": {"title": "A Head Full of Dreams", "year": 2015, "tags": ["pop", "rock"]}}
+{"id": "id:mynamespace:music::bad-type", "fields": {"title": "x", "year": "not-a-number"}}
+{"id": "id:mynamespace:music::unknown-field", "fields": {"title": "x", "artist": "Coldplay"}}
+{"id": "bad-id", "fields": {"title": "x"}}
+`)
+
+	violations, err := ValidateFeedFile(feedPath, schema)
+
+	assert.Nil(t, err)
+	assert.Len(t, violations, 3)
+	assert.Equal(t, FieldTypeMismatch, violations[0].Kind)
+	assert.Equal(t, UnknownField, violations[1].Kind)
+	assert.Equal(t, MissingIdComponent, violations[2].Kind)
+}
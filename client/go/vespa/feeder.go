@@ -0,0 +1,214 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParseFeedOperation parses a single line of a feed file into a FeedOperation. Two forms are accepted: the
+// vespa-feed-client envelope, exactly one of {"put": id, ...}, {"update": id, ...} or {"remove": id, ...}
+// with an optional "fields" object, and the bare {"id": id, "fields": {...}} form already produced by
+// vespa document export, which is always a put.
+func ParseFeedOperation(line []byte) (FeedOperation, error) {
+	var envelope struct {
+		Put    string          `json:"put"`
+		Update string          `json:"update"`
+		Remove string          `json:"remove"`
+		Id     string          `json:"id"`
+		Fields json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(line, &envelope); err != nil {
+		return FeedOperation{}, fmt.Errorf("invalid feed operation: %w", err)
+	}
+	op, id := OperationPut, envelope.Put
+	switch {
+	case envelope.Update != "":
+		op, id = OperationUpdate, envelope.Update
+	case envelope.Remove != "":
+		op, id = OperationRemove, envelope.Remove
+	case envelope.Put != "":
+		op, id = OperationPut, envelope.Put
+	case envelope.Id != "":
+		op, id = OperationPut, envelope.Id
+	default:
+		return FeedOperation{}, fmt.Errorf(`feed operation has no "put", "update", "remove" or "id"`)
+	}
+	if op == OperationRemove {
+		return FeedOperation{Operation: op, DocumentId: id}, nil
+	}
+	fields := envelope.Fields
+	if fields == nil {
+		fields = json.RawMessage("{}")
+	}
+	body, err := json.Marshal(struct {
+		Fields json.RawMessage `json:"fields"`
+	}{fields})
+	if err != nil {
+		return FeedOperation{}, err
+	}
+	return FeedOperation{Operation: op, DocumentId: id, Data: body}, nil
+}
+
+// FeedOperation is a single parsed line of a feed file, ready to be sent.
+type FeedOperation struct {
+	Operation  Operation
+	DocumentId string
+	Data       []byte
+}
+
+// FeedProgress is a snapshot of feed counters, reported periodically while a Feeder.Feed call is running.
+type FeedProgress struct {
+	Sent       int64
+	Success    int64
+	Failed     int64
+	P99Latency time.Duration
+}
+
+// FeedSummary is the outcome of a completed Feeder.Feed call.
+type FeedSummary struct {
+	DocumentCount       int64   `json:"documentCount"`
+	Success             int64   `json:"success"`
+	Failed              int64   `json:"failed"`
+	DurationSeconds     float64 `json:"durationSeconds"`
+	ThroughputPerSecond float64 `json:"throughputPerSecond"`
+}
+
+// Feeder feeds a stream of operations to a Vespa endpoint with up to MaxInflight requests in flight at
+// once. It relies on Service's underlying http.Client to keep connections (HTTP/2 when the endpoint
+// supports it) open and reused across operations, rather than opening one per request.
+type Feeder struct {
+	Service     *Service
+	Timeout     time.Duration
+	MaxInflight int
+	// Compress gzip-compresses each request body, as OperationOptions.Compress does for a single operation.
+	Compress bool
+	// OnProgress, if set, is called about once a second while Feed runs, with counters covering the whole
+	// feed so far and the p99 latency observed since the previous call.
+	OnProgress func(FeedProgress)
+	// OnReject, if set, is called for every operation that fails, with the original line it was parsed
+	// from (so a rejects file can preserve the exact input) and the server's response, if any.
+	OnReject func(line []byte, result OperationResult, err error)
+}
+
+// Feed reads lines from the channel until it's closed, each a single feed operation, and sends them with up
+// to f.MaxInflight requests in flight at once. A throttled operation (429, or a 503 with Retry-After) is
+// retried automatically with backoff; retries are allowed even for a non-idempotent update, since a feed at
+// this throughput favors eventually getting every operation applied at least once over strict
+// exactly-once semantics.
+func (f *Feeder) Feed(lines <-chan []byte) FeedSummary {
+	start := time.Now()
+	sem := make(chan struct{}, f.MaxInflight)
+	var wg sync.WaitGroup
+	var sent, success, failed int64
+	var latencies latencyTracker
+
+	stopProgress := make(chan struct{})
+	var progressWg sync.WaitGroup
+	if f.OnProgress != nil {
+		progressWg.Add(1)
+		go func() {
+			defer progressWg.Done()
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					f.OnProgress(FeedProgress{
+						Sent:       atomic.LoadInt64(&sent),
+						Success:    atomic.LoadInt64(&success),
+						Failed:     atomic.LoadInt64(&failed),
+						P99Latency: latencies.p99AndReset(),
+					})
+				case <-stopProgress:
+					return
+				}
+			}
+		}()
+	}
+
+	for line := range lines {
+		line := line
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			atomic.AddInt64(&sent, 1)
+			op, err := ParseFeedOperation(line)
+			if err != nil {
+				atomic.AddInt64(&failed, 1)
+				if f.OnReject != nil {
+					f.OnReject(line, OperationResult{}, err)
+				}
+				return
+			}
+			opStart := time.Now()
+			result, err := SendOperationWithData(op.Data, op.DocumentId, op.Operation, f.Service, f.Timeout, OperationOptions{
+				Compress:        f.Compress,
+				RetryOnThrottle: true,
+				RetryUnsafe:     true,
+			})
+			if f.OnProgress != nil {
+				latencies.record(time.Since(opStart))
+			}
+			if err != nil || !result.Success {
+				atomic.AddInt64(&failed, 1)
+				if f.OnReject != nil {
+					f.OnReject(line, result, err)
+				}
+				return
+			}
+			atomic.AddInt64(&success, 1)
+		}()
+	}
+	wg.Wait()
+	close(stopProgress)
+	progressWg.Wait()
+
+	elapsed := time.Since(start)
+	throughput := 0.0
+	if elapsed > 0 {
+		throughput = float64(sent) / elapsed.Seconds()
+	}
+	return FeedSummary{
+		DocumentCount:       sent,
+		Success:             success,
+		Failed:              failed,
+		DurationSeconds:     elapsed.Seconds(),
+		ThroughputPerSecond: throughput,
+	}
+}
+
+// latencyTracker accumulates operation latencies between calls to p99AndReset, which reports the p99 of
+// whatever was recorded since the previous call and starts a fresh window. This keeps memory bounded by the
+// feed rate over one reporting interval rather than the entire feed.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func (t *latencyTracker) record(d time.Duration) {
+	t.mu.Lock()
+	t.samples = append(t.samples, d)
+	t.mu.Unlock()
+}
+
+func (t *latencyTracker) p99AndReset() time.Duration {
+	t.mu.Lock()
+	samples := t.samples
+	t.samples = nil
+	t.mu.Unlock()
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	index := int(float64(len(samples)) * 0.99)
+	if index >= len(samples) {
+		index = len(samples) - 1
+	}
+	return samples[index]
+}
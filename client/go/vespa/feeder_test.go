@@ -0,0 +1,119 @@
+package vespa
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestParseFeedOperationEnvelopeForms(t *testing.T) {
+	put, err := ParseFeedOperation([]byte(`{"put": "id:ns:type::1", "fields": {"title": "a"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, OperationPut, put.Operation)
+	assert.Equal(t, "id:ns:type::1", put.DocumentId)
+	assert.JSONEq(t, `{"fields": {"title": "a"}}`, string(put.Data))
+
+	update, err := ParseFeedOperation([]byte(`{"update": "id:ns:type::1", "fields": {"title": {"assign": "b"}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, OperationUpdate, update.Operation)
+
+	remove, err := ParseFeedOperation([]byte(`{"remove": "id:ns:type::1"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, OperationRemove, remove.Operation)
+	assert.Nil(t, remove.Data)
+}
+
+func TestParseFeedOperationBareIdForm(t *testing.T) {
+	op, err := ParseFeedOperation([]byte(`{"id": "id:ns:type::1", "fields": {"title": "a"}}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, OperationPut, op.Operation)
+	assert.Equal(t, "id:ns:type::1", op.DocumentId)
+}
+
+func TestParseFeedOperationRejectsMissingId(t *testing.T) {
+	_, err := ParseFeedOperation([]byte(`{"fields": {"title": "a"}}`))
+
+	assert.NotNil(t, err)
+}
+
+// TestFeederFeedsConcurrentlyAndSummarizes simulates a small load against the mock HTTP client and verifies
+// every operation is sent and accounted for in the returned summary.
+func TestFeederFeedsConcurrentlyAndSummarizes(t *testing.T) {
+	const opCount = 50
+	var requests int32
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	feeder := &Feeder{Service: testService(httpClient), Timeout: time.Second, MaxInflight: 8}
+
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		for i := 0; i < opCount; i++ {
+			line, _ := json.Marshal(map[string]interface{}{"id": "id:ns:type::x", "fields": map[string]interface{}{"n": i}})
+			lines <- line
+		}
+	}()
+	summary := feeder.Feed(lines)
+
+	assert.EqualValues(t, opCount, atomic.LoadInt32(&requests))
+	assert.EqualValues(t, opCount, summary.DocumentCount)
+	assert.EqualValues(t, opCount, summary.Success)
+	assert.EqualValues(t, 0, summary.Failed)
+}
+
+// TestFeederRetriesOnThrottleThenSucceeds simulates a server that throttles the first attempt at an
+// operation and accepts the retry, verifying the operation still counts as a success.
+func TestFeederRetriesOnThrottleThenSucceeds(t *testing.T) {
+	var attempts int32
+	httpClient := &mock.HTTPClient{DoFunc: func(_ *http.Request, _ time.Duration) (*http.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return &http.Response{StatusCode: 429, Body: closer{strings.NewReader("throttled")}, Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}, nil
+	}}
+	feeder := &Feeder{Service: testService(httpClient), Timeout: time.Second, MaxInflight: 1}
+
+	lines := make(chan []byte, 1)
+	lines <- []byte(`{"id": "id:ns:type::1", "fields": {"title": "a"}}`)
+	close(lines)
+	summary := feeder.Feed(lines)
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	assert.EqualValues(t, 1, summary.Success)
+	assert.EqualValues(t, 0, summary.Failed)
+}
+
+// TestFeederReportsRejectsForFailedOperations verifies a hard failure is surfaced through OnReject with the
+// server's response, and counted as failed rather than success.
+func TestFeederReportsRejectsForFailedOperations(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader(`{"message": "boom"}`)}, Header: make(http.Header)}}
+	var rejected []OperationResult
+	feeder := &Feeder{
+		Service:     testService(httpClient),
+		Timeout:     time.Second,
+		MaxInflight: 1,
+		OnReject: func(_ []byte, result OperationResult, _ error) {
+			rejected = append(rejected, result)
+		},
+	}
+
+	lines := make(chan []byte, 1)
+	lines <- []byte(`{"id": "id:ns:type::1", "fields": {"title": "a"}}`)
+	close(lines)
+	summary := feeder.Feed(lines)
+
+	assert.EqualValues(t, 1, summary.Failed)
+	if assert.Equal(t, 1, len(rejected)) {
+		assert.Equal(t, 500, rejected[0].Status)
+		assert.Contains(t, rejected[0].Message, "boom")
+	}
+}
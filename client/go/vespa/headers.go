@@ -0,0 +1,46 @@
+package vespa
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// redactedHeaderNames matches header names whose values should never be printed verbatim, e.g. in -v
+// output: anything that looks like it carries a credential.
+var redactedHeaderNames = []string{"auth", "token"}
+
+// ParseHeaders parses values, each an HTTP header in "Name: value" form, into an http.Header. Content-Type,
+// which the CLI sets itself, is rejected (case-insensitively), since silently overriding it would produce a
+// request the CLI didn't actually ask for. Other headers the CLI sets, e.g. Content-Encoding, are not checked.
+func ParseHeaders(values []string) (http.Header, error) {
+	headers := make(http.Header, len(values))
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q: must be in \"Name: value\" form", value)
+		}
+		name = strings.TrimSpace(name)
+		v = strings.TrimSpace(v)
+		if name == "" {
+			return nil, fmt.Errorf("invalid header %q: name must not be empty", value)
+		}
+		if strings.EqualFold(name, "Content-Type") {
+			return nil, fmt.Errorf("invalid header %q: Content-Type is set by the CLI and cannot be overridden", value)
+		}
+		headers.Set(name, v)
+	}
+	return headers, nil
+}
+
+// RedactHeaderValue returns value unchanged, unless name looks like it carries a credential (its name
+// contains "auth" or "token", case-insensitively), in which case it returns a fixed placeholder instead.
+func RedactHeaderValue(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, marker := range redactedHeaderNames {
+		if strings.Contains(lower, marker) {
+			return "[redacted]"
+		}
+	}
+	return value
+}
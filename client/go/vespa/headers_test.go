@@ -0,0 +1,43 @@
+package vespa
+
+import "testing"
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders([]string{"X-Auth-Token: secret", "X-Custom:  value "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("X-Auth-Token"); got != "secret" {
+		t.Errorf("got %q, want %q", got, "secret")
+	}
+	if got := headers.Get("X-Custom"); got != "value" {
+		t.Errorf("got %q, want %q", got, "value")
+	}
+}
+
+func TestParseHeadersRejectsMalformedValue(t *testing.T) {
+	if _, err := ParseHeaders([]string{"no-colon-here"}); err == nil {
+		t.Error("expected an error for a header without a colon")
+	}
+}
+
+func TestParseHeadersRejectsContentTypeOverride(t *testing.T) {
+	if _, err := ParseHeaders([]string{"Content-Type: text/plain"}); err == nil {
+		t.Error("expected an error overriding Content-Type")
+	}
+	if _, err := ParseHeaders([]string{"content-type: text/plain"}); err == nil {
+		t.Error("expected an error overriding content-type case-insensitively")
+	}
+}
+
+func TestRedactHeaderValue(t *testing.T) {
+	if got := RedactHeaderValue("X-Auth-Token", "secret"); got == "secret" {
+		t.Error("expected X-Auth-Token value to be redacted")
+	}
+	if got := RedactHeaderValue("Authorization", "Bearer xyz"); got == "Bearer xyz" {
+		t.Error("expected Authorization value to be redacted")
+	}
+	if got := RedactHeaderValue("X-Custom", "value"); got != "value" {
+		t.Errorf("got %q, want %q", got, "value")
+	}
+}
@@ -0,0 +1,30 @@
+package vespa
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// VespaHomeEnv is the environment variable naming a self-hosted Vespa installation's root directory.
+const VespaHomeEnv = "VESPA_HOME"
+
+// defaultVespaHomeUnix is used when VESPA_HOME is unset on a POSIX system, Vespa's own default installation
+// path.
+const defaultVespaHomeUnix = "/opt/vespa"
+
+// defaultVespaHomeWindows is used when VESPA_HOME is unset on Windows, since /opt doesn't exist there.
+const defaultVespaHomeWindows = `C:\ProgramData\Vespa`
+
+// FindHome resolves a self-hosted Vespa installation's root directory: env(VespaHomeEnv) if set, otherwise
+// a platform-appropriate default. env is a lookup function, e.g. a CLI's own cli.env map or os.Getenv,
+// rather than FindHome reading the process environment directly, so a caller controls exactly what counts
+// as "set".
+func FindHome(env func(string) string) string {
+	if home := env(VespaHomeEnv); home != "" {
+		return filepath.Clean(home)
+	}
+	if runtime.GOOS == "windows" {
+		return defaultVespaHomeWindows
+	}
+	return defaultVespaHomeUnix
+}
@@ -0,0 +1,17 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindHomeUsesEnvWhenSet(t *testing.T) {
+	env := map[string]string{VespaHomeEnv: "/my/vespa"}
+	assert.Equal(t, "/my/vespa", FindHome(func(name string) string { return env[name] }))
+}
+
+func TestFindHomeFallsBackToPlatformDefault(t *testing.T) {
+	home := FindHome(func(string) string { return "" })
+	assert.NotEmpty(t, home)
+}
@@ -0,0 +1,141 @@
+package vespa
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is a single line from the Vespa log, as emitted by the log API: a tab-separated record of time,
+// host, process/thread ID, service, component, level, an optional source file:line, and message.
+type LogEntry struct {
+	Time      time.Time
+	Host      string
+	Pid       string // process/thread ID, e.g. "123/4", verbatim as the log API reports it
+	Service   string
+	Component string
+	Level     string
+	Source    string // file:line the entry was logged from, empty if the log line didn't include one
+	Message   string
+}
+
+// ParseLogLine parses a single raw log line into a LogEntry. The source field is optional: a line with 7
+// tab-separated fields is treated as having no source, and one with 8 treats the 7th as source.
+func ParseLogLine(line string) (LogEntry, error) {
+	fields := strings.SplitN(line, "\t", 8)
+	if len(fields) < 7 {
+		return LogEntry{}, fmt.Errorf("invalid log line: expected at least 7 tab-separated fields, got %d", len(fields))
+	}
+	t, err := parseLogTime(fields[0])
+	if err != nil {
+		return LogEntry{}, err
+	}
+	entry := LogEntry{
+		Time:      t,
+		Host:      fields[1],
+		Pid:       fields[2],
+		Service:   fields[3],
+		Component: fields[4],
+		Level:     fields[5],
+	}
+	if len(fields) == 8 {
+		entry.Source = fields[6]
+		entry.Message = fields[7]
+	} else {
+		entry.Message = fields[6]
+	}
+	return entry, nil
+}
+
+// parseLogTime parses s, a Unix timestamp in seconds with an optional fractional part, as used by the log API.
+func parseLogTime(s string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid log timestamp %q: %w", s, err)
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+}
+
+// LogOptions controls how FormatLogLine renders a LogEntry.
+type LogOptions struct {
+	// ShowSource prints the entry's source file:line, in brackets after the component name, when available.
+	ShowSource bool
+}
+
+// FormatLogLine renders e the way vespa-logfmt does: a timestamp, the service and component the entry came
+// from, its level, and its message.
+func FormatLogLine(e LogEntry, opts LogOptions) string {
+	component := e.Component
+	if opts.ShowSource && e.Source != "" {
+		component = fmt.Sprintf("%s [%s]", component, e.Source)
+	}
+	return fmt.Sprintf("[%s] %-7s %s/%s\t%s", e.Time.Format("2006-01-02 15:04:05.000"), strings.ToUpper(e.Level), e.Service, component, e.Message)
+}
+
+// ScanLogLines calls fn with each non-blank raw line read from r, leaving parsing (and what to do with a
+// line that fails to parse) up to the caller.
+func ScanLogLines(r io.Reader, fn func(string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+// ReadLog reads newline-delimited raw log lines from r, calling fn with each line that parses successfully.
+// A line that fails to parse is skipped rather than aborting the read, since the log stream may contain
+// blank or malformed lines at its boundaries.
+func ReadLog(r io.Reader, fn func(LogEntry)) error {
+	return ScanLogLines(r, func(line string) {
+		entry, err := ParseLogLine(line)
+		if err != nil {
+			return
+		}
+		fn(entry)
+	})
+}
+
+// LogEntryJSON is the shape FormatLogLineJSON encodes a LogEntry as: a conventional field set for shipping
+// log entries into an external system like an ELK stack.
+type LogEntryJSON struct {
+	Timestamp string `json:"timestamp"`
+	Host      string `json:"host"`
+	Pid       string `json:"pid"`
+	Service   string `json:"service"`
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+// FormatLogLineJSON renders e as a single-line JSON object, with its timestamp formatted as RFC3339 with
+// nanosecond precision.
+func FormatLogLineJSON(e LogEntry) []byte {
+	data, _ := json.Marshal(LogEntryJSON{
+		Timestamp: e.Time.Format(time.RFC3339Nano),
+		Host:      e.Host,
+		Pid:       e.Pid,
+		Service:   e.Service,
+		Component: e.Component,
+		Level:     e.Level,
+		Message:   e.Message,
+	})
+	return data
+}
+
+// FormatRawLogLineJSON renders line, which failed to parse as a LogEntry, as {"raw": line}, so a malformed
+// line still shows up in JSON output instead of silently vanishing.
+func FormatRawLogLineJSON(line string) []byte {
+	data, _ := json.Marshal(struct {
+		Raw string `json:"raw"`
+	}{Raw: line})
+	return data
+}
@@ -0,0 +1,99 @@
+package vespa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLineCapturesPid(t *testing.T) {
+	entry, err := ParseLogLine("1700000000\tmyhost\t123/4\tcontainer\tsearch.handler\tinfo\tsomething happened")
+	assert.Nil(t, err)
+	assert.Equal(t, "123/4", entry.Pid)
+}
+
+func TestFormatLogLineJSON(t *testing.T) {
+	entry, err := ParseLogLine("1700000000\tmyhost\t123/4\tcontainer\tsearch.handler\twarning\tsomething happened")
+	assert.Nil(t, err)
+
+	data := FormatLogLineJSON(entry)
+
+	assert.JSONEq(t, `{
+		"timestamp": "2023-11-14T22:13:20Z",
+		"host": "myhost",
+		"pid": "123/4",
+		"service": "container",
+		"component": "search.handler",
+		"level": "warning",
+		"message": "something happened"
+	}`, string(data))
+}
+
+func TestFormatRawLogLineJSON(t *testing.T) {
+	data := FormatRawLogLineJSON("not a log line")
+
+	assert.JSONEq(t, `{"raw": "not a log line"}`, string(data))
+}
+
+func TestScanLogLinesSkipsBlankLinesOnly(t *testing.T) {
+	input := "first\n\nnot a log line\nsecond\n"
+	var lines []string
+	err := ScanLogLines(strings.NewReader(input), func(line string) {
+		lines = append(lines, line)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "not a log line", "second"}, lines)
+}
+
+func TestParseLogLine(t *testing.T) {
+	entry, err := ParseLogLine("1700000000.123\tmyhost\t123/4\tcontainer\tsearch.handler\tinfo\tsomething happened")
+	assert.Nil(t, err)
+	assert.Equal(t, "myhost", entry.Host)
+	assert.Equal(t, "container", entry.Service)
+	assert.Equal(t, "search.handler", entry.Component)
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "something happened", entry.Message)
+}
+
+func TestParseLogLineRejectsMalformed(t *testing.T) {
+	_, err := ParseLogLine("not a log line")
+	assert.NotNil(t, err)
+}
+
+func TestFormatLogLine(t *testing.T) {
+	entry, err := ParseLogLine("1700000000\tmyhost\t123/4\tcontainer\tsearch.handler\twarning\tsomething happened")
+	assert.Nil(t, err)
+	formatted := FormatLogLine(entry, LogOptions{})
+	assert.Contains(t, formatted, "WARNING")
+	assert.Contains(t, formatted, "container/search.handler")
+	assert.Contains(t, formatted, "something happened")
+}
+
+func TestParseLogLineWithSource(t *testing.T) {
+	entry, err := ParseLogLine("1700000000\tmyhost\t123/4\tcontainer\tsearch.handler\twarning\tHandler.java:42\tsomething happened")
+	assert.Nil(t, err)
+	assert.Equal(t, "Handler.java:42", entry.Source)
+	assert.Equal(t, "something happened", entry.Message)
+}
+
+func TestFormatLogLineIncludesSourceWhenRequested(t *testing.T) {
+	entry, err := ParseLogLine("1700000000\tmyhost\t123/4\tcontainer\tsearch.handler\twarning\tHandler.java:42\tsomething happened")
+	assert.Nil(t, err)
+
+	withoutSource := FormatLogLine(entry, LogOptions{})
+	assert.NotContains(t, withoutSource, "Handler.java:42")
+
+	withSource := FormatLogLine(entry, LogOptions{ShowSource: true})
+	assert.Contains(t, withSource, "search.handler [Handler.java:42]")
+}
+
+func TestReadLogSkipsMalformedLines(t *testing.T) {
+	input := "1700000000\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\tfirst\n\nnot a log line\n1700000001\tmyhost\t1/1\tcontainer\tsearch.handler\tinfo\tsecond\n"
+	var messages []string
+	err := ReadLog(strings.NewReader(input), func(e LogEntry) {
+		messages = append(messages, e.Message)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, messages)
+}
@@ -0,0 +1,59 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MetricsSnapshot is a single point-in-time read of /metrics/v2/values, grouped the same way the API
+// returns it: one entry per service instance, each reporting a set of named metric values.
+type MetricsSnapshot struct {
+	Services []struct {
+		Name    string `json:"name"`
+		Metrics []struct {
+			Values map[string]float64 `json:"values"`
+		} `json:"metrics"`
+	} `json:"services"`
+}
+
+// Sum adds up every value reported for name across all services and metric entries in the snapshot, which
+// is what a rate metric (e.g. "queries.rate") needs when an application has more than one container.
+func (m MetricsSnapshot) Sum(name string) float64 {
+	var total float64
+	for _, service := range m.Services {
+		for _, metric := range service.Metrics {
+			total += metric.Values[name]
+		}
+	}
+	return total
+}
+
+// FetchMetrics fetches the current metrics snapshot from service's /metrics/v2/values endpoint, using the
+// "Vespa" consumer, which exposes the standard set of rate and latency metrics without requiring the
+// application to declare a custom metrics consumer.
+func FetchMetrics(service *Service, timeout time.Duration) (MetricsSnapshot, error) {
+	req, err := http.NewRequest(http.MethodGet, "/metrics/v2/values?consumer=Vespa", nil)
+	if err != nil {
+		return MetricsSnapshot{}, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return MetricsSnapshot{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return MetricsSnapshot{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return MetricsSnapshot{}, fmt.Errorf("failed to get metrics: status %d: %s", resp.StatusCode, body)
+	}
+	var snapshot MetricsSnapshot
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		return MetricsSnapshot{}, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,44 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+const metricsJSON = `{
+	"services": [
+		{"name": "vespa.container", "metrics": [
+			{"values": {"queries.rate": 12.5, "queries.error.rate": 0.5}}
+		]},
+		{"name": "vespa.container", "metrics": [
+			{"values": {"queries.rate": 7.5, "feed.operations.rate": 4.0}}
+		]}
+	]
+}`
+
+func TestFetchMetrics(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(metricsJSON)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	snapshot, err := FetchMetrics(service, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 20.0, snapshot.Sum("queries.rate"))
+	assert.Equal(t, 4.0, snapshot.Sum("feed.operations.rate"))
+	assert.Equal(t, 0.5, snapshot.Sum("queries.error.rate"))
+	assert.Equal(t, 0.0, snapshot.Sum("unknown.metric"))
+}
+
+func TestFetchMetricsFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader("boom")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	_, err := FetchMetrics(service, time.Second)
+
+	assert.NotNil(t, err)
+}
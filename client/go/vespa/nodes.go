@@ -0,0 +1,55 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Node is a single container or content node in a running deployment.
+type Node struct {
+	Hostname    string `json:"hostname"`
+	ServiceType string `json:"serviceType"`
+	Version     string `json:"version"`
+	State       string `json:"state"`
+}
+
+// ListNodes returns the nodes running d, optionally restricted to a single cluster.
+func ListNodes(service *Service, d Deployment, cluster string, timeout time.Duration) ([]Node, error) {
+	path := fmt.Sprintf("/application/v2/tenant/%s/application/%s/environment/%s/region/%s/instance/%s/nodes",
+		d.Tenant, d.Application, d.Environment, d.Region, d.Instance)
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to list nodes: status %d: %s", resp.StatusCode, body)
+	}
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	if cluster == "" {
+		return result.Nodes, nil
+	}
+	var filtered []Node
+	for _, n := range result.Nodes {
+		if n.ServiceType == cluster {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered, nil
+}
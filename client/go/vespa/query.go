@@ -0,0 +1,108 @@
+package vespa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// QueryResult holds a query response's raw JSON body, left unparsed since result shapes vary widely (hits,
+// groupings, errors) and callers only need to print or post-process it.
+type QueryResult struct {
+	Body []byte
+}
+
+// QueryError reports a non-2xx response from /search/, carrying the status so a caller can distinguish,
+// e.g., a 404 caused by no search chain being configured from any other query failure.
+type QueryError struct {
+	Status int
+	Body   []byte
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query failed with status %d: %s", e.Status, e.Body)
+}
+
+// Query issues a query against service's /search/ endpoint with the given parameters (e.g. "yql", "hits",
+// "query") and returns the raw JSON response.
+func Query(service *Service, parameters url.Values, timeout time.Duration) (QueryResult, error) {
+	req, err := http.NewRequest(http.MethodGet, "/search/?"+parameters.Encode(), nil)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return QueryResult{}, &QueryError{Status: resp.StatusCode, Body: body}
+	}
+	return QueryResult{Body: body}, nil
+}
+
+// PostQuery is equivalent to Query, but POSTs parameters and tensors as a JSON body instead of a GET query
+// string: each entry in parameters becomes a top-level key, and each entry in tensors becomes
+// "input.query(name)" set to its (already-validated) JSON content verbatim, overridden by any identically-
+// named entry in parameters. POST is used instead of GET for this because a tensor literal is often too
+// large to comfortably fit in a query string.
+func PostQuery(service *Service, parameters url.Values, tensors map[string]json.RawMessage, timeout time.Duration) (QueryResult, error) {
+	body := make(map[string]interface{}, len(parameters)+len(tensors))
+	for name, raw := range tensors {
+		body[fmt.Sprintf("input.query(%s)", name)] = raw
+	}
+	for key := range parameters {
+		body[key] = parameters.Get(key)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, "/search/", bytes.NewReader(data))
+	if err != nil {
+		return QueryResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return QueryResult{}, &QueryError{Status: resp.StatusCode, Body: respBody}
+	}
+	return QueryResult{Body: respBody}, nil
+}
+
+// QueryStream is equivalent to Query, but copies a successful response body directly to w instead of
+// buffering it, keeping memory use flat regardless of result size. A non-2xx response is still buffered
+// into a QueryError, since error bodies are small and callers need to inspect them.
+func QueryStream(service *Service, parameters url.Values, timeout time.Duration, w io.Writer) error {
+	req, err := http.NewRequest(http.MethodGet, "/search/?"+parameters.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return &QueryError{Status: resp.StatusCode, Body: body}
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,77 @@
+package vespa
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestQuery(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 200,
+		Body:       closer{strings.NewReader(`{"root": {"fields": {"totalCount": 1}}}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	result, err := Query(service, url.Values{"yql": {"select * from music where true"}}, time.Second)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(result.Body), "totalCount")
+	assert.Equal(t, "/search/", httpClient.LastRequest.URL.Path)
+	assert.Equal(t, "select * from music where true", httpClient.LastRequest.URL.Query().Get("yql"))
+}
+
+func TestQueryFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 400,
+		Body:       closer{strings.NewReader(`{"root": {"errors": []}}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	_, err := Query(service, url.Values{}, time.Second)
+
+	assert.NotNil(t, err)
+}
+
+func TestQueryStreamCopiesBodyDirectly(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 200,
+		Body:       closer{strings.NewReader(`{"root": {"fields": {"totalCount": 1}}}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+	var buf bytes.Buffer
+
+	err := QueryStream(service, url.Values{"yql": {"select * from music where true"}}, time.Second, &buf)
+
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "totalCount")
+}
+
+func TestQueryStreamFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 404,
+		Body:       closer{strings.NewReader(`{"root": {"errors": []}}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+	var buf bytes.Buffer
+
+	err := QueryStream(service, url.Values{}, time.Second, &buf)
+
+	if assert.NotNil(t, err) {
+		queryErr, ok := err.(*QueryError)
+		if assert.True(t, ok) {
+			assert.Equal(t, 404, queryErr.Status)
+		}
+	}
+	assert.Empty(t, buf.String())
+}
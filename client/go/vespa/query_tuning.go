@@ -0,0 +1,162 @@
+package vespa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tuningValueType constrains the values ParseTuningParameters accepts for a known tuning key.
+type tuningValueType int
+
+const (
+	tuningInt tuningValueType = iota
+	tuningFloat
+	tuningDuration
+)
+
+// tuningKey describes one recognized dispatch/match-phase tuning parameter.
+type tuningKey struct {
+	valueType   tuningValueType
+	description string
+}
+
+// TuningKeys is the known set of dispatch and match-phase tuning parameters --tuning accepts, kept in one
+// place so adding a new one doesn't require touching validation logic elsewhere.
+var TuningKeys = map[string]tuningKey{
+	"ranking.matchPhase.maxHits":             {tuningInt, "maximum hits to consider before degrading match phase"},
+	"ranking.matchPhase.diversity.minGroups": {tuningInt, "minimum number of diversity groups required during match phase"},
+	"dispatch.maxHitsPerNode":                {tuningInt, "maximum hits requested from each content node"},
+	"dispatch.topKProbability":               {tuningFloat, "probability used to estimate the top-k cutoff across nodes"},
+	"timeout.coverage":                       {tuningFloat, "fraction of the query timeout reserved for partial coverage"},
+	"timeout.connection":                     {tuningDuration, "per-node connection timeout"},
+}
+
+// TuningParameters is a validated set of --tuning key=value pairs, ready to be applied as query parameters.
+type TuningParameters map[string]string
+
+// ParseTuningParameters parses args, each a "key=value" pair, validating every key against TuningKeys and
+// every value against its key's type. A key not found in TuningKeys is rejected, along with a suggestion if
+// a known key is a close match for a likely typo, unless unsafe is set, in which case unknown keys are
+// passed through unvalidated.
+func ParseTuningParameters(args []string, unsafe bool) (TuningParameters, error) {
+	params := make(TuningParameters, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tuning parameter %q: must be key=value", arg)
+		}
+		known, ok := TuningKeys[key]
+		if !ok {
+			if unsafe {
+				params[key] = value
+				continue
+			}
+			return nil, unknownTuningKeyError(key)
+		}
+		if err := validateTuningValue(key, value, known.valueType); err != nil {
+			return nil, err
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+func validateTuningValue(key, value string, valueType tuningValueType) error {
+	var err error
+	switch valueType {
+	case tuningInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case tuningFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case tuningDuration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for tuning parameter %q: must be a %s", value, key, valueType)
+	}
+	return nil
+}
+
+// TuningKeysHelp renders the known tuning keys as one "key (type): description" line per key, sorted by
+// key, for inclusion in --tuning's help text.
+func TuningKeysHelp() string {
+	names := make([]string, 0, len(TuningKeys))
+	for name := range TuningKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		key := TuningKeys[name]
+		lines[i] = fmt.Sprintf("  %s (%s): %s", name, key.valueType, key.description)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (t tuningValueType) String() string {
+	switch t {
+	case tuningInt:
+		return "integer"
+	case tuningFloat:
+		return "number"
+	case tuningDuration:
+		return "duration, e.g. \"500ms\""
+	default:
+		return "value"
+	}
+}
+
+// unknownTuningKeyError reports key as unrecognized, suggesting the closest known key if one is a likely
+// typo (edit distance of 3 or less), and pointing at --tuning-unsafe as the escape hatch either way.
+func unknownTuningKeyError(key string) error {
+	if closest := closestTuningKey(key); closest != "" {
+		return fmt.Errorf("unknown tuning parameter %q: did you mean %q? Use --tuning-unsafe to set it anyway", key, closest)
+	}
+	return fmt.Errorf("unknown tuning parameter %q: use --tuning-unsafe to set it anyway", key)
+}
+
+// closestTuningKey returns the known tuning key with the smallest edit distance to key, or "" if none is
+// within a distance of 3, the point past which a suggestion is more likely to mislead than help.
+func closestTuningKey(key string) string {
+	const maxSuggestDistance = 3
+	best := ""
+	bestDistance := maxSuggestDistance + 1
+	names := make([]string, 0, len(TuningKeys))
+	for name := range TuningKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names) // deterministic tie-breaking
+	for _, name := range names {
+		if d := levenshtein(key, name); d < bestDistance {
+			best = name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b: the minimum number of single-character insertions,
+// deletions or substitutions needed to turn one into the other.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(curr[j-1]+1, min(prev[j]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
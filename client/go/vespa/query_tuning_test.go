@@ -0,0 +1,70 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTuningParametersAcceptsKnownKeys(t *testing.T) {
+	params, err := ParseTuningParameters([]string{"dispatch.maxHitsPerNode=50", "dispatch.topKProbability=0.9"}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "50", params["dispatch.maxHitsPerNode"])
+	assert.Equal(t, "0.9", params["dispatch.topKProbability"])
+}
+
+func TestParseTuningParametersAcceptsDuration(t *testing.T) {
+	params, err := ParseTuningParameters([]string{"timeout.connection=500ms"}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "500ms", params["timeout.connection"])
+}
+
+func TestParseTuningParametersRejectsMalformedArg(t *testing.T) {
+	_, err := ParseTuningParameters([]string{"not-a-key-value-pair"}, false)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "must be key=value")
+	}
+}
+
+func TestParseTuningParametersRejectsUnknownKeyWithoutUnsafe(t *testing.T) {
+	_, err := ParseTuningParameters([]string{"dispatch.maxHitsPerNod=50"}, false)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `did you mean "dispatch.maxHitsPerNode"`)
+		assert.Contains(t, err.Error(), "--tuning-unsafe")
+	}
+}
+
+func TestParseTuningParametersOmitsSuggestionWhenNoCloseMatch(t *testing.T) {
+	_, err := ParseTuningParameters([]string{"completely.unrelated.key=1"}, false)
+	if assert.NotNil(t, err) {
+		assert.NotContains(t, err.Error(), "did you mean")
+		assert.Contains(t, err.Error(), "--tuning-unsafe")
+	}
+}
+
+func TestParseTuningParametersRejectsWrongType(t *testing.T) {
+	_, err := ParseTuningParameters([]string{"dispatch.maxHitsPerNode=notanumber"}, false)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "integer")
+	}
+	_, err = ParseTuningParameters([]string{"dispatch.topKProbability=notanumber"}, false)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "number")
+	}
+	_, err = ParseTuningParameters([]string{"timeout.connection=notaduration"}, false)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "duration")
+	}
+}
+
+func TestParseTuningParametersUnsafeAllowsUnknownKey(t *testing.T) {
+	params, err := ParseTuningParameters([]string{"some.experimental.flag=1"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", params["some.experimental.flag"])
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("abc", "abc"))
+	assert.Equal(t, 1, levenshtein("abc", "abd"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
@@ -0,0 +1,222 @@
+package vespa
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Clock abstracts the passage of time so a Retrier can be driven deterministically in tests, instead of
+// actually sleeping. realClock, used everywhere outside tests, just delegates to the time package.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// BackoffPolicy computes how long to wait before the next attempt, growing the delay geometrically from
+// Initial by Multiplier on every attempt, capped at Max, so a flaky or restarting target isn't hammered with
+// retries at a fixed interval.
+type BackoffPolicy struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// delay returns the backoff for the given zero-based attempt count, before jitter is applied.
+func (b BackoffPolicy) delay(attempt int) time.Duration {
+	d := float64(b.Initial)
+	for i := 0; i < attempt; i++ {
+		d *= b.Multiplier
+	}
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	return time.Duration(d)
+}
+
+// breakerState is the state of a CircuitBreaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops attempts against a target after Threshold consecutive failures, for Cooldown,
+// before allowing a single half-open probe through: a success closes it again, a failure re-opens it for
+// another Cooldown. This is what lets a poll loop back off hard once a target is clearly down (e.g. a
+// config server restarting) instead of retrying it at the same pace as a merely slow response.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	consecutiveFailures int
+	state               breakerState
+	openedAt            time.Time
+}
+
+// Allow reports whether an attempt should be made right now: always while closed, never while open and
+// still cooling down, and once the cooldown has elapsed it moves to half-open and returns true on every
+// call until RecordSuccess or RecordFailure settles the probe.
+func (b *CircuitBreaker) Allow(now time.Time) bool {
+	if b.state != breakerOpen {
+		return true
+	}
+	if now.Sub(b.openedAt) < b.Cooldown {
+		return false
+	}
+	b.state = breakerHalfOpen
+	return true
+}
+
+// RecordSuccess closes the breaker and resets the failure count, whether that success followed a normal
+// closed-state attempt or a half-open probe.
+func (b *CircuitBreaker) RecordSuccess() {
+	b.consecutiveFailures = 0
+	b.state = breakerClosed
+}
+
+// RecordFailure counts a failure, opening the breaker once Threshold consecutive failures are reached, or
+// immediately re-opening it if the failure was itself a half-open probe.
+func (b *CircuitBreaker) RecordFailure(now time.Time) {
+	if b.state == breakerHalfOpen {
+		b.open(now)
+		return
+	}
+	b.consecutiveFailures++
+	if b.Threshold > 0 && b.consecutiveFailures >= b.Threshold {
+		b.open(now)
+	}
+}
+
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = breakerOpen
+	b.openedAt = now
+}
+
+// State returns the breaker's current state, "closed", "open" or "half-open", for --debug logging.
+func (b *CircuitBreaker) State() string { return b.state.String() }
+
+// Retrier drives the pacing of a poll loop: jittered exponential backoff between attempts, short-circuited
+// by a CircuitBreaker once a target is clearly down, all bounded by an overall deadline. It's meant to be
+// driven explicitly by each loop (Allow before an attempt, Success or Failure after it, Wait between
+// attempts) rather than wrapping the attempt itself, since the loops that need this (vespa status --watch,
+// vespa rollback --wait, vespa prod status --wait) each already print their own progress between attempts.
+type Retrier struct {
+	Backoff  BackoffPolicy
+	Breaker  *CircuitBreaker
+	// Deadline is when Allow and Wait give up and return false. The zero value means no deadline.
+	Deadline time.Time
+	// Debug, if set, is called with a short message whenever the breaker changes state, for --debug.
+	Debug func(string)
+	// Clock is the source of time and sleeping. Defaults to the real clock; tests, and commands that already
+	// have their own injectable sleep hook (e.g. cli.sleep), can replace it.
+	Clock Clock
+	// Jitter perturbs a computed backoff before it's slept, defaulting to fullJitter. Tests that need
+	// deterministic delays can replace it with the identity function.
+	Jitter func(time.Duration) time.Duration
+
+	attempt   int
+	lastState breakerState
+}
+
+// NewRetrier creates a Retrier backed by the real clock, ready to use.
+func NewRetrier(backoff BackoffPolicy, breaker *CircuitBreaker, deadline time.Time) *Retrier {
+	return &Retrier{
+		Backoff:   backoff,
+		Breaker:   breaker,
+		Deadline:  deadline,
+		Clock:     realClock{},
+		Jitter:    fullJitter,
+		lastState: breaker.state,
+	}
+}
+
+// Allow reports whether an attempt should be made now: the deadline (if any) hasn't passed, and the breaker
+// isn't open and still cooling down.
+func (r *Retrier) Allow() bool {
+	now := r.Clock.Now()
+	if !r.Deadline.IsZero() && !now.Before(r.Deadline) {
+		return false
+	}
+	allowed := r.Breaker.Allow(now)
+	r.logTransition()
+	return allowed
+}
+
+// Success records a successful attempt: it closes the breaker and resets the backoff, so the next failure
+// starts growing from Backoff.Initial again.
+func (r *Retrier) Success() {
+	r.Breaker.RecordSuccess()
+	r.attempt = 0
+	r.logTransition()
+}
+
+// Failure records a failed attempt, possibly opening (or re-opening) the breaker. The backoff itself grows
+// with each call to Wait.
+func (r *Retrier) Failure() {
+	r.Breaker.RecordFailure(r.Clock.Now())
+	r.logTransition()
+}
+
+func (r *Retrier) logTransition() {
+	if r.Debug == nil || r.Breaker.state == r.lastState {
+		return
+	}
+	r.Debug(fmt.Sprintf("circuit breaker %s -> %s", r.lastState, r.Breaker.state))
+	r.lastState = r.Breaker.state
+}
+
+// Wait sleeps before the next attempt, returning false without sleeping if Deadline would already be
+// exceeded. The delay is the backoff for the current attempt count, lengthened to the breaker's remaining
+// cooldown if it just opened, and shortened so it never sleeps past Deadline.
+func (r *Retrier) Wait() bool {
+	now := r.Clock.Now()
+	if !r.Deadline.IsZero() && !now.Before(r.Deadline) {
+		return false
+	}
+	delay := r.Jitter(r.Backoff.delay(r.attempt))
+	r.attempt++
+	if r.Breaker.state == breakerOpen {
+		if remaining := r.Breaker.Cooldown - now.Sub(r.Breaker.openedAt); remaining > delay {
+			delay = remaining
+		}
+	}
+	if !r.Deadline.IsZero() {
+		if remaining := r.Deadline.Sub(now); delay > remaining {
+			delay = remaining
+		}
+	}
+	r.Clock.Sleep(delay)
+	return true
+}
+
+// fullJitter returns a random duration in [d/2, d), so concurrent callers backing off after the same
+// failure don't all retry at exactly the same instant.
+func fullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
@@ -0,0 +1,136 @@
+package vespa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock that only advances when told to, so backoff growth, breaker transitions and deadline
+// adherence can be verified without a test actually waiting in real time.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func noJitter(d time.Duration) time.Duration { return d }
+
+func newTestRetrier(breaker *CircuitBreaker, deadline time.Time) (*Retrier, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	r := NewRetrier(BackoffPolicy{Initial: time.Second, Max: 8 * time.Second, Multiplier: 2}, breaker, deadline)
+	r.Clock = clock
+	r.Jitter = noJitter
+	return r, clock
+}
+
+func TestBackoffPolicyGrowsGeometricallyUpToMax(t *testing.T) {
+	b := BackoffPolicy{Initial: time.Second, Max: 8 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Second, b.delay(0))
+	assert.Equal(t, 2*time.Second, b.delay(1))
+	assert.Equal(t, 4*time.Second, b.delay(2))
+	assert.Equal(t, 8*time.Second, b.delay(3))
+	assert.Equal(t, 8*time.Second, b.delay(4)) // capped at Max
+}
+
+func TestRetrierWaitGrowsDelayOnRepeatedFailure(t *testing.T) {
+	r, clock := newTestRetrier(&CircuitBreaker{Threshold: 100, Cooldown: time.Minute}, time.Time{})
+
+	r.Failure()
+	start := clock.now
+	assert.True(t, r.Wait())
+	assert.Equal(t, time.Second, clock.now.Sub(start))
+
+	r.Failure()
+	start = clock.now
+	assert.True(t, r.Wait())
+	assert.Equal(t, 2*time.Second, clock.now.Sub(start))
+
+	r.Success()
+	r.Failure()
+	start = clock.now
+	assert.True(t, r.Wait())
+	assert.Equal(t, time.Second, clock.now.Sub(start), "a success resets the backoff back to Initial")
+}
+
+func TestCircuitBreakerOpensAfterThresholdAndHalfOpensAfterCooldown(t *testing.T) {
+	breaker := &CircuitBreaker{Threshold: 3, Cooldown: 10 * time.Second}
+	r, clock := newTestRetrier(breaker, time.Time{})
+
+	assert.Equal(t, "closed", breaker.State())
+	r.Failure()
+	r.Failure()
+	assert.Equal(t, "closed", breaker.State())
+	r.Failure()
+	assert.Equal(t, "open", breaker.State())
+	assert.False(t, r.Allow(), "breaker is open and hasn't cooled down yet")
+
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.True(t, r.Allow(), "cooldown elapsed: a half-open probe is allowed")
+	assert.Equal(t, "half-open", breaker.State())
+
+	r.Failure()
+	assert.Equal(t, "open", breaker.State(), "a failed probe re-opens the breaker")
+}
+
+func TestCircuitBreakerClosesOnSuccessfulProbe(t *testing.T) {
+	breaker := &CircuitBreaker{Threshold: 1, Cooldown: time.Second}
+	r, clock := newTestRetrier(breaker, time.Time{})
+
+	r.Failure()
+	assert.Equal(t, "open", breaker.State())
+
+	clock.now = clock.now.Add(time.Second)
+	assert.True(t, r.Allow())
+	assert.Equal(t, "half-open", breaker.State())
+
+	r.Success()
+	assert.Equal(t, "closed", breaker.State())
+}
+
+func TestRetrierWaitRespectsDeadline(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	deadline := clock.now.Add(2*time.Second + 500*time.Millisecond)
+	r := NewRetrier(BackoffPolicy{Initial: time.Second, Max: time.Minute, Multiplier: 2}, &CircuitBreaker{Threshold: 100, Cooldown: time.Minute}, deadline)
+	r.Clock = clock
+	r.Jitter = noJitter
+
+	r.Failure()
+	assert.True(t, r.Wait()) // delay would be 1s, well within the deadline
+	assert.Equal(t, time.Second, clock.now.Sub(time.Unix(0, 0)))
+
+	r.Failure()
+	assert.True(t, r.Wait()) // delay would be 2s, shortened to the 1.5s left before the deadline
+	assert.Equal(t, deadline, clock.now)
+
+	assert.False(t, r.Wait(), "deadline has already passed")
+	assert.False(t, r.Allow())
+}
+
+func TestFullJitterStaysWithinHalfToFullRange(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := fullJitter(10 * time.Second)
+		assert.True(t, d >= 5*time.Second && d < 10*time.Second, "jittered delay %s out of [5s, 10s)", d)
+	}
+	assert.Equal(t, time.Duration(0), fullJitter(0))
+}
+
+func TestRetrierDebugLogsBreakerStateTransitions(t *testing.T) {
+	var messages []string
+	r, clock := newTestRetrier(&CircuitBreaker{Threshold: 1, Cooldown: time.Second}, time.Time{})
+	r.Debug = func(msg string) { messages = append(messages, msg) }
+
+	r.Failure()
+	clock.now = clock.now.Add(time.Second)
+	r.Allow()
+	r.Success()
+
+	assert.Equal(t, []string{
+		"circuit breaker closed -> open",
+		"circuit breaker open -> half-open",
+		"circuit breaker half-open -> closed",
+	}, messages)
+}
@@ -0,0 +1,100 @@
+package vespa
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Schema is a minimal, offline-parsed view of a .sd file: just enough to validate feed documents against
+// it (field names and their coarse kind), not a full grammar of the schema language.
+type Schema struct {
+	DocumentType string
+	Fields       map[string]FieldKind
+}
+
+// FieldKind is the coarse shape a schema field's value must take in a document's JSON representation.
+// Detailed numeric/tensor subtypes are not distinguished, since the wire format for feed JSON only
+// distinguishes these coarse kinds anyway.
+type FieldKind int
+
+const (
+	KindUnknown FieldKind = iota
+	KindString
+	KindNumber
+	KindBool
+	KindArray
+	KindObject
+)
+
+func (k FieldKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	}
+	return "unknown"
+}
+
+var (
+	documentPattern = regexp.MustCompile(`^\s*document\s+(\S+)\s*\{`)
+	fieldPattern    = regexp.MustCompile(`^\s*field\s+(\S+)\s+type\s+(\S+)\s*\{`)
+)
+
+// fieldKindOf maps a schema field type, e.g. "string", "array<string>" or "tensor(x[4])", to its coarse kind.
+func fieldKindOf(schemaType string) FieldKind {
+	switch {
+	case strings.HasPrefix(schemaType, "array<"), strings.HasPrefix(schemaType, "weightedset<"):
+		return KindArray
+	case strings.HasPrefix(schemaType, "map<"), strings.HasPrefix(schemaType, "tensor("), schemaType == "struct":
+		return KindObject
+	case schemaType == "bool":
+		return KindBool
+	case schemaType == "string":
+		return KindString
+	case schemaType == "byte", schemaType == "int", schemaType == "long", schemaType == "short",
+		schemaType == "float", schemaType == "double", schemaType == "position":
+		return KindNumber
+	}
+	return KindUnknown
+}
+
+// ParseSchema reads the document type name and field kinds out of the .sd file at path. This is a
+// line-oriented scan, not a real parser: it is only meant to catch the common case of a top-level field
+// declaration directly inside the document block, which covers the drift this is meant to catch (a field
+// renamed or retyped in the schema without updating the feed generator).
+func ParseSchema(path string) (*Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	schema := &Schema{Fields: make(map[string]FieldKind)}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := documentPattern.FindStringSubmatch(line); m != nil && schema.DocumentType == "" {
+			schema.DocumentType = m[1]
+			continue
+		}
+		if m := fieldPattern.FindStringSubmatch(line); m != nil {
+			schema.Fields[m[1]] = fieldKindOf(m[2])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if schema.DocumentType == "" {
+		return nil, fmt.Errorf("%s: no document block found", path)
+	}
+	return schema, nil
+}
@@ -0,0 +1,138 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const sessionBasePath = "/application/v2/tenant/default/application/default/session"
+
+// Session is a prepared (but not necessarily activated) deployment on a config server.
+type Session struct {
+	Id        string `json:"id"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"createdAt"`
+	Active    bool   `json:"active"`
+}
+
+// ListSessions returns all sessions known to the config server behind service.
+func ListSessions(service *Service, timeout time.Duration) ([]Session, error) {
+	var sessions []Session
+	if err := sessionGet(service, sessionBasePath+"/", timeout, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// SessionStatus returns the id, prepare status, creation time and active flag of a single session. The
+// session's prepare log is not returned.
+func SessionStatus(service *Service, id string, timeout time.Duration) (Session, error) {
+	var session Session
+	if err := sessionGet(service, fmt.Sprintf("%s/%s", sessionBasePath, id), timeout, &session); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// DeleteSession deletes an unused session. Deleting the currently active session is refused.
+func DeleteSession(service *Service, id string, timeout time.Duration) error {
+	session, err := SessionStatus(service, id, timeout)
+	if err != nil {
+		return err
+	}
+	if session.Active {
+		return fmt.Errorf("session %s is active and cannot be deleted", id)
+	}
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%s", sessionBasePath, id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete session %s: status %d: %s", id, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ActivateSession activates a previously prepared session, making it the one serving the application.
+func ActivateSession(service *Service, id string, timeout time.Duration) error {
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/%s/active", sessionBasePath, id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to activate session %s: status %d: %s", id, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// PreviousSession returns the session that was active immediately before the one currently active, for use
+// when rolling back a broken deployment. The second return value is false if there is no earlier session to
+// roll back to.
+func PreviousSession(sessions []Session) (Session, bool) {
+	activeIndex := -1
+	for i, s := range sessions {
+		if s.Active {
+			activeIndex = i
+			break
+		}
+	}
+	if activeIndex == -1 {
+		return Session{}, false
+	}
+	active, err := strconv.Atoi(sessions[activeIndex].Id)
+	if err != nil {
+		return Session{}, false
+	}
+	var previous Session
+	previousId := -1
+	for _, s := range sessions {
+		id, err := strconv.Atoi(s.Id)
+		if err != nil || id >= active {
+			continue
+		}
+		if id > previousId {
+			previousId = id
+			previous = s
+		}
+	}
+	if previousId == -1 {
+		return Session{}, false
+	}
+	return previous, true
+}
+
+func sessionGet(service *Service, path string, timeout time.Duration, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,62 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestActivateSession(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader("{}")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	err := ActivateSession(service, "3", time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPut, httpClient.LastRequest.Method)
+	assert.Contains(t, httpClient.LastRequest.URL.Path, "/session/3/active")
+}
+
+func TestActivateSessionFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 500, Body: closer{strings.NewReader("boom")}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	err := ActivateSession(service, "3", time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "status 500")
+	}
+}
+
+func TestPreviousSession(t *testing.T) {
+	sessions := []Session{
+		{Id: "1", Active: false},
+		{Id: "2", Active: false},
+		{Id: "3", Active: true},
+	}
+
+	previous, ok := PreviousSession(sessions)
+
+	assert.True(t, ok)
+	assert.Equal(t, "2", previous.Id)
+}
+
+func TestPreviousSessionNoneBeforeActive(t *testing.T) {
+	sessions := []Session{{Id: "1", Active: true}}
+
+	_, ok := PreviousSession(sessions)
+
+	assert.False(t, ok)
+}
+
+func TestPreviousSessionNoneActive(t *testing.T) {
+	sessions := []Session{{Id: "1", Active: false}, {Id: "2", Active: false}}
+
+	_, ok := PreviousSession(sessions)
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,152 @@
+package vespa
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SupportFile is a single named blob to be written into a support snapshot archive.
+type SupportFile struct {
+	Name string
+	Data []byte
+}
+
+// SupportCollector gathers one piece of diagnostic information for a support snapshot. Collect must not
+// include secrets or document content.
+type SupportCollector struct {
+	Name    string
+	Collect func() (SupportFile, error)
+}
+
+// CollectSupportSnapshot runs every collector in order, continuing past individual failures, and writes a
+// tar.gz to w containing each successfully collected file plus a MANIFEST.txt recording what succeeded or
+// failed and why, so a partially-failed snapshot is still useful.
+func CollectSupportSnapshot(w io.Writer, collectors []SupportCollector) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	var manifest strings.Builder
+	for _, c := range collectors {
+		file, err := c.Collect()
+		if err != nil {
+			fmt.Fprintf(&manifest, "FAILED\t%s\t%s\n", c.Name, err)
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: file.Name, Mode: 0644, Size: int64(len(file.Data))}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return err
+		}
+		fmt.Fprintf(&manifest, "OK\t%s\t%s\n", c.Name, file.Name)
+	}
+	manifestBytes := []byte(manifest.String())
+	if err := tw.WriteHeader(&tar.Header{Name: "MANIFEST.txt", Mode: 0644, Size: int64(len(manifestBytes))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// secretLikeEnvKeys marks substrings that, when present in an environment variable's name, exclude it from
+// EnvironmentCollector's output, since a support snapshot must never leak credentials.
+var secretLikeEnvKeys = []string{"KEY", "TOKEN", "SECRET", "PASSWORD"}
+
+func looksLikeSecretEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, s := range secretLikeEnvKeys {
+		if strings.Contains(upper, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvironmentCollector captures env as a sorted KEY=VALUE listing, omitting any key that looks like it
+// holds a credential (containing KEY, TOKEN, SECRET or PASSWORD).
+func EnvironmentCollector(env map[string]string) SupportCollector {
+	return SupportCollector{Name: "environment", Collect: func() (SupportFile, error) {
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		for _, k := range keys {
+			if looksLikeSecretEnvKey(k) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s=%s\n", k, env[k])
+		}
+		return SupportFile{Name: "environment.txt", Data: []byte(b.String())}, nil
+	}}
+}
+
+// ServiceHealthCollector fetches /state/v1/health from service, naming the collected file after name.
+func ServiceHealthCollector(name string, service *Service, timeout time.Duration) SupportCollector {
+	return SupportCollector{Name: name + "-health", Collect: func() (SupportFile, error) {
+		body, err := getBody(service, "/state/v1/health", timeout)
+		if err != nil {
+			return SupportFile{}, err
+		}
+		return SupportFile{Name: name + "-health.json", Data: body}, nil
+	}}
+}
+
+// ConfigGenerationCollector fetches the config server's application status, which reports the currently
+// active config generation.
+func ConfigGenerationCollector(service *Service, timeout time.Duration) SupportCollector {
+	return SupportCollector{Name: "config-generation", Collect: func() (SupportFile, error) {
+		body, err := getBody(service, "/ApplicationStatus", timeout)
+		if err != nil {
+			return SupportFile{}, err
+		}
+		return SupportFile{Name: "config-generation.json", Data: body}, nil
+	}}
+}
+
+func getBody(service *Service, path string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// LogTailCollector captures the last maxLines lines of the file at path, e.g. vespa.log.
+func LogTailCollector(path string, maxLines int) SupportCollector {
+	return SupportCollector{Name: "vespa-log", Collect: func() (SupportFile, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return SupportFile{}, err
+		}
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(lines) > maxLines {
+			lines = lines[len(lines)-maxLines:]
+		}
+		return SupportFile{Name: "vespa.log.tail", Data: []byte(strings.Join(lines, "\n") + "\n")}, nil
+	}}
+}
@@ -0,0 +1,97 @@
+package vespa
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func readSnapshot(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		body, err := io.ReadAll(tr)
+		assert.Nil(t, err)
+		files[hdr.Name] = string(body)
+	}
+	return files
+}
+
+func TestCollectSupportSnapshotContinuesPastFailures(t *testing.T) {
+	var buf bytes.Buffer
+	collectors := []SupportCollector{
+		{Name: "good", Collect: func() (SupportFile, error) {
+			return SupportFile{Name: "good.txt", Data: []byte("ok")}, nil
+		}},
+		{Name: "bad", Collect: func() (SupportFile, error) {
+			return SupportFile{}, errors.New("boom")
+		}},
+	}
+
+	err := CollectSupportSnapshot(&buf, collectors)
+
+	assert.Nil(t, err)
+	files := readSnapshot(t, buf.Bytes())
+	assert.Equal(t, "ok", files["good.txt"])
+	assert.Contains(t, files["MANIFEST.txt"], "OK\tgood\tgood.txt")
+	assert.Contains(t, files["MANIFEST.txt"], "FAILED\tbad\tboom")
+}
+
+func TestEnvironmentCollectorRedactsSecrets(t *testing.T) {
+	env := map[string]string{
+		"VESPA_CLI_API_KEY": "super-secret",
+		"HOME":              "/home/user",
+	}
+
+	file, err := EnvironmentCollector(env).Collect()
+
+	assert.Nil(t, err)
+	assert.NotContains(t, string(file.Data), "super-secret")
+	assert.Contains(t, string(file.Data), "HOME=/home/user")
+}
+
+func TestServiceHealthCollector(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 200, Body: closer{strings.NewReader(`{"status": {"code": "up"}}`)}, Header: make(http.Header)}}
+	service := testService(httpClient)
+
+	file, err := ServiceHealthCollector("container", service, time.Second).Collect()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "container-health.json", file.Name)
+	assert.Contains(t, string(file.Data), "up")
+}
+
+func TestLogTailCollectorTruncatesToMaxLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vespa.log")
+	assert.Nil(t, os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0644))
+
+	file, err := LogTailCollector(path, 2).Collect()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "three\nfour\n", string(file.Data))
+}
+
+func TestLogTailCollectorFailsSoftOnMissingFile(t *testing.T) {
+	_, err := LogTailCollector(filepath.Join(t.TempDir(), "missing.log"), 10).Collect()
+
+	assert.NotNil(t, err)
+}
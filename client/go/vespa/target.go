@@ -0,0 +1,264 @@
+package vespa
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// endpointBreakerThreshold and endpointBreakerCooldown configure the per-endpoint CircuitBreaker Service.Do
+// keeps for each of its base URLs: after this many consecutive failures against one URL, it's skipped for
+// this long (unless every URL is currently skipped, see Service.Do), so a config server restart doesn't get
+// hit with a fresh retry from every caller on every single request.
+const (
+	endpointBreakerThreshold = 3
+	endpointBreakerCooldown  = 10 * time.Second
+)
+
+// Service represents a Vespa service, e.g. a container or content node, reachable at one or more base
+// URLs. When more than one URL is configured, requests round-robin across them, failing over to the next
+// on a connection error. Setting sticky instead pins every subsequent request to whichever URL last
+// succeeded, for a backend that keeps session state tied to the server that handled an earlier request.
+type Service struct {
+	BaseURL string
+	Name    string
+	// Headers are set on every request this service sends, in addition to whatever the caller already
+	// set. A header the caller also set is overridden, since these are meant to be unconditional, e.g. a
+	// gateway's required auth header.
+	Headers http.Header
+	// Signer, if set, adds signing headers (e.g. an AWS SigV4 Authorization header) to every request this
+	// service sends, right before it's dispatched to its final, resolved URL. This is for customers fronting
+	// Vespa with a cloud API gateway that requires every request to carry a signature the CLI has no
+	// built-in way to produce otherwise.
+	Signer RequestSigner
+
+	httpClient util.HTTPClient
+	failover   []string // additional base URLs beyond BaseURL, tried in order on connection failure
+	sticky     bool     // see NewServiceWithStickyFailover
+	next       uint64   // round-robin cursor over BaseURL and failover, advanced atomically
+	goodIndex  int32    // index into BaseURL+failover last known to work, used as the start point when sticky
+
+	breakersMu sync.Mutex
+	breakers   map[string]*CircuitBreaker // per-base-URL breaker, created lazily; see Service.breakerFor
+}
+
+// NewService creates a Service reachable at baseURL, identified by name in error messages.
+func NewService(baseURL, name string, httpClient util.HTTPClient) *Service {
+	return &Service{BaseURL: baseURL, Name: name, httpClient: httpClient}
+}
+
+// NewServiceWithFailover creates a Service that round-robins across urls, failing over to the next URL on
+// a connection error. urls must contain at least one URL.
+func NewServiceWithFailover(urls []string, name string, httpClient util.HTTPClient) *Service {
+	return &Service{BaseURL: urls[0], Name: name, httpClient: httpClient, failover: urls[1:]}
+}
+
+// NewServiceWithStickyFailover creates a Service that tries urls in order on a connection error, the same
+// as NewServiceWithFailover, except it does not round-robin: once a URL succeeds, it's tried first on every
+// later call, instead of spreading load across urls. This is for a server-side session tied to whichever
+// server handled an earlier request in the same sequence (e.g. a config server's prepare-then-activate
+// deployment session), where round-robining to a different URL on the next call would lose that session.
+// urls must contain at least one URL.
+func NewServiceWithStickyFailover(urls []string, name string, httpClient util.HTTPClient) *Service {
+	return &Service{BaseURL: urls[0], Name: name, httpClient: httpClient, failover: urls[1:], sticky: true}
+}
+
+// urls returns every base URL this service can reach, starting from the next round-robin cursor position,
+// or from whichever URL last succeeded if sticky is set.
+func (s *Service) urls() []string {
+	all := append([]string{s.BaseURL}, s.failover...)
+	n := uint64(len(all))
+	var start uint64
+	if s.sticky {
+		start = uint64(atomic.LoadInt32(&s.goodIndex)) % n
+	} else {
+		start = (atomic.AddUint64(&s.next, 1) - 1) % n
+	}
+	rotated := make([]string, n)
+	for i := range all {
+		rotated[i] = all[(start+uint64(i))%n]
+	}
+	return rotated
+}
+
+// rememberGood records base as the URL to try first on the next call, when sticky is set.
+func (s *Service) rememberGood(base string) {
+	if !s.sticky {
+		return
+	}
+	for i, u := range append([]string{s.BaseURL}, s.failover...) {
+		if u == base {
+			atomic.StoreInt32(&s.goodIndex, int32(i))
+			return
+		}
+	}
+}
+
+// breakerFor returns the CircuitBreaker tracking base's recent health, creating it on first use.
+func (s *Service) breakerFor(base string) *CircuitBreaker {
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+	if s.breakers == nil {
+		s.breakers = make(map[string]*CircuitBreaker)
+	}
+	b, ok := s.breakers[base]
+	if !ok {
+		b = &CircuitBreaker{Threshold: endpointBreakerThreshold, Cooldown: endpointBreakerCooldown}
+		s.breakers[base] = b
+	}
+	return b
+}
+
+// Do sends request against this service, resolving a relative URL against one of its base URLs if
+// necessary. If more than one base URL is configured, a connection error tries the next one in round-robin
+// order before giving up. Each base URL has its own CircuitBreaker: once one has failed
+// endpointBreakerThreshold times in a row it's skipped for endpointBreakerCooldown rather than retried on
+// every call, so a config server restart doesn't get hammered by every request that would otherwise fail
+// over to it and back. If every URL's breaker is currently open, they're tried anyway, since skipping all of
+// them would mean never noticing one has recovered.
+func (s *Service) Do(request *http.Request, timeout time.Duration) (*http.Response, error) {
+	for name, values := range s.Headers {
+		for _, v := range values {
+			request.Header.Set(name, v)
+		}
+	}
+	if request.URL.Host != "" {
+		if err := s.signRequest(request); err != nil {
+			return nil, err
+		}
+		return s.httpClient.Do(request, timeout)
+	}
+	path := request.URL.String()
+	urls := s.urls()
+	now := time.Now()
+	candidates := urls
+	if reachable := s.reachable(urls, now); len(reachable) > 0 {
+		candidates = reachable
+	}
+	var attempts []string
+	var lastErr error
+	for _, base := range candidates {
+		breaker := s.breakerFor(base)
+		u, err := url.Parse(base + path)
+		if err != nil {
+			return nil, err
+		}
+		req := request.Clone(request.Context())
+		req.URL = u
+		if req.GetBody != nil {
+			// Clone only shallow-copies Body, so every candidate would otherwise share (and, after the first
+			// attempt reads from it, drain) the same reader. Re-fetch a fresh one per attempt instead, closing
+			// whatever Body the request already carried (e.g. one opened just to build the request) so it
+			// isn't leaked now that it'll never be read.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			req.Body = body
+		}
+		if err := s.signRequest(req); err != nil {
+			return nil, err
+		}
+		resp, err := s.httpClient.Do(req, timeout)
+		if err == nil {
+			breaker.RecordSuccess()
+			s.rememberGood(base)
+			return resp, nil
+		}
+		breaker.RecordFailure(now)
+		attempts = append(attempts, fmt.Sprintf("%s (%s)", base, err))
+		lastErr = err
+	}
+	return nil, fmt.Errorf("%s: all endpoints failed: %s: %w", s.Name, strings.Join(attempts, ", "), lastErr)
+}
+
+// reachable filters urls down to those whose breaker currently allows an attempt, preserving order.
+func (s *Service) reachable(urls []string, now time.Time) []string {
+	var ok []string
+	for _, base := range urls {
+		if s.breakerFor(base).Allow(now) {
+			ok = append(ok, base)
+		}
+	}
+	return ok
+}
+
+// signRequest has s.Signer, if set, add its signing headers to request, which must already have its final
+// URL resolved. The body is read into memory so it can be hashed for the signer and still be sent
+// afterwards.
+func (s *Service) signRequest(request *http.Request) error {
+	if s.Signer == nil {
+		return nil
+	}
+	var body []byte
+	if request.Body != nil {
+		data, err := io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return err
+		}
+		body = data
+		request.Body = io.NopCloser(bytes.NewReader(data))
+		request.ContentLength = int64(len(data))
+	}
+	return s.Signer.Sign(request, body)
+}
+
+// LastRequestStats returns the timing breakdown of the most recent request this service sent, for a
+// command's --stats flag. ok is false if the underlying HTTPClient doesn't implement util.TimedHTTPClient;
+// the mock client used in tests does, returning its canned Stats (zero if none were given).
+func (s *Service) LastRequestStats() (util.RequestStats, bool) {
+	timed, ok := s.httpClient.(util.TimedHTTPClient)
+	if !ok {
+		return util.RequestStats{}, false
+	}
+	return timed.LastRequestStats(), true
+}
+
+// CheckHealth polls service's /state/v1/health endpoint, returning nil if it responds with a successful
+// status, or an error describing why it didn't.
+func CheckHealth(service *Service, timeout time.Duration) error {
+	req, err := http.NewRequest(http.MethodGet, "/state/v1/health", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// Target represents a Vespa deployment target, e.g. a local instance, a self-hosted cluster reachable
+// through a custom URL, or a Vespa Cloud deployment.
+type Target interface {
+	// Type returns a short, human-readable description of this target's kind.
+	Type() string
+	// ContainerService returns the service to use for document and query operations against cluster, or
+	// the only configured container service if cluster is empty.
+	ContainerService(cluster string) (*Service, error)
+	// DeployService returns the service to use for deployment operations.
+	DeployService() (*Service, error)
+	// ActivePackage returns the file manifest of the currently active application package, as reported by
+	// the config server's application content API.
+	ActivePackage() (FileManifest, error)
+	// ActivePackageFile returns the content of a single file, named by path as it appears in the manifest
+	// returned by ActivePackage, from the currently active application package.
+	ActivePackageFile(path string) ([]byte, error)
+}
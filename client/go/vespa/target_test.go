@@ -0,0 +1,242 @@
+package vespa
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+func TestServiceFailsOverOnConnectionError(t *testing.T) {
+	var requested []string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		requested = append(requested, request.URL.String())
+		if request.URL.Host == "bad" {
+			return nil, assert.AnError
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewServiceWithFailover([]string{"http://bad", "http://good"}, "container", httpClient)
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	resp, err := service.Do(req, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"http://bad/status", "http://good/status"}, requested)
+}
+
+func TestServiceFailsWhenAllEndpointsFail(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		return nil, assert.AnError
+	}}
+	service := NewServiceWithFailover([]string{"http://a", "http://b"}, "container", httpClient)
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.NotNil(t, err)
+}
+
+func TestServiceRoundRobinsAcrossCalls(t *testing.T) {
+	var requested []string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		requested = append(requested, request.URL.Host)
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewServiceWithFailover([]string{"http://a", "http://b"}, "container", httpClient)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "/status", nil)
+		assert.Nil(t, err)
+		_, err = service.Do(req, time.Second)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, []string{"a", "b"}, requested)
+}
+
+func TestServiceStickyFailoverStaysOnLastSuccessfulURL(t *testing.T) {
+	var requested []string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		requested = append(requested, request.URL.Host)
+		if request.URL.Host == "a" {
+			return nil, assert.AnError
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewServiceWithStickyFailover([]string{"http://a", "http://b"}, "deploy", httpClient)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "/status", nil)
+		assert.Nil(t, err)
+		_, err = service.Do(req, time.Second)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, []string{"a", "b", "b"}, requested)
+}
+
+// TestServiceSkipsEndpointAfterRepeatedFailures verifies Service.Do's per-URL CircuitBreaker: once an
+// endpoint has failed endpointBreakerThreshold times in a row, it's left out of the candidate list entirely,
+// rather than retried (and failed over away from) on every single call, so a config server stuck restarting
+// isn't hit by every request that would otherwise fail over to it first.
+func TestServiceSkipsEndpointAfterRepeatedFailures(t *testing.T) {
+	var requested []string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		requested = append(requested, request.URL.Host)
+		if request.URL.Host == "bad" {
+			return nil, assert.AnError
+		}
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewServiceWithFailover([]string{"http://bad", "http://good"}, "container", httpClient)
+
+	// Round-robin puts bad first only on every other call, so it takes twice the threshold in calls for bad to
+	// fail endpointBreakerThreshold times, and the next call starts at bad again.
+	for i := 0; i < 2*endpointBreakerThreshold; i++ {
+		req, err := http.NewRequest(http.MethodGet, "/status", nil)
+		assert.Nil(t, err)
+		_, err = service.Do(req, time.Second)
+		assert.Nil(t, err)
+	}
+	assert.Contains(t, requested, "bad")
+
+	requested = nil
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"good"}, requested, "bad's breaker should be open, skipping it entirely")
+}
+
+func TestServiceFailureListsEveryAttemptedURL(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		return nil, assert.AnError
+	}}
+	service := NewServiceWithFailover([]string{"http://a", "http://b"}, "container", httpClient)
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "http://a")
+		assert.Contains(t, err.Error(), "http://b")
+	}
+}
+
+// TestServiceLastRequestStatsReturnsUnderlyingClientStats verifies Service.LastRequestStats passes through
+// to a mock.HTTPClient's canned Stats, for a command's --stats flag to read without a real network call.
+func TestServiceLastRequestStatsReturnsUnderlyingClientStats(t *testing.T) {
+	httpClient := &mock.HTTPClient{
+		NextResponse: &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)},
+		Stats:        util.RequestStats{Total: 5 * time.Millisecond},
+	}
+	service := NewService("http://good", "container", httpClient)
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+	assert.Nil(t, err)
+
+	stats, ok := service.LastRequestStats()
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Millisecond, stats.Total)
+}
+
+func TestServiceAppliesHeadersToEveryRequest(t *testing.T) {
+	var seen string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		seen = request.Header.Get("X-Auth-Token")
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewService("http://localhost", "container", httpClient)
+	service.Headers = http.Header{"X-Auth-Token": []string{"secret"}}
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", seen)
+}
+
+type fakeSigner struct {
+	sawBody []byte
+	err     error
+}
+
+func (s *fakeSigner) Sign(request *http.Request, body []byte) error {
+	s.sawBody = body
+	if s.err != nil {
+		return s.err
+	}
+	request.Header.Set("Authorization", "signed")
+	return nil
+}
+
+func TestServiceSignsRequestAndPreservesBody(t *testing.T) {
+	var seenAuth, seenBody string
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		seenAuth = request.Header.Get("Authorization")
+		data, err := io.ReadAll(request.Body)
+		assert.Nil(t, err)
+		seenBody = string(data)
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewService("http://localhost", "container", httpClient)
+	signer := &fakeSigner{}
+	service.Signer = signer
+
+	req, err := http.NewRequest(http.MethodPost, "/status", strings.NewReader(`{"a":1}`))
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "signed", seenAuth)
+	assert.Equal(t, `{"a":1}`, seenBody)
+	assert.Equal(t, `{"a":1}`, string(signer.sawBody))
+}
+
+func TestServiceSignRequestErrorAbortsRequest(t *testing.T) {
+	httpClient := &mock.HTTPClient{}
+	service := NewService("http://localhost", "container", httpClient)
+	service.Signer = &fakeSigner{err: assert.AnError}
+
+	req, err := http.NewRequest(http.MethodGet, "/status", nil)
+	assert.Nil(t, err)
+	_, err = service.Do(req, time.Second)
+
+	assert.Equal(t, assert.AnError, err)
+	assert.Nil(t, httpClient.LastRequest)
+}
+
+func TestCheckHealthSucceedsOn200(t *testing.T) {
+	httpClient := &mock.HTTPClient{DoFunc: func(request *http.Request, timeout time.Duration) (*http.Response, error) {
+		assert.Equal(t, "/state/v1/health", request.URL.Path)
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
+	}}
+	service := NewService("http://localhost", "container", httpClient)
+
+	assert.Nil(t, CheckHealth(service, time.Second))
+}
+
+func TestCheckHealthFailsOnNonSuccessStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{StatusCode: 503, Body: http.NoBody, Header: make(http.Header)}}
+	service := NewService("http://localhost", "container", httpClient)
+
+	err := CheckHealth(service, time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "status 503")
+	}
+}
@@ -0,0 +1,284 @@
+package vespa
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TensorDimension is a single dimension of a tensor type, e.g. the "x[3]" or "y{}" in "tensor(x[3],y{})".
+type TensorDimension struct {
+	Name string
+	// Size is the dimension's bound, or 0 if it's mapped (unbounded).
+	Size int
+	// Mapped reports whether this dimension is addressed by string labels ("y{}") rather than a dense,
+	// bounded range of integer indices ("x[3]").
+	Mapped bool
+}
+
+// TensorCell is a single value at a fully-specified address, one label (or index, as a string) per
+// dimension of the tensor.
+type TensorCell struct {
+	Address map[string]string
+	Value   float64
+}
+
+// TensorValue is a tensor field value as rendered in a Vespa document or query response: its declared type
+// together with every cell it holds. Dimensions appear in the order declared by Type.
+type TensorValue struct {
+	Type       string
+	Dimensions []TensorDimension
+	Cells      []TensorCell
+}
+
+var tensorTypePattern = regexp.MustCompile(`^tensor(<[^>]*>)?\(([^)]*)\)$`)
+var tensorDimensionPattern = regexp.MustCompile(`^(\w+)(\[(\d+)\]|\{\})$`)
+
+// ParseTensorType parses a tensor type string, e.g. "tensor(x[3])" or "tensor<float>(x{},y[2])", into its
+// dimensions, in declaration order.
+func ParseTensorType(t string) ([]TensorDimension, error) {
+	m := tensorTypePattern.FindStringSubmatch(strings.TrimSpace(t))
+	if m == nil {
+		return nil, fmt.Errorf("not a tensor type: %q", t)
+	}
+	inner := strings.TrimSpace(m[2])
+	if inner == "" {
+		return nil, nil
+	}
+	specs := strings.Split(inner, ",")
+	dims := make([]TensorDimension, 0, len(specs))
+	for _, spec := range specs {
+		dm := tensorDimensionPattern.FindStringSubmatch(strings.TrimSpace(spec))
+		if dm == nil {
+			return nil, fmt.Errorf("invalid tensor dimension %q in type %q", spec, t)
+		}
+		if dm[3] == "" {
+			dims = append(dims, TensorDimension{Name: dm[1], Mapped: true})
+			continue
+		}
+		size, err := strconv.Atoi(dm[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid tensor dimension %q in type %q: %w", spec, t, err)
+		}
+		dims = append(dims, TensorDimension{Name: dm[1], Size: size})
+	}
+	return dims, nil
+}
+
+// LooksLikeTensor reports whether m is shaped like a tensor field value as Vespa renders it: a "type"
+// naming a tensor type, alongside one of its literal value forms ("cells", "values" or "blocks").
+func LooksLikeTensor(m map[string]interface{}) bool {
+	t, ok := m["type"].(string)
+	if !ok || !strings.HasPrefix(t, "tensor(") && !strings.HasPrefix(t, "tensor<") {
+		return false
+	}
+	_, hasCells := m["cells"]
+	_, hasValues := m["values"]
+	_, hasBlocks := m["blocks"]
+	return hasCells || hasValues || hasBlocks
+}
+
+// ParseTensorValue parses m, a decoded JSON object shaped like a tensor field value, into a TensorValue.
+// It supports every literal form this CLI's own output needs to round-trip: the general "cells" form (one
+// object per cell, addressed by a full label/index map) used for any dimension mix, the compact "values"
+// form (a flat, row-major array) used for purely indexed tensors, the "cells" short form (a label->value
+// map) used for single-dimension mapped tensors, and the "blocks" form (one dense array per mapped-dimension
+// address) used for mixed tensors.
+func ParseTensorValue(m map[string]interface{}) (TensorValue, error) {
+	typeStr, _ := m["type"].(string)
+	dims, err := ParseTensorType(typeStr)
+	if err != nil {
+		return TensorValue{}, err
+	}
+	var cells []TensorCell
+	switch {
+	case m["cells"] != nil:
+		cells, err = parseCells(m["cells"], dims)
+	case m["blocks"] != nil:
+		cells, err = parseBlocks(m["blocks"], dims)
+	case m["values"] != nil:
+		cells, err = parseValues(m["values"], dims)
+	default:
+		return TensorValue{}, fmt.Errorf("tensor value has none of cells, blocks or values: %v", m)
+	}
+	if err != nil {
+		return TensorValue{}, fmt.Errorf("invalid tensor value for type %q: %w", typeStr, err)
+	}
+	return TensorValue{Type: typeStr, Dimensions: dims, Cells: cells}, nil
+}
+
+// parseCells parses the "cells" literal, either the general array-of-{address,value} form or the
+// single-dimension map-shorthand form.
+func parseCells(raw interface{}, dims []TensorDimension) ([]TensorCell, error) {
+	switch v := raw.(type) {
+	case []interface{}:
+		cells := make([]TensorCell, 0, len(v))
+		for _, e := range v {
+			entry, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cell entry is not an object: %v", e)
+			}
+			addr, ok := entry["address"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cell entry has no address: %v", e)
+			}
+			value, ok := entry["value"].(float64)
+			if !ok {
+				return nil, fmt.Errorf("cell entry has no numeric value: %v", e)
+			}
+			cells = append(cells, TensorCell{Address: stringAddress(addr), Value: value})
+		}
+		return cells, nil
+	case map[string]interface{}:
+		if len(dims) != 1 || !dims[0].Mapped {
+			return nil, fmt.Errorf("map-shorthand cells form requires exactly one mapped dimension, got %v", dims)
+		}
+		cells := make([]TensorCell, 0, len(v))
+		for label, val := range v {
+			value, ok := val.(float64)
+			if !ok {
+				return nil, fmt.Errorf("cell %q has no numeric value: %v", label, val)
+			}
+			cells = append(cells, TensorCell{Address: map[string]string{dims[0].Name: label}, Value: value})
+		}
+		return cells, nil
+	default:
+		return nil, fmt.Errorf("unsupported cells literal: %v", raw)
+	}
+}
+
+// parseValues parses the "values" literal, a flat array in row-major order (the last dimension varies
+// fastest), valid only when every dimension is indexed.
+func parseValues(raw interface{}, dims []TensorDimension) ([]TensorCell, error) {
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("values literal is not an array: %v", raw)
+	}
+	for _, d := range dims {
+		if d.Mapped {
+			return nil, fmt.Errorf("values literal requires every dimension to be indexed, got mapped dimension %q", d.Name)
+		}
+	}
+	cells := make([]TensorCell, 0, len(values))
+	for i, val := range values {
+		value, ok := val.(float64)
+		if !ok {
+			return nil, fmt.Errorf("value at index %d is not numeric: %v", i, val)
+		}
+		cells = append(cells, TensorCell{Address: indexedAddress(dims, i), Value: value})
+	}
+	return cells, nil
+}
+
+// parseBlocks parses the "blocks" literal used for mixed tensors: one entry per address of the mapped
+// dimensions, each holding a dense, row-major array over the remaining indexed dimensions.
+func parseBlocks(raw interface{}, dims []TensorDimension) ([]TensorCell, error) {
+	blocks, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("blocks literal is not an array: %v", raw)
+	}
+	var indexedDims []TensorDimension
+	for _, d := range dims {
+		if !d.Mapped {
+			indexedDims = append(indexedDims, d)
+		}
+	}
+	var cells []TensorCell
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("block entry is not an object: %v", b)
+		}
+		addr, ok := block["address"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("block entry has no address: %v", b)
+		}
+		blockAddr := stringAddress(addr)
+		values, ok := block["values"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("block entry has no values array: %v", b)
+		}
+		for i, val := range values {
+			value, ok := val.(float64)
+			if !ok {
+				return nil, fmt.Errorf("block value at index %d is not numeric: %v", i, val)
+			}
+			address := indexedAddress(indexedDims, i)
+			for k, v := range blockAddr {
+				address[k] = v
+			}
+			cells = append(cells, TensorCell{Address: address, Value: value})
+		}
+	}
+	return cells, nil
+}
+
+// indexedAddress returns the address of the i'th cell in a row-major, fully-indexed (every dimension
+// bounded) iteration over dims, with the last dimension varying fastest.
+func indexedAddress(dims []TensorDimension, i int) map[string]string {
+	address := make(map[string]string, len(dims))
+	for d := len(dims) - 1; d >= 0; d-- {
+		size := dims[d].Size
+		if size == 0 {
+			size = 1
+		}
+		address[dims[d].Name] = strconv.Itoa(i % size)
+		i /= size
+	}
+	return address
+}
+
+func stringAddress(raw map[string]interface{}) map[string]string {
+	address := make(map[string]string, len(raw))
+	for k, v := range raw {
+		address[k] = fmt.Sprint(v)
+	}
+	return address
+}
+
+// NumCells returns the number of cells the tensor holds.
+func (t TensorValue) NumCells() int { return len(t.Cells) }
+
+// Shape returns one entry per dimension (in declaration order): the dimension's bound for an indexed
+// dimension, or -1 for a mapped dimension, whose size isn't known up front.
+func (t TensorValue) Shape() []int {
+	shape := make([]int, len(t.Dimensions))
+	for i, d := range t.Dimensions {
+		if d.Mapped {
+			shape[i] = -1
+		} else {
+			shape[i] = d.Size
+		}
+	}
+	return shape
+}
+
+// Min, Max and Mean return the minimum, maximum and mean of the tensor's cell values. All three return 0
+// for an empty tensor.
+func (t TensorValue) Min() float64 { return t.reduce(func(a, b float64) bool { return b < a }) }
+func (t TensorValue) Max() float64 { return t.reduce(func(a, b float64) bool { return b > a }) }
+
+func (t TensorValue) reduce(replace func(best, candidate float64) bool) float64 {
+	if len(t.Cells) == 0 {
+		return 0
+	}
+	best := t.Cells[0].Value
+	for _, c := range t.Cells[1:] {
+		if replace(best, c.Value) {
+			best = c.Value
+		}
+	}
+	return best
+}
+
+func (t TensorValue) Mean() float64 {
+	if len(t.Cells) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, c := range t.Cells {
+		sum += c.Value
+	}
+	return sum / float64(len(t.Cells))
+}
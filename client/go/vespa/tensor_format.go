@@ -0,0 +1,208 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TensorFormat selects how ParseTensorValue is rendered by FormatTensor and RenderTensors.
+type TensorFormat string
+
+const (
+	// TensorFormatShort renders small tensors as an aligned table, one row per cell, falling back to the
+	// same rendering as TensorFormatSummary once the cell count passes tensorShortFormatThreshold.
+	TensorFormatShort TensorFormat = "short"
+	// TensorFormatFull renders every cell, however many there are, as one "address: value" line.
+	TensorFormatFull TensorFormat = "full"
+	// TensorFormatSummary renders the tensor's shape and value statistics plus its first few cells,
+	// regardless of size.
+	TensorFormatSummary TensorFormat = "summary"
+)
+
+// tensorShortFormatThreshold is the cell count above which TensorFormatShort falls back to a summary
+// instead of printing a table, so a short-format render of a large embedding doesn't flood the terminal.
+const tensorShortFormatThreshold = 32
+
+// tensorSummaryCellCount is how many cells TensorFormatSummary (and a TensorFormatShort that falls back to
+// it) lists individually, after the shape and statistics line.
+const tensorSummaryCellCount = 5
+
+// ParseTensorFormat validates s as one of the supported --tensor-format values.
+func ParseTensorFormat(s string) (TensorFormat, error) {
+	switch TensorFormat(s) {
+	case TensorFormatShort, TensorFormatFull, TensorFormatSummary:
+		return TensorFormat(s), nil
+	default:
+		return "", fmt.Errorf("invalid tensor format %q: must be short, full or summary", s)
+	}
+}
+
+// FormatTensor renders t according to format.
+func FormatTensor(t TensorValue, format TensorFormat) string {
+	switch format {
+	case TensorFormatFull:
+		return formatTensorTable(t, t.Cells)
+	case TensorFormatShort:
+		if t.NumCells() <= tensorShortFormatThreshold {
+			return formatTensorTable(t, t.Cells)
+		}
+		return formatTensorSummary(t)
+	default:
+		return formatTensorSummary(t)
+	}
+}
+
+// formatTensorTable renders cells as an aligned table, one column per dimension plus a value column, one
+// row per cell, in a stable address order.
+func formatTensorTable(t TensorValue, cells []TensorCell) string {
+	if len(cells) == 0 {
+		return fmt.Sprintf("%s: (empty)", t.Type)
+	}
+	sorted := sortedCells(t.Dimensions, cells)
+	headers := make([]string, 0, len(t.Dimensions)+1)
+	for _, d := range t.Dimensions {
+		headers = append(headers, d.Name)
+	}
+	headers = append(headers, "value")
+	rows := make([][]string, 0, len(sorted)+1)
+	rows = append(rows, headers)
+	for _, c := range sorted {
+		row := make([]string, 0, len(headers))
+		for _, d := range t.Dimensions {
+			row = append(row, c.Address[d.Name])
+		}
+		row = append(row, formatTensorCellValue(c.Value))
+		rows = append(rows, row)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s:\n", t.Type)
+	sb.WriteString(alignColumns(rows))
+	return sb.String()
+}
+
+// formatTensorSummary renders the tensor's shape and value statistics, followed by its first few cells.
+func formatTensorSummary(t TensorValue) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: shape %s, %d value(s), min=%s, max=%s, mean=%s\n",
+		t.Type, formatShape(t), t.NumCells(), formatTensorCellValue(t.Min()), formatTensorCellValue(t.Max()), formatTensorCellValue(t.Mean()))
+	if t.NumCells() == 0 {
+		return strings.TrimRight(sb.String(), "\n")
+	}
+	sorted := sortedCells(t.Dimensions, t.Cells)
+	n := tensorSummaryCellCount
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	sb.WriteString(formatTensorTable(t, sorted[:n]))
+	if n < len(sorted) {
+		fmt.Fprintf(&sb, "\n... %d more cell(s)", len(sorted)-n)
+	}
+	return sb.String()
+}
+
+func formatShape(t TensorValue) string {
+	parts := make([]string, len(t.Dimensions))
+	for i, size := range t.Shape() {
+		if size < 0 {
+			parts[i] = "?"
+		} else {
+			parts[i] = strconv.Itoa(size)
+		}
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func formatTensorCellValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// sortedCells returns a copy of cells ordered by address, comparing dims in declaration order and, within
+// a dimension, numerically if both labels parse as integers, lexicographically otherwise. This keeps
+// indexed-dimension addresses ("0", "1", ..., "10") in the order the dimension actually iterates.
+func sortedCells(dims []TensorDimension, cells []TensorCell) []TensorCell {
+	sorted := make([]TensorCell, len(cells))
+	copy(sorted, cells)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, d := range dims {
+			a, b := sorted[i].Address[d.Name], sorted[j].Address[d.Name]
+			if a == b {
+				continue
+			}
+			ai, aerr := strconv.Atoi(a)
+			bi, berr := strconv.Atoi(b)
+			if aerr == nil && berr == nil {
+				return ai < bi
+			}
+			return a < b
+		}
+		return false
+	})
+	return sorted
+}
+
+// alignColumns renders rows, whose first row is a header, as a left-aligned, space-padded table.
+func alignColumns(rows [][]string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	var sb strings.Builder
+	for r, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(cell)
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-len(cell)))
+			}
+		}
+		if r < len(rows)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// RenderTensors re-encodes body, a JSON response, replacing every field value shaped like a tensor
+// (LooksLikeTensor) with its FormatTensor rendering under format, leaving everything else unchanged. This
+// is how --tensor-format turns an unreadable tensor literal buried in a query or document response into
+// something legible, without needing to know where in the response tensor fields will appear.
+func RenderTensors(body []byte, format TensorFormat) ([]byte, error) {
+	var tree interface{}
+	if err := json.Unmarshal(body, &tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renderTensorsIn(tree, format))
+}
+
+func renderTensorsIn(v interface{}, format TensorFormat) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if LooksLikeTensor(value) {
+			if t, err := ParseTensorValue(value); err == nil {
+				return FormatTensor(t, format)
+			}
+		}
+		rendered := make(map[string]interface{}, len(value))
+		for k, e := range value {
+			rendered[k] = renderTensorsIn(e, format)
+		}
+		return rendered
+	case []interface{}:
+		rendered := make([]interface{}, len(value))
+		for i, e := range value {
+			rendered[i] = renderTensorsIn(e, format)
+		}
+		return rendered
+	default:
+		return v
+	}
+}
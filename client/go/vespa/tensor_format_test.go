@@ -0,0 +1,119 @@
+package vespa
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTensorFormatRejectsUnknownValue(t *testing.T) {
+	_, err := ParseTensorFormat("pretty")
+	assert.NotNil(t, err)
+}
+
+func TestFormatTensorShortRendersTableForSmallTensor(t *testing.T) {
+	tensor := TensorValue{
+		Type:       "tensor(x[2])",
+		Dimensions: []TensorDimension{{Name: "x", Size: 2}},
+		Cells: []TensorCell{
+			{Address: map[string]string{"x": "1"}, Value: 2},
+			{Address: map[string]string{"x": "0"}, Value: 1},
+		},
+	}
+
+	out := FormatTensor(tensor, TensorFormatShort)
+
+	lines := strings.Split(out, "\n")
+	assert.Equal(t, "tensor(x[2]):", lines[0])
+	assert.Contains(t, lines[1], "x")
+	assert.Contains(t, lines[1], "value")
+	// cells are sorted numerically by the indexed dimension, so x=0 comes before x=1
+	assert.True(t, strings.Index(out, "0") < strings.Index(out, "1"))
+}
+
+func TestFormatTensorShortFallsBackToSummaryForLargeTensor(t *testing.T) {
+	cells := make([]TensorCell, tensorShortFormatThreshold+1)
+	for i := range cells {
+		cells[i] = TensorCell{Address: map[string]string{"x": strconv.Itoa(i)}, Value: float64(i)}
+	}
+	tensor := TensorValue{
+		Type:       "tensor(x[100])",
+		Dimensions: []TensorDimension{{Name: "x", Size: 100}},
+		Cells:      cells,
+	}
+
+	out := FormatTensor(tensor, TensorFormatShort)
+
+	assert.Contains(t, out, "shape [100]")
+	assert.Contains(t, out, "more cell(s)")
+}
+
+func TestFormatTensorFullListsEveryCellRegardlessOfSize(t *testing.T) {
+	cells := make([]TensorCell, tensorShortFormatThreshold+1)
+	for i := range cells {
+		cells[i] = TensorCell{Address: map[string]string{"x": strconv.Itoa(i)}, Value: float64(i)}
+	}
+	tensor := TensorValue{
+		Type:       "tensor(x[100])",
+		Dimensions: []TensorDimension{{Name: "x", Size: 100}},
+		Cells:      cells,
+	}
+
+	out := FormatTensor(tensor, TensorFormatFull)
+
+	assert.NotContains(t, out, "more cell(s)")
+	assert.Equal(t, len(cells)+2, len(strings.Split(out, "\n"))) // type line + header + one row per cell
+}
+
+func TestFormatTensorSummaryReportsStatistics(t *testing.T) {
+	tensor := TensorValue{
+		Type:       "tensor(x[3])",
+		Dimensions: []TensorDimension{{Name: "x", Size: 3}},
+		Cells: []TensorCell{
+			{Address: map[string]string{"x": "0"}, Value: 1},
+			{Address: map[string]string{"x": "1"}, Value: 2},
+			{Address: map[string]string{"x": "2"}, Value: 3},
+		},
+	}
+
+	out := FormatTensor(tensor, TensorFormatSummary)
+
+	assert.Contains(t, out, "min=1")
+	assert.Contains(t, out, "max=3")
+	assert.Contains(t, out, "mean=2")
+	assert.Contains(t, out, "3 value(s)")
+}
+
+func TestFormatTensorSummaryMarksMappedDimensionShapeUnknown(t *testing.T) {
+	tensor := TensorValue{
+		Type:       "tensor(x{})",
+		Dimensions: []TensorDimension{{Name: "x", Mapped: true}},
+		Cells:      []TensorCell{{Address: map[string]string{"x": "a"}, Value: 1}},
+	}
+
+	out := FormatTensor(tensor, TensorFormatSummary)
+
+	assert.Contains(t, out, "shape [?]")
+}
+
+func TestRenderTensorsReplacesTensorFieldsInPlace(t *testing.T) {
+	body := []byte(`{"fields":{"title":"hello","embedding":{"type":"tensor(x[2])","values":[1,2]}}}`)
+
+	out, err := RenderTensors(body, TensorFormatFull)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "hello")
+	assert.Contains(t, string(out), "tensor(x[2]):")
+	assert.NotContains(t, string(out), `"values"`)
+}
+
+func TestRenderTensorsLeavesNonTensorFieldsUntouched(t *testing.T) {
+	body := []byte(`{"fields":{"title":"hello","count":3}}`)
+
+	out, err := RenderTensors(body, TensorFormatFull)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(body), string(out))
+}
@@ -0,0 +1,125 @@
+package vespa
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTensorTypeIndexed(t *testing.T) {
+	dims, err := ParseTensorType("tensor(x[2],y[3])")
+	assert.Nil(t, err)
+	if assert.Len(t, dims, 2) {
+		assert.Equal(t, TensorDimension{Name: "x", Size: 2}, dims[0])
+		assert.Equal(t, TensorDimension{Name: "y", Size: 3}, dims[1])
+	}
+}
+
+func TestParseTensorTypeMappedAndValueType(t *testing.T) {
+	dims, err := ParseTensorType("tensor<float>(x{},y[2])")
+	assert.Nil(t, err)
+	if assert.Len(t, dims, 2) {
+		assert.Equal(t, TensorDimension{Name: "x", Mapped: true}, dims[0])
+		assert.Equal(t, TensorDimension{Name: "y", Size: 2}, dims[1])
+	}
+}
+
+func TestParseTensorTypeRejectsNonTensor(t *testing.T) {
+	_, err := ParseTensorType("string")
+	assert.NotNil(t, err)
+}
+
+func decodeTensorJSON(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(raw), &m))
+	return m
+}
+
+func TestParseTensorValueIndexedValues(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x[2],y[2])","values":[1,2,3,4]}`)
+	assert.True(t, LooksLikeTensor(m))
+
+	tensor, err := ParseTensorValue(m)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, tensor.NumCells())
+	assert.Equal(t, []int{2, 2}, tensor.Shape())
+	assert.Equal(t, 1.0, tensor.Min())
+	assert.Equal(t, 4.0, tensor.Max())
+	assert.Equal(t, 2.5, tensor.Mean())
+	var found bool
+	for _, c := range tensor.Cells {
+		if c.Address["x"] == "0" && c.Address["y"] == "1" {
+			assert.Equal(t, 2.0, c.Value)
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestParseTensorValueCellsArray(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x{},y[2])","cells":[
+		{"address":{"x":"a","y":"0"},"value":1.5},
+		{"address":{"x":"a","y":"1"},"value":2.5}
+	]}`)
+
+	tensor, err := ParseTensorValue(m)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tensor.NumCells())
+	assert.Equal(t, []int{-1, 2}, tensor.Shape())
+}
+
+func TestParseTensorValueCellsMapShorthand(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x{})","cells":{"a":1.0,"b":2.0}}`)
+
+	tensor, err := ParseTensorValue(m)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tensor.NumCells())
+	assert.Equal(t, 1.5, tensor.Mean())
+}
+
+func TestParseTensorValueCellsMapShorthandRejectsMultiDimension(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x{},y{})","cells":{"a":1.0}}`)
+
+	_, err := ParseTensorValue(m)
+
+	assert.NotNil(t, err)
+}
+
+func TestParseTensorValueBlocksMixed(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x{},y[2])","blocks":[
+		{"address":{"x":"a"},"values":[1,2]},
+		{"address":{"x":"b"},"values":[3,4]}
+	]}`)
+
+	tensor, err := ParseTensorValue(m)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, tensor.NumCells())
+	for _, c := range tensor.Cells {
+		if c.Address["x"] == "b" && c.Address["y"] == "1" {
+			assert.Equal(t, 4.0, c.Value)
+		}
+	}
+}
+
+func TestLooksLikeTensorRejectsOrdinaryObjects(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"string","value":"hello"}`)
+	assert.False(t, LooksLikeTensor(m))
+}
+
+func TestParseTensorValueEmptyTensorHasZeroStatistics(t *testing.T) {
+	m := decodeTensorJSON(t, `{"type":"tensor(x{})","cells":{}}`)
+
+	tensor, err := ParseTensorValue(m)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, tensor.NumCells())
+	assert.Equal(t, 0.0, tensor.Min())
+	assert.Equal(t, 0.0, tensor.Max())
+	assert.Equal(t, 0.0, tensor.Mean())
+}
@@ -0,0 +1,149 @@
+package vespa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VisitDocument is a single document as returned by the /document/v1 visit API.
+type VisitDocument struct {
+	Id     string          `json:"id"`
+	Fields json.RawMessage `json:"fields"`
+}
+
+// VisitResult is one page of a visit operation.
+type VisitResult struct {
+	Documents     []VisitDocument `json:"documents"`
+	Continuation  string          `json:"continuation"`
+	DocumentCount int64           `json:"documentCount"`
+}
+
+// VisitOptions configures a single page fetched through Visit.
+type VisitOptions struct {
+	// Namespace restricts the visit to a single document namespace. Empty means all namespaces.
+	Namespace string
+	// DocType restricts the visit to a single document type. Empty means all types.
+	DocType string
+	// Selection is a document selection expression, as accepted by the config server.
+	Selection string
+	// Continuation resumes a visit from a previous VisitResult.Continuation. Empty starts from the beginning.
+	Continuation string
+	// WantedDocumentCount is a hint for how many documents a single page should contain.
+	WantedDocumentCount int
+}
+
+// Visit fetches a single page of documents matching opts.
+func Visit(service *Service, opts VisitOptions, timeout time.Duration) (VisitResult, error) {
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "*"
+	}
+	docType := opts.DocType
+	if docType == "" {
+		docType = "*"
+	}
+	path := fmt.Sprintf("/document/v1/%s/%s/docid", namespace, docType)
+	q := url.Values{}
+	if opts.Selection != "" {
+		q.Set("selection", opts.Selection)
+	}
+	if opts.Continuation != "" {
+		q.Set("continuation", opts.Continuation)
+	}
+	if opts.WantedDocumentCount > 0 {
+		q.Set("wantedDocumentCount", fmt.Sprint(opts.WantedDocumentCount))
+	}
+	req, err := http.NewRequest(http.MethodGet, path+"?"+q.Encode(), nil)
+	if err != nil {
+		return VisitResult{}, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return VisitResult{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return VisitResult{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return VisitResult{}, fmt.Errorf("visit failed with status %d: %s", resp.StatusCode, body)
+	}
+	var result VisitResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return VisitResult{}, fmt.Errorf("invalid visit response: %w", err)
+	}
+	return result, nil
+}
+
+// GetDocument fetches a single document by ID through the /document/v1 get API, returning an error whose
+// Error() contains "404" if no document exists with that ID, so callers can distinguish a missing document
+// from any other failure the same way they would for a query.
+func GetDocument(service *Service, documentId string, timeout time.Duration) (VisitDocument, error) {
+	id, err := ParseDocumentId(documentId)
+	if err != nil {
+		return VisitDocument{}, err
+	}
+	req, err := http.NewRequest(http.MethodGet, id.DocumentPath(), nil)
+	if err != nil {
+		return VisitDocument{}, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return VisitDocument{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return VisitDocument{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return VisitDocument{}, fmt.Errorf("get failed with status %d: %s", resp.StatusCode, body)
+	}
+	var doc VisitDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return VisitDocument{}, fmt.Errorf("invalid get response: %w", err)
+	}
+	return doc, nil
+}
+
+// namespacesResponse is the subset of the /document/v1/ root response we care about: one path prefix per
+// document namespace present on the cluster.
+type namespacesResponse struct {
+	PathPrefix []string `json:"pathPrefix"`
+}
+
+// ListNamespaces returns every document namespace known to service, as reported by the root /document/v1/
+// endpoint.
+func ListNamespaces(service *Service, timeout time.Duration) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, "/document/v1/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := service.Do(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("listing namespaces failed with status %d: %s", resp.StatusCode, body)
+	}
+	var result namespacesResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid namespaces response: %w", err)
+	}
+	namespaces := make([]string, 0, len(result.PathPrefix))
+	for _, prefix := range result.PathPrefix {
+		namespaces = append(namespaces, strings.Trim(prefix, "/"))
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,69 @@
+package vespa
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vespa-engine/vespa/client/go/mock"
+)
+
+func TestListNamespaces(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 200,
+		Body:       closer{strings.NewReader(`{"pathPrefix": ["/mynamespace/", "/other/"]}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	namespaces, err := ListNamespaces(service, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"mynamespace", "other"}, namespaces)
+	assert.Equal(t, "/document/v1/", httpClient.LastRequest.URL.Path)
+}
+
+func TestGetDocument(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 200,
+		Body:       closer{strings.NewReader(`{"id": "id:ns:type::1", "fields": {"title": "x"}}`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	doc, err := GetDocument(service, "id:ns:type::1", time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "id:ns:type::1", doc.Id)
+	assert.Equal(t, "/document/v1/ns/type/docid/1", httpClient.LastRequest.URL.Path)
+}
+
+func TestGetDocumentFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 404,
+		Body:       closer{strings.NewReader(`not found`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	_, err := GetDocument(service, "id:ns:type::1", time.Second)
+
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "404")
+	}
+}
+
+func TestListNamespacesFailsOnErrorStatus(t *testing.T) {
+	httpClient := &mock.HTTPClient{NextResponse: &http.Response{
+		StatusCode: 500,
+		Body:       closer{strings.NewReader(`internal error`)},
+		Header:     make(http.Header),
+	}}
+	service := testService(httpClient)
+
+	_, err := ListNamespaces(service, time.Second)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,25 @@
+package vespa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Zone identifies a Vespa Cloud zone by environment and region, e.g. "prod.us-east-3".
+type Zone struct {
+	Environment string
+	Region      string
+}
+
+func (z Zone) String() string {
+	return z.Environment + "." + z.Region
+}
+
+// ZoneFromString parses s as an "environment.region" zone, e.g. "prod.us-east-3" or "dev.aws-us-east-1c".
+func ZoneFromString(s string) (Zone, error) {
+	parts := strings.SplitN(s, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Zone{}, fmt.Errorf("invalid zone %q: must be on the form env.region, e.g. \"prod.us-east-3\"", s)
+	}
+	return Zone{Environment: parts[0], Region: parts[1]}, nil
+}
@@ -0,0 +1,23 @@
+package vespa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneFromString(t *testing.T) {
+	zone, err := ZoneFromString("prod.us-east-3")
+	assert.Nil(t, err)
+	assert.Equal(t, Zone{Environment: "prod", Region: "us-east-3"}, zone)
+	assert.Equal(t, "prod.us-east-3", zone.String())
+}
+
+func TestZoneFromStringRejectsMalformed(t *testing.T) {
+	for _, s := range []string{"prod", "prod.", ".us-east-3", ""} {
+		_, err := ZoneFromString(s)
+		if assert.NotNil(t, err, "expected error for %q", s) {
+			assert.Contains(t, err.Error(), "env.region")
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package vespatest provides an in-process harness for testing code that drives the vespa CLI, without
+// spawning a real binary or touching the network.
+//
+// This package is semi-stable: its shape may still change as cmd.CLI's internals do, but it's built on
+// cmd.NewForTesting, the same constructor the CLI's own test suite uses, so it won't silently drift out of
+// sync with what testing the real CLI actually requires.
+package vespatest
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/vespa-engine/vespa/client/go/cmd"
+	"github.com/vespa-engine/vespa/client/go/mock"
+	"github.com/vespa-engine/vespa/client/go/util"
+)
+
+// CLI is a vespa CLI wired up for offline testing: Stdin is empty, Stdout and Stderr are in-memory buffers,
+// and every HTTP request is served by HTTPClient instead of a real network. It embeds *cmd.CLI, so it's
+// ready to Run directly.
+type CLI struct {
+	*cmd.CLI
+	// Stdout and Stderr capture everything the CLI printed, for assertions after Run.
+	Stdout *bytes.Buffer
+	Stderr *bytes.Buffer
+	// HTTPClient serves every request the CLI issues. Set NextResponse, NextError or DoFunc on it to
+	// script a response before calling Run.
+	HTTPClient *mock.HTTPClient
+}
+
+// NewCLI creates a CLI ready to Run. env overrides the environment variables the CLI sees, e.g.
+// VESPA_CLI_HOME to isolate config files to a temp directory; a nil map falls back to the OS environment.
+func NewCLI(env map[string]string) (*CLI, error) {
+	var stdout, stderr bytes.Buffer
+	httpClient := &mock.HTTPClient{}
+	inner, err := cmd.NewForTesting(strings.NewReader(""), &stdout, &stderr, env, func(_ time.Duration) util.HTTPClient {
+		return httpClient
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CLI{CLI: inner, Stdout: &stdout, Stderr: &stderr, HTTPClient: httpClient}, nil
+}
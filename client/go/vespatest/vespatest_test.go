@@ -0,0 +1,56 @@
+package vespatest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewCLIDrivesDeployOffline demonstrates validating and deploying an application package fully
+// offline: no real network call is needed, since vespa deploy only talks to the config server for --diff
+// and --dry-run.
+func TestNewCLIDrivesDeployOffline(t *testing.T) {
+	cli, err := NewCLI(nil)
+	assert.Nil(t, err)
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "services.xml"), []byte("<services/>"), 0644))
+
+	err = cli.Run([]string{"deploy", dir})
+
+	assert.Nil(t, err)
+	assert.Contains(t, cli.Stdout.String(), "Deploying")
+}
+
+// TestNewCLIDrivesQueryOffline demonstrates scripting a query response on HTTPClient and driving vespa
+// query against it, asserting both the printed response and the request the CLI actually sent.
+func TestNewCLIDrivesQueryOffline(t *testing.T) {
+	cli, err := NewCLI(nil)
+	assert.Nil(t, err)
+	cli.HTTPClient.NextResponse = &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(`{"root": {"fields": {"totalCount": 1}}}`)),
+		Header:     make(http.Header),
+	}
+
+	err = cli.Run([]string{"query", "yql=select * from music where true", "hits=5"})
+
+	assert.Nil(t, err)
+	assert.Contains(t, cli.Stdout.String(), "totalCount")
+	assert.Equal(t, "select * from music where true", cli.HTTPClient.LastRequest.URL.Query().Get("yql"))
+}
+
+func TestNewCLIInjectsEnv(t *testing.T) {
+	dir := t.TempDir()
+	cli, err := NewCLI(map[string]string{"VESPA_CLI_HOME": dir})
+	assert.Nil(t, err)
+
+	err = cli.Run([]string{"config", "set", "application", "mytenant.myapp"})
+
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(dir, "profiles", "default", "config.yaml"))
+}